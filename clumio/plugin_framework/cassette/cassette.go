@@ -0,0 +1,114 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the cassettes holding the interactions with the Clumio API recorded by the
+// acceptance tests, so that the tests can replay them without reaching the API.
+
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Cassette holds the interactions with the Clumio API of an acceptance test, in the order
+// they were recorded. Its values are scrubbed of the tokens, external IDs and account IDs.
+type Cassette struct {
+	// Env holds the environment variables the interactions were recorded with which are not
+	// scrubbed, such as AWS_REGION. They must have the same values for the replay.
+	Env map[string]string `json:"env,omitempty"`
+	// Interactions are the requests sent to the API and their responses.
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a request sent to the Clumio API and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a request sent to the Clumio API.
+type Request struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// Query is the query string of the request, with its parameters sorted by name.
+	Query string `json:"query,omitempty"`
+	// Header holds the headers the request is matched on, such as Accept which selects the
+	// version of the API.
+	Header map[string]string `json:"header,omitempty"`
+	// Body is the body of the request if it is JSON.
+	Body json.RawMessage `json:"body,omitempty"`
+	// Text is the body of the request if it is not JSON.
+	Text string `json:"text,omitempty"`
+}
+
+// Response is the response of the Clumio API to a request.
+type Response struct {
+	StatusCode int `json:"status_code"`
+	// Header holds the headers of the response relevant to the provider, such as
+	// Retry-After.
+	Header map[string]string `json:"header,omitempty"`
+	// Body is the body of the response if it is JSON.
+	Body json.RawMessage `json:"body,omitempty"`
+	// Text is the body of the response if it is not JSON.
+	Text string `json:"text,omitempty"`
+}
+
+// Path returns the path of the cassette of the test in the given directory. The subtests
+// get a cassette of their own.
+func Path(dir, testName string) string {
+	return filepath.Join(dir, strings.ReplaceAll(testName, "/", "_")+".json")
+}
+
+// Load reads the cassette at the given path. The returned error wraps os.ErrNotExist if
+// there is no cassette at the path.
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cassette := &Cassette{}
+	if err := json.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %w", path, err)
+	}
+	// The bodies are indented in the file, and compacted back to the form they are matched
+	// in.
+	for _, interaction := range cassette.Interactions {
+		for _, body := range []*json.RawMessage{
+			&interaction.Request.Body, &interaction.Response.Body} {
+			if *body == nil {
+				continue
+			}
+			var buf bytes.Buffer
+			if err := json.Compact(&buf, *body); err != nil {
+				return nil, fmt.Errorf("invalid cassette %s: %w", path, err)
+			}
+			*body = buf.Bytes()
+		}
+	}
+	return cassette, nil
+}
+
+// Save writes the cassette at the given path, creating its directory if needed.
+func (c *Cassette) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(c); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// IsNotExist reports whether the error returned by Load is due to a missing cassette.
+func IsNotExist(err error) bool {
+	return errors.Is(err, os.ErrNotExist)
+}
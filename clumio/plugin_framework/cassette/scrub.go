@@ -0,0 +1,141 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the scrubbing of the sensitive values out of the recorded interactions.
+
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// Redacted replaces the values of the sensitive keys of the bodies.
+	Redacted = "REDACTED"
+
+	// minSecretLength is the length under which the values of the sensitive keys are not
+	// replaced in the rest of the interactions, as they would match unrelated values.
+	minSecretLength = 6
+)
+
+// sensitiveKeys are the JSON keys whose values are scrubbed out of the bodies, at any depth.
+// They hold the tokens of the wallets and the AWS connections, the external IDs of the AWS
+// roles and the AWS account of Clumio.
+var sensitiveKeys = map[string]bool{
+	"token":                 true,
+	"clumio_token":          true,
+	"external_id":           true,
+	"role_external_id":      true,
+	"clumio_aws_account_id": true,
+}
+
+// Scrubber removes the sensitive values out of the interactions. The values of the sensitive
+// keys of the bodies are replaced by Redacted, and so is every other occurrence of them in the
+// interactions that follow. The values given to NewScrubber, such as the AWS account ID of the
+// tests, are replaced by their placeholder. A Scrubber is safe for concurrent use.
+type Scrubber struct {
+	mu           sync.Mutex
+	replacements map[string]string
+	replacer     *strings.Replacer
+}
+
+// NewScrubber returns a Scrubber replacing the keys of the map by their values on top of the
+// sensitive values found in the bodies. The empty keys are ignored.
+func NewScrubber(replacements map[string]string) *Scrubber {
+	s := &Scrubber{replacements: map[string]string{}}
+	for value, placeholder := range replacements {
+		if value != "" && value != placeholder {
+			s.replacements[value] = placeholder
+		}
+	}
+	s.buildReplacer()
+	return s
+}
+
+// buildReplacer rebuilds the replacer from the replacements, the longest values first so that
+// an ARN is replaced as a whole rather than by its account ID.
+func (s *Scrubber) buildReplacer() {
+	values := make([]string, 0, len(s.replacements))
+	for value := range s.replacements {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+		return values[i] < values[j]
+	})
+	pairs := make([]string, 0, 2*len(values))
+	for _, value := range values {
+		pairs = append(pairs, value, s.replacements[value])
+	}
+	s.replacer = strings.NewReplacer(pairs...)
+}
+
+// String returns the string with the sensitive values replaced.
+func (s *Scrubber) String(value string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.replacer.Replace(value)
+}
+
+// Body returns the scrubbed body, in a canonical JSON form if it is JSON and as text
+// otherwise. The canonical form has the keys of the objects sorted, so that the bodies sent
+// by the replayed tests can be compared with the recorded ones.
+func (s *Scrubber) Body(body []byte) (json.RawMessage, string) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, ""
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return nil, s.String(string(body))
+	}
+
+	s.mu.Lock()
+	found := false
+	redactSensitiveKeys(value, func(secret string) {
+		if len(secret) >= minSecretLength && s.replacements[secret] == "" {
+			s.replacements[secret] = Redacted
+			found = true
+		}
+	})
+	if found {
+		s.buildReplacer()
+	}
+	s.mu.Unlock()
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, s.String(string(body))
+	}
+	return json.RawMessage(s.String(strings.TrimSpace(buf.String()))), ""
+}
+
+// redactSensitiveKeys replaces the string values of the sensitive keys by Redacted, at any
+// depth, and passes the values replaced to found.
+func redactSensitiveKeys(value any, found func(string)) {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, item := range value {
+			if secret, ok := item.(string); ok && sensitiveKeys[key] {
+				if secret != "" && secret != Redacted {
+					found(secret)
+					value[key] = Redacted
+				}
+				continue
+			}
+			redactSensitiveKeys(item, found)
+		}
+	case []any:
+		for _, item := range value {
+			redactSensitiveKeys(item, found)
+		}
+	}
+}
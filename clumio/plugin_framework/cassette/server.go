@@ -0,0 +1,311 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the server recording the interactions of the acceptance tests with the Clumio API
+// into cassettes, and replaying them.
+
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Mode is the mode of a Server.
+type Mode string
+
+const (
+	// ModeRecord forwards the requests to the Clumio API and records them along with their
+	// responses.
+	ModeRecord Mode = "record"
+	// ModeReplay answers the requests with the responses recorded for them, without reaching
+	// the Clumio API.
+	ModeReplay Mode = "replay"
+)
+
+var (
+	// matchedRequestHeaders are the headers of the requests that are recorded and matched.
+	// Accept selects the version of the API and the other one the organizational unit
+	// context. The Authorization header in particular is never recorded.
+	matchedRequestHeaders = []string{
+		"Accept",
+		"X-Clumio-Organizationalunit-Context",
+		"If-Match",
+	}
+
+	// recordedResponseHeaders are the headers of the responses that are recorded.
+	recordedResponseHeaders = []string{
+		"Content-Type",
+		"Etag",
+		"Retry-After",
+	}
+)
+
+// Server stands in for the Clumio API base URL of the acceptance tests. In ModeRecord, it
+// forwards the requests to the Clumio API and records the interactions into the cassette in
+// use. In ModeReplay, it answers the requests from the cassette in use: a request is matched
+// by its method, path, query, headers and body against the recorded requests in order, and
+// each recorded interaction is replayed once, except for a GET request replayed again with
+// its last response once its interactions are used up. The requests that match no
+// interaction fail and are reported by Eject.
+type Server struct {
+	// URL is the base URL of the server, to use as the clumio_api_base_url of the provider.
+	URL string
+
+	mode       Mode
+	target     *url.URL
+	client     *http.Client
+	scrubber   *Scrubber
+	httpServer *httptest.Server
+
+	mu        sync.Mutex
+	cassette  *Cassette
+	used      []bool
+	unmatched []string
+}
+
+// NewServer starts a server in the given mode on a local port. The requests are forwarded to
+// the target base URL in ModeRecord, and the target is ignored in ModeReplay. The scrubber
+// applies to the recorded interactions and to the requests matched in ModeReplay. Close must
+// be called to stop the server.
+func NewServer(mode Mode, target string, scrubber *Scrubber) (*Server, error) {
+	s := &Server{
+		mode:     mode,
+		client:   &http.Client{},
+		scrubber: scrubber,
+	}
+	switch mode {
+	case ModeRecord:
+		targetUrl, err := url.Parse(target)
+		if err != nil || targetUrl.Scheme == "" || targetUrl.Host == "" {
+			return nil, fmt.Errorf("invalid Clumio API base URL %q to record", target)
+		}
+		s.target = targetUrl
+	case ModeReplay:
+	default:
+		return nil, fmt.Errorf("invalid cassette mode %q: expected %s or %s", mode,
+			ModeRecord, ModeReplay)
+	}
+	s.httpServer = httptest.NewServer(s)
+	s.URL = s.httpServer.URL
+	return s, nil
+}
+
+// Mode returns the mode of the server.
+func (s *Server) Mode() Mode {
+	return s.mode
+}
+
+// Close stops the server.
+func (s *Server) Close() {
+	s.httpServer.Close()
+}
+
+// Insert makes the server record into, or replay, the given cassette until Eject is called.
+func (s *Server) Insert(cassette *Cassette) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cassette = cassette
+	s.used = make([]bool, len(cassette.Interactions))
+	s.unmatched = nil
+}
+
+// Eject stops using the cassette inserted last and returns it, along with the requests that
+// matched no interaction of the cassette in ModeReplay.
+func (s *Server) Eject() (*Cassette, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cassette, unmatched := s.cassette, s.unmatched
+	s.cassette, s.used, s.unmatched = nil, nil, nil
+	return cassette, unmatched
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, httpReq *http.Request) {
+	body, err := io.ReadAll(httpReq.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Unable to read the request: %v", err))
+		return
+	}
+	if s.mode == ModeRecord {
+		s.record(w, httpReq, body)
+	} else {
+		s.replay(w, httpReq, body)
+	}
+}
+
+// scrubRequest returns the scrubbed representation of the request recorded and matched.
+func (s *Server) scrubRequest(httpReq *http.Request, body []byte) Request {
+	req := Request{
+		Method: httpReq.Method,
+		Path:   s.scrubber.String(httpReq.URL.Path),
+		Query:  s.scrubber.String(httpReq.URL.Query().Encode()),
+	}
+	for _, name := range matchedRequestHeaders {
+		if value := httpReq.Header.Get(name); value != "" {
+			if req.Header == nil {
+				req.Header = map[string]string{}
+			}
+			req.Header[name] = s.scrubber.String(value)
+		}
+	}
+	req.Body, req.Text = s.scrubber.Body(body)
+	return req
+}
+
+// record forwards the request to the Clumio API, records the interaction into the cassette
+// in use, if any, and writes the response of the API.
+func (s *Server) record(w http.ResponseWriter, httpReq *http.Request, body []byte) {
+	targetUrl := *s.target
+	targetUrl.Path = strings.TrimSuffix(s.target.Path, "/") + httpReq.URL.Path
+	targetUrl.RawQuery = httpReq.URL.RawQuery
+	forwarded, err := http.NewRequestWithContext(httpReq.Context(), httpReq.Method,
+		targetUrl.String(), bytes.NewReader(body))
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	forwarded.Header = httpReq.Header.Clone()
+	// The response is recorded decompressed, so the transport negotiates the encoding.
+	forwarded.Header.Del("Accept-Encoding")
+	resp, err := s.client.Do(forwarded)
+	if err != nil {
+		writeError(w, http.StatusBadGateway,
+			fmt.Sprintf("Error reaching the Clumio API at %s: %v", s.target.Host, err))
+		return
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		writeError(w, http.StatusBadGateway,
+			fmt.Sprintf("Error reading the response of the Clumio API: %v", err))
+		return
+	}
+
+	interaction := &Interaction{
+		Request:  s.scrubRequest(httpReq, body),
+		Response: Response{StatusCode: resp.StatusCode},
+	}
+	for _, name := range recordedResponseHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			if interaction.Response.Header == nil {
+				interaction.Response.Header = map[string]string{}
+			}
+			interaction.Response.Header[name] = s.scrubber.String(value)
+		}
+	}
+	interaction.Response.Body, interaction.Response.Text = s.scrubber.Body(respBody)
+	s.mu.Lock()
+	if s.cassette != nil {
+		s.cassette.Interactions = append(s.cassette.Interactions, interaction)
+	}
+	s.mu.Unlock()
+
+	for name, values := range resp.Header {
+		if name == "Content-Length" || name == "Content-Encoding" {
+			continue
+		}
+		w.Header()[name] = values
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = w.Write(respBody)
+}
+
+// replay writes the response recorded for the request in the cassette in use.
+func (s *Server) replay(w http.ResponseWriter, httpReq *http.Request, body []byte) {
+	req := s.scrubRequest(httpReq, body)
+	s.mu.Lock()
+	interaction := s.match(req)
+	if interaction == nil {
+		description := describe(req)
+		if s.cassette != nil {
+			s.unmatched = append(s.unmatched, description)
+		}
+		s.mu.Unlock()
+		writeError(w, http.StatusBadRequest,
+			"No interaction of the cassette matches the request "+description+".")
+		return
+	}
+	s.mu.Unlock()
+
+	for name, value := range interaction.Response.Header {
+		w.Header().Set(name, value)
+	}
+	w.WriteHeader(interaction.Response.StatusCode)
+	if interaction.Response.Body != nil {
+		_, _ = w.Write(interaction.Response.Body)
+	} else {
+		_, _ = io.WriteString(w, interaction.Response.Text)
+	}
+}
+
+// match returns the first interaction of the cassette in use which matches the request and
+// was not replayed yet. A GET request whose interactions were all replayed gets the last one
+// again. It returns nil if there is none.
+func (s *Server) match(req Request) *Interaction {
+	if s.cassette == nil {
+		return nil
+	}
+	var replayed *Interaction
+	for i, interaction := range s.cassette.Interactions {
+		if !sameRequest(interaction.Request, req) {
+			continue
+		}
+		if !s.used[i] {
+			s.used[i] = true
+			return interaction
+		}
+		replayed = interaction
+	}
+	if req.Method == http.MethodGet {
+		return replayed
+	}
+	return nil
+}
+
+// sameRequest reports whether the scrubbed requests are the same.
+func sameRequest(recorded, req Request) bool {
+	if recorded.Method != req.Method || recorded.Path != req.Path ||
+		recorded.Query != req.Query || recorded.Text != req.Text ||
+		!bytes.Equal(recorded.Body, req.Body) || len(recorded.Header) != len(req.Header) {
+		return false
+	}
+	for name, value := range recorded.Header {
+		if req.Header[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// describe returns a description of the request for the error messages.
+func describe(req Request) string {
+	description := req.Method + " " + req.Path
+	if req.Query != "" {
+		description += "?" + req.Query
+	}
+	if req.Body != nil {
+		description += " " + string(req.Body)
+	} else if req.Text != "" {
+		description += " " + req.Text
+	}
+	return description
+}
+
+// writeError writes an error in the format of the errors of the Clumio API.
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"errors": []map[string]any{{
+			"error_code":    status,
+			"error_message": message,
+		}},
+	})
+}
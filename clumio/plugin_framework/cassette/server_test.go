@@ -0,0 +1,147 @@
+// Copyright 2024. Clumio, Inc.
+
+// Unit tests of the recording and replay of the cassettes.
+
+package cassette
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// send sends a request to the server and returns the status code and body of the response.
+func send(t *testing.T, url, method, path, body string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer api-token")
+	req.Header.Set("Accept", "application/api.clumio.wallets=v1+json")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode, string(resBody)
+}
+
+func TestScrubber(t *testing.T) {
+	scrubber := NewScrubber(map[string]string{
+		"111122223333":                          "123456789012",
+		"arn:aws:iam::111122223333:role/Clumio": "arn:aws:iam::123456789012:role/Placeholder",
+	})
+	body, text := scrubber.Body([]byte(`{"account_native_id": "111122223333",
+		"wallet": {"token": "secret-wallet-token"},
+		"role_arn": "arn:aws:iam::111122223333:role/Clumio", "count": 12345678901234567}`))
+	if text != "" {
+		t.Fatalf("got text %q, want a JSON body", text)
+	}
+	want := `{"account_native_id":"123456789012","count":12345678901234567,` +
+		`"role_arn":"arn:aws:iam::123456789012:role/Placeholder","wallet":{"token":"REDACTED"}}`
+	if string(body) != want {
+		t.Errorf("got body %s, want %s", body, want)
+	}
+	if got := scrubber.String("/wallets?token=secret-wallet-token"); got !=
+		"/wallets?token=REDACTED" {
+		t.Errorf("got %q, the token found in the body was not scrubbed", got)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer api-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"id": "wallet-1", "token": "secret-wallet-token"}`)
+	}))
+	defer api.Close()
+	s, err := NewServer(ModeRecord, api.URL, NewScrubber(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.Insert(&Cassette{})
+	status, body := send(t, s.URL, http.MethodPost, "/wallets", `{"account_native_id": "1"}`)
+	if status != http.StatusOK || !strings.Contains(body, "secret-wallet-token") {
+		t.Fatalf("got %d %s, want the response of the API", status, body)
+	}
+	recorded, _ := s.Eject()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	if err := recorded.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(loaded)
+	if strings.Contains(string(data), "secret-wallet-token") ||
+		strings.Contains(string(data), "api-token") {
+		t.Errorf("the cassette holds a token: %s", data)
+	}
+	if len(loaded.Interactions) != 1 ||
+		string(loaded.Interactions[0].Response.Body) != `{"id":"wallet-1","token":"REDACTED"}` {
+		t.Errorf("got interactions %s, want the POST request", data)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	s, err := NewServer(ModeReplay, "", NewScrubber(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	header := map[string]string{"Accept": "application/api.clumio.wallets=v1+json"}
+	s.Insert(&Cassette{Interactions: []*Interaction{
+		{
+			Request: Request{Method: http.MethodPost, Path: "/wallets", Header: header,
+				Body: json.RawMessage(`{"account_native_id":"1"}`)},
+			Response: Response{StatusCode: http.StatusOK, Body: json.RawMessage(`{"id":"w"}`)},
+		},
+		{
+			Request:  Request{Method: http.MethodGet, Path: "/wallets/w", Header: header},
+			Response: Response{StatusCode: http.StatusOK, Body: json.RawMessage(`{"state":"a"}`)},
+		},
+		{
+			Request:  Request{Method: http.MethodGet, Path: "/wallets/w", Header: header},
+			Response: Response{StatusCode: http.StatusOK, Body: json.RawMessage(`{"state":"b"}`)},
+		},
+	}})
+
+	for _, step := range []struct {
+		method, path, body string
+		status             int
+		response           string
+	}{
+		{http.MethodPost, "/wallets", `{ "account_native_id": "1" }`, http.StatusOK, `{"id":"w"}`},
+		{http.MethodGet, "/wallets/w", "", http.StatusOK, `{"state":"a"}`},
+		{http.MethodGet, "/wallets/w", "", http.StatusOK, `{"state":"b"}`},
+		// The last response of a GET request is replayed again.
+		{http.MethodGet, "/wallets/w", "", http.StatusOK, `{"state":"b"}`},
+		{http.MethodPost, "/wallets", `{"account_native_id": "1"}`, http.StatusBadRequest, ""},
+		{http.MethodGet, "/wallets/x", "", http.StatusBadRequest, ""},
+	} {
+		status, body := send(t, s.URL, step.method, step.path, step.body)
+		if status != step.status || (step.response != "" && body != step.response) {
+			t.Errorf("%s %s: got %d %s, want %d %s", step.method, step.path, status, body,
+				step.status, step.response)
+		}
+	}
+	_, unmatched := s.Eject()
+	if len(unmatched) != 2 {
+		t.Errorf("got unmatched requests %q, want the last two requests", unmatched)
+	}
+}
@@ -18,6 +18,9 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
+// apiFieldPaths maps the fields of the API requests to the attributes of the resource.
+var apiFieldPaths = common.AttributePaths(schemaName, schemaCondition)
+
 // Ensure the implementation satisfies the expected interfaces.
 var (
 	_ resource.Resource              = &autoUserProvisioningRuleResource{}
@@ -114,7 +117,7 @@ func (r *autoUserProvisioningRuleResource) Create(
 		return
 	}
 
-	aupr := aupRules.NewAutoUserProvisioningRulesV1(r.client.ClumioConfig)
+	aupr := aupRules.NewAutoUserProvisioningRulesV1(r.client.WithContext(ctx).ClumioConfig())
 	name := plan.Name.ValueString()
 	condition := plan.Condition.ValueString()
 	roleId := plan.RoleID.ValueString()
@@ -132,10 +135,9 @@ func (r *autoUserProvisioningRuleResource) Create(
 	}
 	res, apiErr := aupr.CreateAutoUserProvisioningRule(auprRequest)
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
 			fmt.Sprintf("Error creating auto user provisioning rule %v.", name),
-			fmt.Sprintf(errorFmt, string(apiErr.Response)),
-		)
+			apiErr, apiFieldPaths)...)
 		return
 	}
 
@@ -160,13 +162,17 @@ func (r *autoUserProvisioningRuleResource) Read(
 		return
 	}
 
-	aupr := aupRules.NewAutoUserProvisioningRulesV1(r.client.ClumioConfig)
+	aupr := aupRules.NewAutoUserProvisioningRulesV1(r.client.WithContext(ctx).ClumioConfig())
 	res, apiErr := aupr.ReadAutoUserProvisioningRule(state.ID.ValueString())
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
+		if common.NewAPIError(apiErr).IsNotFound() {
+			common.RemoveMissingResource(ctx, resp, fmt.Sprintf(
+				"Clumio auto user provisioning rule %q", state.Name.ValueString()))
+			return
+		}
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
 			fmt.Sprintf("Error retrieving auto user provisioning rule %v.", state.Name.ValueString()),
-			fmt.Sprintf(errorFmt, string(apiErr.Response)),
-		)
+			apiErr, nil)...)
 		return
 	}
 
@@ -196,7 +202,7 @@ func (r *autoUserProvisioningRuleResource) Update(
 		return
 	}
 
-	aupr := aupRules.NewAutoUserProvisioningRulesV1(r.client.ClumioConfig)
+	aupr := aupRules.NewAutoUserProvisioningRulesV1(r.client.WithContext(ctx).ClumioConfig())
 	name := plan.Name.ValueString()
 	condition := plan.Condition.ValueString()
 	roleId := plan.RoleID.ValueString()
@@ -215,10 +221,9 @@ func (r *autoUserProvisioningRuleResource) Update(
 
 	res, apiErr := aupr.UpdateAutoUserProvisioningRule(plan.ID.ValueString(), auprRequest)
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
 			fmt.Sprintf("Error updating auto user provisioning rule %v.", name),
-			fmt.Sprintf(errorFmt, string(apiErr.Response)),
-		)
+			apiErr, apiFieldPaths)...)
 		return
 	}
 
@@ -248,12 +253,11 @@ func (r *autoUserProvisioningRuleResource) Delete(
 		return
 	}
 
-	aupr := aupRules.NewAutoUserProvisioningRulesV1(r.client.ClumioConfig)
+	aupr := aupRules.NewAutoUserProvisioningRulesV1(r.client.WithContext(ctx).ClumioConfig())
 	_, apiErr := aupr.DeleteAutoUserProvisioningRule(state.ID.ValueString())
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
 			fmt.Sprintf("Error deleting auto user provisioning rule %v.", state.ID.String()),
-			fmt.Sprintf(errorFmt, string(apiErr.Response)),
-		)
+			apiErr, nil)...)
 	}
 }
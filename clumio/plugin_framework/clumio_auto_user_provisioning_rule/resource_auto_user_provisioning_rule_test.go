@@ -4,11 +4,13 @@
 package clumio_auto_user_provisioning_rule_test
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"regexp"
 	"testing"
 
+	aupRules "github.com/clumio-code/clumio-go-sdk/controllers/auto_user_provisioning_rules"
 	clumio_pf "github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -47,10 +49,28 @@ func TestAccClumioAutoUserProvisioningRule(t *testing.T) {
 						regexp.MustCompile(ouAdminRole)),
 				),
 			},
+			{
+				Config: getTestAccResourceClumioAutoUserProvisioningRuleUpdate(
+					autoUserProvisioningRuleName, ouAdminRole),
+				Check: clumio_pf.UtilTestCheckResourceDisappears(
+					"clumio_auto_user_provisioning_rule.test_auto_user_provisioning_rule", testAccDeleteAutoUserProvisioningRule),
+				ExpectNonEmptyPlan: true,
+			},
 		},
 	})
 }
 
+// testAccDeleteAutoUserProvisioningRule deletes the auto user provisioning rule outside of
+// Terraform.
+func testAccDeleteAutoUserProvisioningRule(
+	_ context.Context, client *common.ApiClient, id string) error {
+	aupr := aupRules.NewAutoUserProvisioningRulesV1(client.ClumioConfig())
+	if _, apiErr := aupr.DeleteAutoUserProvisioningRule(id); apiErr != nil {
+		return common.NewAPIError(apiErr)
+	}
+	return nil
+}
+
 func getTestAccResourceClumioAutoUserProvisioningRule(autoUserProvisioningRuleName string,
 	roleId string) string {
 	baseUrl := os.Getenv(common.ClumioApiBaseUrl)
@@ -4,7 +4,6 @@ package clumio_auto_user_provisioning_setting
 
 import (
 	"context"
-	"fmt"
 
 	aupSettings "github.com/clumio-code/clumio-go-sdk/controllers/auto_user_provisioning_settings"
 	"github.com/clumio-code/clumio-go-sdk/models"
@@ -91,17 +90,15 @@ func (r *autoUserProvisioningSettingResource) Create(
 		return
 	}
 
-	aups := aupSettings.NewAutoUserProvisioningSettingsV1(r.client.ClumioConfig)
+	aups := aupSettings.NewAutoUserProvisioningSettingsV1(r.client.WithContext(ctx).ClumioConfig())
 	isEnabled := plan.IsEnabled.ValueBool()
 	aupsRequest := &models.UpdateAutoUserProvisioningSettingV1Request{
 		IsEnabled: &isEnabled,
 	}
 	_, apiErr := aups.UpdateAutoUserProvisioningSetting(aupsRequest)
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
-			"Error creating auto user provisioning setting.",
-			fmt.Sprintf(errorFmt, string(apiErr.Response)),
-		)
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			"Error creating auto user provisioning setting.", apiErr, nil)...)
 		return
 	}
 
@@ -126,13 +123,11 @@ func (r *autoUserProvisioningSettingResource) Read(
 		return
 	}
 
-	aups := aupSettings.NewAutoUserProvisioningSettingsV1(r.client.ClumioConfig)
+	aups := aupSettings.NewAutoUserProvisioningSettingsV1(r.client.WithContext(ctx).ClumioConfig())
 	res, apiErr := aups.ReadAutoUserProvisioningSetting()
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
-			"Error retrieving auto user provisioning setting.",
-			fmt.Sprintf(errorFmt, string(apiErr.Response)),
-		)
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			"Error retrieving auto user provisioning setting.", apiErr, nil)...)
 		return
 	}
 
@@ -157,7 +152,7 @@ func (r *autoUserProvisioningSettingResource) Update(
 		return
 	}
 
-	aups := aupSettings.NewAutoUserProvisioningSettingsV1(r.client.ClumioConfig)
+	aups := aupSettings.NewAutoUserProvisioningSettingsV1(r.client.WithContext(ctx).ClumioConfig())
 	isEnabled := plan.IsEnabled.ValueBool()
 	aupsRequest := &models.UpdateAutoUserProvisioningSettingV1Request{
 		IsEnabled: &isEnabled,
@@ -165,10 +160,8 @@ func (r *autoUserProvisioningSettingResource) Update(
 
 	res, apiErr := aups.UpdateAutoUserProvisioningSetting(aupsRequest)
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
-			"Error updating auto user provisioning setting.",
-			fmt.Sprintf(errorFmt, string(apiErr.Response)),
-		)
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			"Error updating auto user provisioning setting.", apiErr, nil)...)
 		return
 	}
 
@@ -193,16 +186,14 @@ func (r *autoUserProvisioningSettingResource) Delete(
 		return
 	}
 
-	aups := aupSettings.NewAutoUserProvisioningSettingsV1(r.client.ClumioConfig)
+	aups := aupSettings.NewAutoUserProvisioningSettingsV1(r.client.WithContext(ctx).ClumioConfig())
 	isEnabled := false
 	aupsRequest := &models.UpdateAutoUserProvisioningSettingV1Request{
 		IsEnabled: &isEnabled,
 	}
 	_, apiErr := aups.UpdateAutoUserProvisioningSetting(aupsRequest)
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
-			"Error deleting auto user provisioning setting.",
-			fmt.Sprintf(errorFmt, string(apiErr.Response)),
-		)
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			"Error deleting auto user provisioning setting.", apiErr, nil)...)
 	}
 }
@@ -4,6 +4,10 @@ package clumio_aws_connection
 
 const (
 	schemaId                   = "id"
+	schemaTimeouts             = "timeouts"
+	schemaWaitForCompletion    = "wait_for_completion"
+	schemaLastTaskId           = "last_task_id"
+	schemaFailOnTaskError      = "fail_on_task_error"
 	schemaAccountNativeId      = "account_native_id"
 	schemaAwsRegion            = "aws_region"
 	schemaDescription          = "description"
@@ -23,6 +27,5 @@ const (
 	defaultDataPlaneAccountId = "*"
 
 	http202           = 202
-	pollTimeoutInSec  = 3600
 	pollIntervalInSec = 5
 )
@@ -0,0 +1,13 @@
+// Copyright 2024. Clumio, Inc.
+
+package clumio_aws_connection_test
+
+import (
+	"testing"
+
+	clumio_pf "github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework"
+)
+
+func TestMain(m *testing.M) {
+	clumio_pf.UtilTestMain(m)
+}
@@ -7,14 +7,15 @@ package clumio_aws_connection
 import (
 	"context"
 	"fmt"
-	"strings"
 
+	apiutils "github.com/clumio-code/clumio-go-sdk/api_utils"
 	aws_connections "github.com/clumio-code/clumio-go-sdk/controllers/aws_connections"
 	awsEnvs "github.com/clumio-code/clumio-go-sdk/controllers/aws_environments"
 	orgUnits "github.com/clumio-code/clumio-go-sdk/controllers/organizational_units"
 	"github.com/clumio-code/clumio-go-sdk/models"
 
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -23,6 +24,9 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
+// apiFieldPaths maps the fields of the API requests to the attributes of the resource.
+var apiFieldPaths = common.AttributePaths(schemaAccountNativeId, schemaAwsRegion, schemaDescription)
+
 // Ensure the implementation satisfies the expected interfaces.
 var (
 	_ resource.Resource                = &clumioAWSConnectionResource{}
@@ -42,18 +46,22 @@ type clumioAWSConnectionResource struct {
 
 // clumioAWSConnectionResource model
 type clumioAWSConnectionResourceModel struct {
-	ID                   types.String `tfsdk:"id"`
-	AccountNativeID      types.String `tfsdk:"account_native_id"`
-	AWSRegion            types.String `tfsdk:"aws_region"`
-	Description          types.String `tfsdk:"description"`
-	OrganizationalUnitID types.String `tfsdk:"organizational_unit_id"`
-	ConnectionStatus     types.String `tfsdk:"connection_status"`
-	Token                types.String `tfsdk:"token"`
-	Namespace            types.String `tfsdk:"namespace"`
-	ClumioAWSAccountID   types.String `tfsdk:"clumio_aws_account_id"`
-	ClumioAWSRegion      types.String `tfsdk:"clumio_aws_region"`
-	ExternalID           types.String `tfsdk:"role_external_id"`
-	DataPlaneAccountID   types.String `tfsdk:"data_plane_account_id"`
+	ID                   types.String   `tfsdk:"id"`
+	AccountNativeID      types.String   `tfsdk:"account_native_id"`
+	AWSRegion            types.String   `tfsdk:"aws_region"`
+	Description          types.String   `tfsdk:"description"`
+	OrganizationalUnitID types.String   `tfsdk:"organizational_unit_id"`
+	ConnectionStatus     types.String   `tfsdk:"connection_status"`
+	Token                types.String   `tfsdk:"token"`
+	Namespace            types.String   `tfsdk:"namespace"`
+	ClumioAWSAccountID   types.String   `tfsdk:"clumio_aws_account_id"`
+	ClumioAWSRegion      types.String   `tfsdk:"clumio_aws_region"`
+	ExternalID           types.String   `tfsdk:"role_external_id"`
+	DataPlaneAccountID   types.String   `tfsdk:"data_plane_account_id"`
+	WaitForCompletion    types.Bool     `tfsdk:"wait_for_completion"`
+	LastTaskID           types.String   `tfsdk:"last_task_id"`
+	FailOnTaskError      types.Bool     `tfsdk:"fail_on_task_error"`
+	Timeouts             timeouts.Value `tfsdk:"timeouts"`
 }
 
 // Metadata returns the resource type name.
@@ -64,7 +72,7 @@ func (r *clumioAWSConnectionResource) Metadata(
 
 // Schema defines the schema for the resource.
 func (r *clumioAWSConnectionResource) Schema(
-	_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Clumio AWS Connection Resource used to connect" +
 			" AWS accounts to Clumio.",
@@ -132,6 +140,16 @@ func (r *clumioAWSConnectionResource) Schema(
 				Description: "The internal representation to uniquely identify a given data plane.",
 				Computed:    true,
 			},
+			schemaWaitForCompletion: common.WaitForCompletionAttribute(),
+			schemaLastTaskId:        common.LastTaskIdAttribute(),
+			schemaFailOnTaskError:   common.FailOnTaskErrorAttribute(),
+		},
+		Blocks: map[string]schema.Block{
+			schemaTimeouts: timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -157,11 +175,20 @@ func (r *clumioAWSConnectionResource) Create(
 		return
 	}
 
-	awsConnection := aws_connections.NewAwsConnectionsV1(client.ClumioConfig)
+	createTimeout, diags := plan.Timeouts.Create(ctx, r.client.Timeouts().Create)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	awsConnection := aws_connections.NewAwsConnectionsV1(client.WithContext(ctx).ClumioConfig())
 	accountNativeId := plan.AccountNativeID.ValueString()
 	awsRegion := plan.AWSRegion.ValueString()
 	description := plan.Description.ValueString()
 	organizationalUnitId := plan.OrganizationalUnitID.ValueString()
+	defer r.client.InvalidateLookups(common.LookupAwsEnvironments)
 	res, apiErr := awsConnection.CreateAwsConnection(&models.CreateAwsConnectionV1Request{
 		AccountNativeId:      &accountNativeId,
 		AwsRegion:            &awsRegion,
@@ -169,9 +196,8 @@ func (r *clumioAWSConnectionResource) Create(
 		OrganizationalUnitId: &organizationalUnitId,
 	})
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
-			"Error creating Clumio AWS Connection.",
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			"Error creating Clumio AWS Connection.", apiErr, apiFieldPaths)...)
 		return
 	}
 	plan.ID = types.StringValue(*res.Id)
@@ -185,6 +211,7 @@ func (r *clumioAWSConnectionResource) Create(
 	plan.ConnectionStatus = types.StringValue(*res.ConnectionStatus)
 	setExternalId(&plan, res.ExternalId, res.Token)
 	setDataPlaneAccountId(&plan, res.DataPlaneAccountId)
+	plan.LastTaskID = types.StringNull()
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -203,17 +230,18 @@ func (r *clumioAWSConnectionResource) Read(
 		return
 	}
 
-	awsConnection := aws_connections.NewAwsConnectionsV1(r.client.ClumioConfig)
+	awsConnection := aws_connections.NewAwsConnectionsV1(r.client.WithContext(ctx).ClumioConfig())
 	// To get the external_id field
 	queryParams := "true"
 	res, apiErr := awsConnection.ReadAwsConnection(state.ID.ValueString(), &queryParams)
 	if apiErr != nil {
-		if strings.Contains(apiErr.Error(), "The resource is not found.") {
-			state.ID = types.StringValue("")
+		if common.NewAPIError(apiErr).IsNotFound() {
+			common.RemoveMissingResource(ctx, resp, fmt.Sprintf(
+				"Clumio AWS Connection %s", state.ID.ValueString()))
+			return
 		}
-		resp.Diagnostics.AddError(
-			"Error retrieving Clumio AWS Connection.",
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			"Error retrieving Clumio AWS Connection.", apiErr, nil)...)
 		return
 	}
 	state.ConnectionStatus = types.StringValue(*res.ConnectionStatus)
@@ -230,7 +258,14 @@ func (r *clumioAWSConnectionResource) Read(
 	if res.Description != nil {
 		state.Description = types.StringValue(*res.Description)
 	}
-	if res.OrganizationalUnitId != nil {
+	common.SetTaskAttributeDefaults(&state.WaitForCompletion, &state.FailOnTaskError)
+	pending, diags := common.LastTaskDiagnostics(ctx, r.client, resp.Private,
+		state.WaitForCompletion, state.LastTaskID, state.FailOnTaskError,
+		fmt.Sprintf("Clumio AWS Connection %s", state.ID.ValueString()))
+	resp.Diagnostics.Append(diags...)
+	// While the task moving the connection to another organizational unit is pending, the
+	// connection still reports the previous one.
+	if res.OrganizationalUnitId != nil && !pending {
 		state.OrganizationalUnitID = types.StringValue(*res.OrganizationalUnitId)
 	}
 
@@ -253,6 +288,14 @@ func (r *clumioAWSConnectionResource) Update(
 		return
 	}
 
+	updateTimeout, diags := plan.Timeouts.Update(ctx, r.client.Timeouts().Update)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
 	var state clumioAWSConnectionResourceModel
 	diags = req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -260,30 +303,39 @@ func (r *clumioAWSConnectionResource) Update(
 		return
 	}
 
-	updated := updateOUForConnectionIfNeeded(ctx, r.client, req, resp)
+	updated, taskId := updateOUForConnectionIfNeeded(ctx, r.client, req, resp)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	plan.LastTaskID = state.LastTaskID
+	if taskId != "" {
+		plan.LastTaskID = types.StringValue(taskId)
+	}
 	if plan.Description == state.Description {
+		// The other computed attributes are unknown in the plan, so the state is updated
+		// with the settings of the configuration only.
 		if updated {
 			state.OrganizationalUnitID = plan.OrganizationalUnitID
-			diags = resp.State.Set(ctx, plan)
-			resp.Diagnostics.Append(diags...)
 		}
+		state.LastTaskID = plan.LastTaskID
+		state.WaitForCompletion = plan.WaitForCompletion
+		state.FailOnTaskError = plan.FailOnTaskError
+		state.Timeouts = plan.Timeouts
+		diags = resp.State.Set(ctx, state)
+		resp.Diagnostics.Append(diags...)
 		return
 	}
-	awsConnection := aws_connections.NewAwsConnectionsV1(r.client.ClumioConfig)
+	awsConnection := aws_connections.NewAwsConnectionsV1(r.client.WithContext(ctx).ClumioConfig())
 	description := plan.Description.ValueString()
 	res, apiErr := awsConnection.UpdateAwsConnection(plan.ID.ValueString(),
 		models.UpdateAwsConnectionV1Request{
 			Description: &description,
 		})
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
 			fmt.Sprintf(
 				"Error updating description of Clumio AWS Connection %v.",
-				plan.ID.ValueString()),
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+				plan.ID.ValueString()), apiErr, nil)...)
 		return
 	}
 	plan.Token = types.StringValue(*res.Token)
@@ -292,7 +344,11 @@ func (r *clumioAWSConnectionResource) Update(
 	}
 	plan.ClumioAWSAccountID = types.StringValue(*res.ClumioAwsAccountId)
 	plan.ClumioAWSRegion = types.StringValue(*res.ClumioAwsRegion)
-	plan.OrganizationalUnitID = types.StringValue(*res.OrganizationalUnitId)
+	// The connection only moves to the new organizational unit once the task of the move
+	// completes, so the planned organizational unit is kept when not waiting for it.
+	if !updated || plan.WaitForCompletion.ValueBool() {
+		plan.OrganizationalUnitID = types.StringValue(*res.OrganizationalUnitId)
+	}
 	plan.ConnectionStatus = types.StringValue(*res.ConnectionStatus)
 	setDataPlaneAccountId(&plan, res.DataPlaneAccountId)
 	plan.ID = types.StringValue(*res.Id)
@@ -305,24 +361,26 @@ func (r *clumioAWSConnectionResource) Update(
 }
 
 // updateOUForConnectionIfNeeded updates the OU for the connection if the new OU provided
-// is either the parent of the current OU or one of its immediate descendant.
+// is either the parent of the current OU or one of its immediate descendant. It also returns
+// the ID of the task started by the update, if any.
 func updateOUForConnectionIfNeeded(ctx context.Context, client *common.ApiClient,
-	req resource.UpdateRequest, resp *resource.UpdateResponse) bool {
+	req resource.UpdateRequest, resp *resource.UpdateResponse) (bool, string) {
 	ouUpdated := false
+	taskId := ""
 
 	// Retrieve values from plan
 	var plan clumioAWSConnectionResourceModel
 	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
-		return ouUpdated
+		return ouUpdated, taskId
 	}
 
 	var state clumioAWSConnectionResourceModel
 	diags = req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
-		return ouUpdated
+		return ouUpdated, taskId
 	}
 
 	if !plan.OrganizationalUnitID.IsUnknown() && plan.OrganizationalUnitID != state.OrganizationalUnitID {
@@ -333,16 +391,16 @@ func updateOUForConnectionIfNeeded(ctx context.Context, client *common.ApiClient
 					" is allowed only if the connection status in \"connected\". To make the"+
 					" connection status as connected, install the clumio terraform aws"+
 					" template module.")
-			return ouUpdated
+			return ouUpdated, taskId
 		}
 		envId := GetEnvironmentId(ctx, client, req, resp)
 		if resp.Diagnostics.HasError() {
-			return ouUpdated
+			return ouUpdated, taskId
 		}
 		ouIdStr, isNewOUCurrentOUParent :=
 			validateAndGetOUIDToPatch(ctx, client, req, resp)
 		if resp.Diagnostics.HasError() {
-			return ouUpdated
+			return ouUpdated, taskId
 		}
 		var removeEntityModels []*models.EntityModel
 		var addEntityModels []*models.EntityModel
@@ -366,25 +424,32 @@ func updateOUForConnectionIfNeeded(ctx context.Context, client *common.ApiClient
 				Remove: removeEntityModels,
 			},
 		}
-		orgUnitsAPI := orgUnits.NewOrganizationalUnitsV1(client.ClumioConfig)
+		orgUnitsAPI := orgUnits.NewOrganizationalUnitsV1(client.WithContext(ctx).ClumioConfig())
+		// The patch changes both the organizational unit and the AWS environment.
+		defer client.InvalidateLookups(
+			common.LookupAwsEnvironments, common.LookupOrganizationalUnits)
 		res, apiErr := orgUnitsAPI.PatchOrganizationalUnit(ouIdStr, nil, ouUpdateRequest)
 		if apiErr != nil {
 			resp.Diagnostics.AddError(
 				"Error updating the Organizational Unit for the connection.",
 				fmt.Sprintf(errorFmt, apiErr))
-			return ouUpdated
+			return ouUpdated, taskId
 		}
 		if res.StatusCode == http202 {
-			err := common.PollTask(ctx, client, *res.Http202.TaskId, pollTimeoutInSec, pollIntervalInSec)
-			if err != nil {
-				resp.Diagnostics.AddError("Error while polling for the Update OU task for the connection",
-					fmt.Sprintf(errorFmt, err))
-				return ouUpdated
+			taskId = *res.Http202.TaskId
+			if plan.WaitForCompletion.ValueBool() {
+				err := common.PollTask(ctx, client, taskId, pollIntervalInSec)
+				if err != nil {
+					resp.Diagnostics.AddError(
+						"Error updating the organizational unit of the AWS connection.",
+						fmt.Sprintf(errorFmt, err))
+					return ouUpdated, taskId
+				}
 			}
 		}
 		ouUpdated = true
 	}
-	return ouUpdated
+	return ouUpdated, taskId
 }
 
 // Delete deletes the resource and removes the Terraform state on success.
@@ -397,13 +462,22 @@ func (r *clumioAWSConnectionResource) Delete(
 		return
 	}
 
-	awsConnection := aws_connections.NewAwsConnectionsV1(r.client.ClumioConfig)
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, r.client.Timeouts().Delete)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	awsConnection := aws_connections.NewAwsConnectionsV1(r.client.WithContext(ctx).ClumioConfig())
+	defer r.client.InvalidateLookups(common.LookupAwsEnvironments)
 	_, apiErr := awsConnection.DeleteAwsConnection(state.ID.ValueString())
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
 			fmt.Sprintf(
 				"Error deleting Clumio AWS Connection %v.", state.ID.ValueString()),
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+			apiErr, nil)...)
 	}
 
 }
@@ -419,7 +493,7 @@ func GetEnvironmentId(ctx context.Context, client *common.ApiClient,
 		return ""
 	}
 
-	awsEnvironmentsAPI := awsEnvs.NewAwsEnvironmentsV1(client.ClumioConfig)
+	awsEnvironmentsAPI := awsEnvs.NewAwsEnvironmentsV1(client.WithContext(ctx).ClumioConfig())
 	accountNativeId := state.AccountNativeID.ValueString()
 	awsRegion := state.AWSRegion.ValueString()
 	limit := int64(1)
@@ -427,8 +501,10 @@ func GetEnvironmentId(ctx context.Context, client *common.ApiClient,
 		"{\"account_native_id\":{\"$eq\":\"%v\"}, \"aws_region\":{\"$eq\":\"%v\"}}",
 		accountNativeId, awsRegion)
 	embed := "read-organizational-unit"
-	envs, apiErr := awsEnvironmentsAPI.ListAwsEnvironments(
-		&limit, nil, &filterStr, &embed)
+	envs, apiErr := common.Lookup(ctx, client, common.LookupAwsEnvironments, filterStr,
+		func() (*models.ListAWSEnvironmentsResponse, *apiutils.APIError) {
+			return awsEnvironmentsAPI.ListAwsEnvironments(&limit, nil, &filterStr, &embed)
+		})
 	if apiErr != nil {
 		resp.Diagnostics.AddError(
 			"Error retrieving AWS Environment.",
@@ -464,11 +540,14 @@ func validateAndGetOUIDToPatch(ctx context.Context, client *common.ApiClient,
 	if resp.Diagnostics.HasError() {
 		return "", false
 	}
-	orgUnitsAPI := orgUnits.NewOrganizationalUnitsV1(client.ClumioConfig)
+	orgUnitsAPI := orgUnits.NewOrganizationalUnitsV1(client.WithContext(ctx).ClumioConfig())
 	isValidNewOU := false
 	isNewOUCurrentOUParent := false
 	oldOUIdStr := state.OrganizationalUnitID.ValueString()
-	oldOU, apiErr := orgUnitsAPI.ReadOrganizationalUnit(oldOUIdStr, nil)
+	oldOU, apiErr := common.Lookup(ctx, client, common.LookupOrganizationalUnits, oldOUIdStr,
+		func() (*models.ReadOrganizationalUnitResponseV1, *apiutils.APIError) {
+			return orgUnitsAPI.ReadOrganizationalUnit(oldOUIdStr, nil)
+		})
 	if apiErr != nil {
 		resp.Diagnostics.AddError(fmt.Sprintf("Error retrieving current OU %v.",
 			state.OrganizationalUnitID.ValueString()),
@@ -483,7 +562,10 @@ func validateAndGetOUIDToPatch(ctx context.Context, client *common.ApiClient,
 		ouIdStr = oldOUIdStr
 	}
 	filterStr := fmt.Sprintf("{\"parent_id\": {\"$eq\": \"%v\"}}", oldOUIdStr)
-	listRes, apiErr := orgUnitsAPI.ListOrganizationalUnits(nil, nil, &filterStr)
+	listRes, apiErr := common.Lookup(ctx, client, common.LookupOrganizationalUnits, filterStr,
+		func() (*models.ListOrganizationalUnitsResponseV1, *apiutils.APIError) {
+			return orgUnitsAPI.ListOrganizationalUnits(nil, nil, &filterStr)
+		})
 	if apiErr != nil {
 		resp.Diagnostics.AddError(
 			fmt.Sprintf("Error retrieving child OUs of current OU: %v.",
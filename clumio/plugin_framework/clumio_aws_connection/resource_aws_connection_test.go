@@ -4,11 +4,13 @@
 package clumio_aws_connection_test
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"regexp"
 	"testing"
 
+	aws_connections "github.com/clumio-code/clumio-go-sdk/controllers/aws_connections"
 	clumio_pf "github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -40,10 +42,31 @@ func TestAccResourceClumioAwsConnection(t *testing.T) {
 						regexp.MustCompile(accountNativeId)),
 				),
 			},
+			{
+				ResourceName:      "clumio_aws_connection.test_conn",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: getTestAccResourceClumioCallbackAwsConnection(
+					baseUrl, accountNativeId, testAwsRegion, "test_description_updated"),
+				Check: clumio_pf.UtilTestCheckResourceDisappears(
+					"clumio_aws_connection.test_conn", testAccDeleteAwsConnection),
+				ExpectNonEmptyPlan: true,
+			},
 		},
 	})
 }
 
+// testAccDeleteAwsConnection deletes the AWS connection outside of Terraform.
+func testAccDeleteAwsConnection(_ context.Context, client *common.ApiClient, id string) error {
+	awsConnection := aws_connections.NewAwsConnectionsV1(client.ClumioConfig())
+	if _, apiErr := awsConnection.DeleteAwsConnection(id); apiErr != nil {
+		return common.NewAPIError(apiErr)
+	}
+	return nil
+}
+
 func getTestAccResourceClumioCallbackAwsConnection(
 	baseUrl string, accountId string, awsRegion string, description string) string {
 	return fmt.Sprintf(testAccResourceClumioAwsConnection, baseUrl, accountId,
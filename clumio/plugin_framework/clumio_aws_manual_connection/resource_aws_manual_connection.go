@@ -247,7 +247,7 @@ func (r *awsManualConnectionResource) Configure(
 // clumioSetManualResourcesCommon contains the logic for updating resources of a manual connection.
 func (r *awsManualConnectionResource) clumioSetManualResourcesCommon(
 	ctx context.Context, state AwsManualConnectionModel) diag.Diagnostics {
-	awsConnection := aws_connections.NewAwsConnectionsV1(r.client.ClumioConfig)
+	awsConnection := aws_connections.NewAwsConnectionsV1(r.client.WithContext(ctx).ClumioConfig())
 	accountId := state.AccountId.ValueString()
 	awsRegion := state.AwsRegion.ValueString()
 
@@ -309,9 +309,8 @@ func (r *awsManualConnectionResource) clumioSetManualResourcesCommon(
 		},
 	)
 	if apiErr != nil {
-		diagnostics := diag.Diagnostics{}
-		diagnostics.AddError("Error in updating resources of Clumio AWS Manual Connection.", string(apiErr.Response))
-		return diagnostics
+		return common.APIErrorDiagnostics(
+			"Error in updating resources of Clumio AWS Manual Connection.", apiErr, nil)
 	}
 	return nil
 }
@@ -59,9 +59,9 @@ func TestManualResources(t *testing.T) {
 						resource.TestMatchResourceAttr(
 							"clumio_aws_manual_connection.test_update_resources", "aws_region",
 							regexp.MustCompile(testAwsRegion)),
-							resource.TestMatchResourceAttr(
-								"clumio_aws_manual_connection.test_update_resources", "connection_status",
-								regexp.MustCompile("connected")),
+							resource.TestCheckResourceAttr(
+								"clumio_aws_manual_connection.test_update_resources", "id",
+								testAccountId+"_"+testAwsRegion),
 				),
 			},
 		},
@@ -81,14 +81,14 @@ func getTestClumioAwsManualConnection(
 			testAssetTypes["DynamoDB"],
 			testAssetTypes["S3"],
 			testAssetTypes["EC2MSSQL"],
-			testResources.ClumioIAMRoleArn,
-			testResources.ClumioEventPubArn,
-			testResources.ClumioSupportRoleArn,
-			testResources.EventRules.CloudtrailRuleArn,
-			testResources.EventRules.CloudwatchRuleArn,
-			testResources.ServiceRoles.S3.ContinuousBackupsRoleArn,
-			testResources.ServiceRoles.Mssql.SsmNotificationRoleArn,
-			testResources.ServiceRoles.Mssql.Ec2SsmInstanceProfileArn,
+			testResources.ClumioIAMRoleArn.ValueString(),
+			testResources.ClumioEventPubArn.ValueString(),
+			testResources.ClumioSupportRoleArn.ValueString(),
+			testResources.EventRules.CloudtrailRuleArn.ValueString(),
+			testResources.EventRules.CloudwatchRuleArn.ValueString(),
+			testResources.ServiceRoles.S3.ContinuousBackupsRoleArn.ValueString(),
+			testResources.ServiceRoles.Mssql.SsmNotificationRoleArn.ValueString(),
+			testResources.ServiceRoles.Mssql.Ec2SsmInstanceProfileArn.ValueString(),
 		)
 }
 
@@ -97,10 +97,15 @@ provider clumio{
    clumio_api_base_url = "%s"
 }
 
+resource "clumio_aws_connection" "test_conn" {
+    account_native_id = "%s"
+    aws_region = "%s"
+}
+
 resource "clumio_aws_manual_connection" "test_update_resources" {
 	
-    account_id = "%s"
-    aws_region = "%s"
+    account_id = clumio_aws_connection.test_conn.account_native_id
+    aws_region = clumio_aws_connection.test_conn.aws_region
     assets_enabled = {
         ebs = %t
         rds = %t
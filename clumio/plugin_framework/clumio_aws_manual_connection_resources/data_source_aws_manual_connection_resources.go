@@ -140,7 +140,7 @@ func (r *awsManualConnectionResourcesDatasource) Read(ctx context.Context, req d
 	awsRegion := state.AwsRegion.ValueString()
 	showManualResources := true
 
-	awsTemplates := aws_templates.NewAwsTemplatesV1(r.client.ClumioConfig)
+	awsTemplates := aws_templates.NewAwsTemplatesV1(r.client.WithContext(ctx).ClumioConfig())
 	apiRes, apiErr := awsTemplates.CreateConnectionTemplate(&models.CreateConnectionTemplateV1Request{
 		ShowManualResources: &showManualResources,
 		AssetTypesEnabled: assetsEnabled,
@@ -148,8 +148,12 @@ func (r *awsManualConnectionResourcesDatasource) Read(ctx context.Context, req d
 		AwsRegion: &awsRegion,
 	})
 
-	if apiRes.Resources == nil {
-		res.Diagnostics.AddError("Failed to get resources from API", string(apiErr.Response))
+	if apiErr != nil {
+		res.Diagnostics.Append(common.APIErrorDiagnostics(
+			"Failed to get resources from API", apiErr, nil)...)
+	} else if apiRes.Resources == nil {
+		res.Diagnostics.AddError("Failed to get resources from API",
+			"The API response did not include any resources.")
 	}
 	if res.Diagnostics.HasError() {
 		return
@@ -35,7 +35,7 @@ func TestAwsManualConnectionResources(t *testing.T) {
 					baseUrl, testAccountId, testAwsRegion, testAssetTypes),
 				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttrWith(
-						"clumio_aws_manual_connection_resources.test_update_resources",
+						"data.clumio_aws_manual_connection_resources.test_get_resources",
 						"resources",
 						func(value string) error {
 							if len(value) == 0 {
@@ -0,0 +1,191 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the helpers shared by the functions building Clumio condition documents, such as
+// the condition of a policy rule or the bucket rule of a protection group.
+
+package clumio_functions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementations satisfy the expected interfaces.
+var (
+	_ function.StringParameterValidator = conditionValidator{}
+	_ function.StringParameterValidator = notEmptyValidator{}
+	_ function.ListParameterValidator   = stringListValidator{}
+)
+
+// condition is a Clumio condition document. It maps each field to its filters, keyed by their
+// operator, such as {"aws_tag":{"$eq":{"key":"Environment","value":"Prod"}}}.
+type condition map[string]map[string]any
+
+// parseCondition parses a condition document and checks that each of its fields has at least
+// one filter.
+func parseCondition(document string) (condition, error) {
+	decoder := json.NewDecoder(strings.NewReader(document))
+	decoder.UseNumber()
+	var cond condition
+	if err := decoder.Decode(&cond); err != nil {
+		return nil, fmt.Errorf("the condition is not a JSON object of fields and filters: %v", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("the condition is followed by unexpected data")
+	}
+	if len(cond) == 0 {
+		return nil, errors.New("the condition does not filter any field")
+	}
+	for _, field := range cond.fields() {
+		if len(cond[field]) == 0 {
+			return nil, fmt.Errorf("the field %q of the condition has no filter", field)
+		}
+		for operator := range cond[field] {
+			if !strings.HasPrefix(operator, "$") {
+				return nil, fmt.Errorf(
+					"the filter %q of the field %q is not an operator such as $eq or $in",
+					operator, field)
+			}
+		}
+	}
+	return cond, nil
+}
+
+// fields returns the fields filtered by the condition, sorted.
+func (c condition) fields() []string {
+	fields := make([]string, 0, len(c))
+	for field := range c {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// marshal returns the canonical JSON of the condition: compact, with its keys sorted.
+func (c condition) marshal() (string, error) {
+	return canonicalJSON(c)
+}
+
+// canonicalJSON returns the compact JSON of value, with the keys of its objects sorted.
+func canonicalJSON(value any) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// setResult sets the canonical JSON of the condition as the result of the function.
+func setResult(ctx context.Context, cond condition, resp *function.RunResponse) {
+	document, err := cond.marshal()
+	if err != nil {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewFuncError(
+			fmt.Sprintf("Unable to encode the condition: %v", err)))
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, document))
+}
+
+// conditionValidator validates that a string argument is a condition document.
+type conditionValidator struct{}
+
+// ValidateParameterString performs the validation.
+func (v conditionValidator) ValidateParameterString(_ context.Context,
+	req function.StringParameterValidatorRequest, resp *function.StringParameterValidatorResponse) {
+
+	if req.Value.IsNull() || req.Value.IsUnknown() {
+		return
+	}
+	if _, err := parseCondition(req.Value.ValueString()); err != nil {
+		resp.Error = function.NewArgumentFuncError(req.ArgumentPosition,
+			fmt.Sprintf("Invalid condition: %v", err))
+	}
+}
+
+// notEmptyValidator validates that a string argument is not empty.
+type notEmptyValidator struct{}
+
+// ValidateParameterString performs the validation.
+func (v notEmptyValidator) ValidateParameterString(_ context.Context,
+	req function.StringParameterValidatorRequest, resp *function.StringParameterValidatorResponse) {
+
+	if req.Value.IsNull() || req.Value.IsUnknown() {
+		return
+	}
+	if req.Value.ValueString() == "" {
+		resp.Error = function.NewArgumentFuncError(req.ArgumentPosition,
+			"The value must not be empty")
+	}
+}
+
+// stringListValidator validates that a list argument holds at least one value, that its
+// values are unique and that each of them matches pattern, or is one of allowed if set.
+type stringListValidator struct {
+	pattern *regexp.Regexp
+	// allowed lists the only values accepted, in place of pattern.
+	allowed []string
+	// description describes the valid values, such as "an AWS account ID".
+	description string
+}
+
+// ValidateParameterList performs the validation.
+func (v stringListValidator) ValidateParameterList(ctx context.Context,
+	req function.ListParameterValidatorRequest, resp *function.ListParameterValidatorResponse) {
+
+	if req.Value.IsNull() || req.Value.IsUnknown() {
+		return
+	}
+	var values []types.String
+	diags := req.Value.ElementsAs(ctx, &values, true)
+	if diags.HasError() {
+		resp.Error = function.FuncErrorFromDiags(ctx, diags)
+		return
+	}
+	if len(values) == 0 {
+		resp.Error = function.NewArgumentFuncError(req.ArgumentPosition,
+			"The list must hold at least one value")
+		return
+	}
+	seen := make(map[string]bool, len(values))
+	for idx, value := range values {
+		if value.IsNull() {
+			resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(
+				req.ArgumentPosition, fmt.Sprintf("The value at index %d is null", idx)))
+			continue
+		}
+		str := value.ValueString()
+		if v.allowed != nil && !slices.Contains(v.allowed, str) {
+			resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(
+				req.ArgumentPosition, fmt.Sprintf(
+					"The value %q at index %d is not %s, expected one of: %s", str, idx,
+					v.description, strings.Join(v.allowed, ", "))))
+		} else if v.allowed == nil && !v.pattern.MatchString(str) {
+			resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(
+				req.ArgumentPosition, fmt.Sprintf(
+					"The value %q at index %d is not %s", str, idx, v.description)))
+		}
+		if seen[str] {
+			resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(
+				req.ArgumentPosition, fmt.Sprintf("The value %q is listed more than once", str)))
+		}
+		seen[str] = true
+	}
+}
+
+// inCondition returns the condition matching any of the values of the field.
+func inCondition(field string, values []string) condition {
+	return condition{field: {operatorIn: values}}
+}
@@ -0,0 +1,205 @@
+// Copyright 2024. Clumio, Inc.
+
+// Unit tests of the condition functions, run without Terraform.
+package clumio_functions
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// stringList returns the list value of the given strings.
+func stringList(values ...string) attr.Value {
+	elements := make([]attr.Value, 0, len(values))
+	for _, value := range values {
+		elements = append(elements, types.StringValue(value))
+	}
+	return types.ListValueMust(types.StringType, elements)
+}
+
+// conditions returns the variadic argument of the given condition documents.
+func conditions(documents ...string) attr.Value {
+	elementTypes := make([]attr.Type, 0, len(documents))
+	elements := make([]attr.Value, 0, len(documents))
+	for _, document := range documents {
+		elementTypes = append(elementTypes, types.StringType)
+		elements = append(elements, types.StringValue(document))
+	}
+	return types.TupleValueMust(elementTypes, elements)
+}
+
+// run runs the function with the given arguments and returns its result and error message.
+func run(f function.Function, arguments ...attr.Value) (string, string) {
+	resp := &function.RunResponse{Result: function.NewResultData(types.StringUnknown())}
+	f.Run(context.Background(), function.RunRequest{
+		Arguments: function.NewArgumentsData(arguments),
+	}, resp)
+	if resp.Error != nil {
+		return "", resp.Error.Error()
+	}
+	return resp.Result.Value().(types.String).ValueString(), ""
+}
+
+func TestRun(t *testing.T) {
+	for name, test := range map[string]struct {
+		function  function.Function
+		arguments []attr.Value
+		want      string
+		wantError string
+	}{
+		"tag_equals": {
+			function:  NewTagEqualsFunction(),
+			arguments: []attr.Value{types.StringValue("Environment"), types.StringValue("Prod")},
+			want:      `{"aws_tag":{"$eq":{"key":"Environment","value":"Prod"}}}`,
+		},
+		"entity_type_in": {
+			function:  NewEntityTypeInFunction(),
+			arguments: []attr.Value{stringList("aws_ebs_volume", "aws_ec2_instance")},
+			want:      `{"entity_type":{"$in":["aws_ebs_volume","aws_ec2_instance"]}}`,
+		},
+		"account_in": {
+			function:  NewAccountInFunction(),
+			arguments: []attr.Value{stringList("123456789012")},
+			want:      `{"aws_account_native_id":{"$in":["123456789012"]}}`,
+		},
+		"any_of equal tags": {
+			function: NewAnyOfFunction(),
+			arguments: []attr.Value{conditions(
+				`{"aws_tag":{"$eq":{"key":"Environment","value":"Prod"}}}`,
+				`{"aws_tag":{"$eq":{"value":"Staging","key":"Environment"}}}`)},
+			want: `{"aws_tag":{"$in":[{"key":"Environment","value":"Prod"},` +
+				`{"key":"Environment","value":"Staging"}]}}`,
+		},
+		"any_of different fields": {
+			function: NewAnyOfFunction(),
+			arguments: []attr.Value{conditions(
+				`{"aws_tag":{"$eq":{"key":"Environment","value":"Prod"}}}`,
+				`{"entity_type":{"$in":["aws_ebs_volume"]}}`)},
+			wantError: "must all filter the same field",
+		},
+		"any_of without conditions": {
+			function:  NewAnyOfFunction(),
+			arguments: []attr.Value{conditions()},
+			wantError: "At least one condition is required",
+		},
+		"all_of": {
+			function: NewAllOfFunction(),
+			arguments: []attr.Value{conditions(
+				`{"entity_type":{"$in":["aws_ebs_volume"]}}`,
+				`{"aws_tag":{"$eq":{"key":"Environment","value":"Prod"}}}`)},
+			want: `{"aws_tag":{"$eq":{"key":"Environment","value":"Prod"}},` +
+				`"entity_type":{"$in":["aws_ebs_volume"]}}`,
+		},
+		"all_of same field": {
+			function: NewAllOfFunction(),
+			arguments: []attr.Value{conditions(
+				`{"entity_type":{"$in":["aws_ebs_volume"]}}`,
+				`{"entity_type":{"$eq":"aws_ec2_instance"}}`)},
+			wantError: "already filtered by a previous condition",
+		},
+		"all_of invalid condition": {
+			function:  NewAllOfFunction(),
+			arguments: []attr.Value{conditions(`{"entity_type":{}}`)},
+			wantError: "has no filter",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, gotError := run(test.function, test.arguments...)
+			if test.wantError != "" {
+				if !strings.Contains(gotError, test.wantError) {
+					t.Errorf("got error %q, want one containing %q", gotError, test.wantError)
+				}
+				return
+			}
+			if gotError != "" || got != test.want {
+				t.Errorf("got %s, %q, want %s", got, gotError, test.want)
+			}
+		})
+	}
+}
+
+func TestStringListValidator(t *testing.T) {
+	validator := stringListValidator{
+		allowed:     entityTypes,
+		description: "an entity type supported by the policy rules",
+	}
+	for name, test := range map[string]struct {
+		value     types.List
+		wantError string
+	}{
+		"valid": {
+			value: stringList("aws_ebs_volume", "aws_ec2_instance").(types.List),
+		},
+		"unknown entity type": {
+			value:     stringList("aws_ebs_volum").(types.List),
+			wantError: `"aws_ebs_volum" at index 0 is not an entity type supported`,
+		},
+		"null": {
+			value: types.ListNull(types.StringType),
+		},
+		"empty": {
+			value:     stringList().(types.List),
+			wantError: "at least one value",
+		},
+		"invalid": {
+			value:     stringList("AWS EBS volume").(types.List),
+			wantError: `"AWS EBS volume" at index 0 is not an entity type`,
+		},
+		"duplicate": {
+			value:     stringList("aws_ebs_volume", "aws_ebs_volume").(types.List),
+			wantError: "listed more than once",
+		},
+		"null value": {
+			value: types.ListValueMust(types.StringType,
+				[]attr.Value{types.StringNull()}),
+			wantError: "index 0 is null",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp := &function.ListParameterValidatorResponse{}
+			validator.ValidateParameterList(context.Background(),
+				function.ListParameterValidatorRequest{Value: test.value}, resp)
+			gotError := ""
+			if resp.Error != nil {
+				gotError = resp.Error.Error()
+			}
+			if test.wantError == "" && gotError != "" ||
+				!strings.Contains(gotError, test.wantError) {
+				t.Errorf("got error %q, want %q", gotError, test.wantError)
+			}
+		})
+	}
+}
+
+func TestConditionValidator(t *testing.T) {
+	for name, test := range map[string]struct {
+		value     string
+		wantError string
+	}{
+		"valid":        {value: `{"entity_type":{"$eq":"aws_ebs_volume"}}`},
+		"not JSON":     {value: `entity_type`, wantError: "not a JSON object"},
+		"trailing":     {value: `{"a":{"$eq":1}} {}`, wantError: "followed by unexpected data"},
+		"no field":     {value: `{}`, wantError: "does not filter any field"},
+		"not operator": {value: `{"a":{"eq":1}}`, wantError: "is not an operator"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp := &function.StringParameterValidatorResponse{}
+			conditionValidator{}.ValidateParameterString(context.Background(),
+				function.StringParameterValidatorRequest{Value: types.StringValue(test.value)},
+				resp)
+			gotError := ""
+			if resp.Error != nil {
+				gotError = resp.Error.Error()
+			}
+			if test.wantError == "" && gotError != "" ||
+				!strings.Contains(gotError, test.wantError) {
+				t.Errorf("got error %q, want %q", gotError, test.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright 2024. Clumio, Inc.
+
+package clumio_functions
+
+const (
+	paramKey         = "key"
+	paramValue       = "value"
+	paramEntityTypes = "entity_types"
+	paramAccountIds  = "account_ids"
+	paramConditions  = "conditions"
+
+	fieldAwsTag             = "aws_tag"
+	fieldEntityType         = "entity_type"
+	fieldAwsAccountNativeId = "aws_account_native_id"
+
+	operatorEq = "$eq"
+	operatorIn = "$in"
+
+	tagKey   = "key"
+	tagValue = "value"
+)
@@ -0,0 +1,71 @@
+// Copyright 2024. Clumio, Inc.
+//
+// account_in function definition and run implementation.
+
+package clumio_functions
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &accountInFunction{}
+
+// accountIdPattern matches the AWS account IDs.
+var accountIdPattern = regexp.MustCompile(`^[0-9]{12}$`)
+
+// NewAccountInFunction is a helper function to simplify the provider implementation.
+func NewAccountInFunction() function.Function {
+	return &accountInFunction{}
+}
+
+// accountInFunction is the function implementation.
+type accountInFunction struct{}
+
+// Metadata returns the function name.
+func (f *accountInFunction) Metadata(
+	_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "account_in"
+}
+
+// Definition defines the parameters and the return type of the function.
+func (f *accountInFunction) Definition(
+	_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+
+	resp.Definition = function.Definition{
+		Summary: "Builds a condition matching the assets of any of the given AWS accounts.",
+		MarkdownDescription: "Returns the condition document matching the assets of any of the" +
+			" given AWS accounts, such as" +
+			" `{\"aws_account_native_id\":{\"$in\":[\"123456789012\"]}}`.",
+		Parameters: []function.Parameter{
+			function.ListParameter{
+				Name:        paramAccountIds,
+				Description: "The 12-digit IDs of the AWS accounts.",
+				ElementType: types.StringType,
+				Validators: []function.ListParameterValidator{
+					stringListValidator{
+						pattern:     accountIdPattern,
+						description: "an AWS account ID of 12 digits",
+					},
+				},
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run builds the condition document.
+func (f *accountInFunction) Run(
+	ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+
+	var accountIds []string
+	resp.Error = req.Arguments.Get(ctx, &accountIds)
+	if resp.Error != nil {
+		return
+	}
+	setResult(ctx, inCondition(fieldAwsAccountNativeId, accountIds), resp)
+}
@@ -0,0 +1,86 @@
+// Copyright 2024. Clumio, Inc.
+//
+// all_of function definition and run implementation.
+
+package clumio_functions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &allOfFunction{}
+
+// NewAllOfFunction is a helper function to simplify the provider implementation.
+func NewAllOfFunction() function.Function {
+	return &allOfFunction{}
+}
+
+// allOfFunction is the function implementation.
+type allOfFunction struct{}
+
+// Metadata returns the function name.
+func (f *allOfFunction) Metadata(
+	_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "all_of"
+}
+
+// Definition defines the parameters and the return type of the function.
+func (f *allOfFunction) Definition(
+	_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+
+	resp.Definition = function.Definition{
+		Summary: "Combines conditions on different fields into a condition matching all of them.",
+		MarkdownDescription: "Returns the condition document matching the assets that match all" +
+			" of the given conditions, by merging their filters. A field may only be filtered by" +
+			" one of the conditions. For example, `all_of(entity_type_in([\"aws_ebs_volume\"])," +
+			" tag_equals(\"Environment\", \"Prod\"))` returns" +
+			" `{\"aws_tag\":{\"$eq\":{\"key\":\"Environment\",\"value\":\"Prod\"}}," +
+			"\"entity_type\":{\"$in\":[\"aws_ebs_volume\"]}}`.",
+		VariadicParameter: function.StringParameter{
+			Name:        paramConditions,
+			Description: "The condition documents to combine.",
+			Validators:  []function.StringParameterValidator{conditionValidator{}},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run builds the condition document.
+func (f *allOfFunction) Run(
+	ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+
+	var documents []string
+	resp.Error = req.Arguments.Get(ctx, &documents)
+	if resp.Error != nil {
+		return
+	}
+	if len(documents) == 0 {
+		resp.Error = function.NewFuncError("At least one condition is required")
+		return
+	}
+
+	merged := condition{}
+	for idx, document := range documents {
+		pos := int64(idx)
+		cond, err := parseCondition(document)
+		if err != nil {
+			resp.Error = function.NewArgumentFuncError(pos,
+				fmt.Sprintf("Invalid condition: %v", err))
+			return
+		}
+		for _, field := range cond.fields() {
+			if _, ok := merged[field]; ok {
+				resp.Error = function.NewArgumentFuncError(pos, fmt.Sprintf("The field %q is"+
+					" already filtered by a previous condition. Use any_of to match any of"+
+					" several values of a field", field))
+				return
+			}
+			merged[field] = cond[field]
+		}
+	}
+	setResult(ctx, merged, resp)
+}
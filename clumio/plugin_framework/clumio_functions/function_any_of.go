@@ -0,0 +1,130 @@
+// Copyright 2024. Clumio, Inc.
+//
+// any_of function definition and run implementation.
+
+package clumio_functions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &anyOfFunction{}
+
+// NewAnyOfFunction is a helper function to simplify the provider implementation.
+func NewAnyOfFunction() function.Function {
+	return &anyOfFunction{}
+}
+
+// anyOfFunction is the function implementation.
+type anyOfFunction struct{}
+
+// Metadata returns the function name.
+func (f *anyOfFunction) Metadata(
+	_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "any_of"
+}
+
+// Definition defines the parameters and the return type of the function.
+func (f *anyOfFunction) Definition(
+	_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+
+	resp.Definition = function.Definition{
+		Summary: "Combines conditions on the same field into a condition matching any of them.",
+		MarkdownDescription: "Returns the condition document matching the assets that match any" +
+			" of the given conditions. The conditions must all filter the same field with an" +
+			" `$eq` or `$in` filter, and are combined into a single `$in` filter. For example," +
+			" `any_of(tag_equals(\"Environment\", \"Prod\"), tag_equals(\"Environment\"," +
+			" \"Staging\"))` returns `{\"aws_tag\":{\"$in\":[{\"key\":\"Environment\"," +
+			"\"value\":\"Prod\"},{\"key\":\"Environment\",\"value\":\"Staging\"}]}}`.",
+		VariadicParameter: function.StringParameter{
+			Name:        paramConditions,
+			Description: "The condition documents to combine.",
+			Validators:  []function.StringParameterValidator{conditionValidator{}},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run builds the condition document.
+func (f *anyOfFunction) Run(
+	ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+
+	var documents []string
+	resp.Error = req.Arguments.Get(ctx, &documents)
+	if resp.Error != nil {
+		return
+	}
+	if len(documents) == 0 {
+		resp.Error = function.NewFuncError("At least one condition is required")
+		return
+	}
+
+	field := ""
+	values := make([]any, 0, len(documents))
+	seen := map[string]bool{}
+	for idx, document := range documents {
+		pos := int64(idx)
+		cond, err := parseCondition(document)
+		if err != nil {
+			resp.Error = function.NewArgumentFuncError(pos,
+				fmt.Sprintf("Invalid condition: %v", err))
+			return
+		}
+		if len(cond) != 1 {
+			resp.Error = function.NewArgumentFuncError(pos, "The condition filters more than"+
+				" one field, so it cannot be combined with any_of. Use all_of to combine"+
+				" filters on different fields")
+			return
+		}
+		condField := cond.fields()[0]
+		if field != "" && condField != field {
+			resp.Error = function.NewArgumentFuncError(pos, fmt.Sprintf("The condition"+
+				" filters the field %q, but the previous conditions filter the field %q."+
+				" The conditions combined with any_of must all filter the same field",
+				condField, field))
+			return
+		}
+		field = condField
+
+		filters := cond[field]
+		var operands []any
+		switch {
+		case len(filters) != 1:
+			resp.Error = function.NewArgumentFuncError(pos, fmt.Sprintf("The field %q has"+
+				" more than one filter, so it cannot be combined with any_of", field))
+			return
+		case filters[operatorEq] != nil:
+			operands = []any{filters[operatorEq]}
+		case filters[operatorIn] != nil:
+			list, ok := filters[operatorIn].([]any)
+			if !ok {
+				resp.Error = function.NewArgumentFuncError(pos, fmt.Sprintf(
+					"The %s filter of the field %q is not a list", operatorIn, field))
+				return
+			}
+			operands = list
+		default:
+			resp.Error = function.NewArgumentFuncError(pos, fmt.Sprintf("The filter of the"+
+				" field %q cannot be combined with any_of. Only %s and %s filters can",
+				field, operatorEq, operatorIn))
+			return
+		}
+		for _, operand := range operands {
+			key, err := canonicalJSON(operand)
+			if err != nil {
+				resp.Error = function.NewArgumentFuncError(pos, fmt.Sprintf(
+					"Invalid filter of the field %q: %v", field, err))
+				return
+			}
+			if !seen[key] {
+				seen[key] = true
+				values = append(values, operand)
+			}
+		}
+	}
+	setResult(ctx, condition{field: {operatorIn: values}}, resp)
+}
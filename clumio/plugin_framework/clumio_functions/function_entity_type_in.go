@@ -0,0 +1,82 @@
+// Copyright 2024. Clumio, Inc.
+//
+// entity_type_in function definition and run implementation.
+
+package clumio_functions
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &entityTypeInFunction{}
+
+// entityTypes are the entity types the conditions of the policy rules can filter on, as
+// documented by the Clumio API. They must be kept in sync with the API as it supports new ones.
+var entityTypes = []string{
+	"aws_dynamodb_table",
+	"aws_ebs_volume",
+	"aws_ec2_instance",
+	"aws_rds_cluster",
+	"aws_rds_instance",
+}
+
+// NewEntityTypeInFunction is a helper function to simplify the provider implementation.
+func NewEntityTypeInFunction() function.Function {
+	return &entityTypeInFunction{}
+}
+
+// entityTypeInFunction is the function implementation.
+type entityTypeInFunction struct{}
+
+// Metadata returns the function name.
+func (f *entityTypeInFunction) Metadata(
+	_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "entity_type_in"
+}
+
+// Definition defines the parameters and the return type of the function.
+func (f *entityTypeInFunction) Definition(
+	_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+
+	resp.Definition = function.Definition{
+		Summary: "Builds a condition matching the assets of any of the given entity types.",
+		MarkdownDescription: "Returns the condition document matching the assets of any of the" +
+			" given entity types, such as" +
+			" `{\"entity_type\":{\"$in\":[\"aws_ebs_volume\",\"aws_ec2_instance\"]}}`. The" +
+			" `condition` of a `clumio_policy_rule` requires an entity type filter, so it is" +
+			" usually combined with the other filters of the rule through `all_of`. The entity" +
+			" types must be ones supported by the policy rules: `" +
+			strings.Join(entityTypes, "`, `") + "`.",
+		Parameters: []function.Parameter{
+			function.ListParameter{
+				Name:        paramEntityTypes,
+				Description: "The entity types, such as aws_ebs_volume or aws_ec2_instance.",
+				ElementType: types.StringType,
+				Validators: []function.ListParameterValidator{
+					stringListValidator{
+						allowed:     entityTypes,
+						description: "an entity type supported by the policy rules",
+					},
+				},
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run builds the condition document.
+func (f *entityTypeInFunction) Run(
+	ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+
+	var entityTypes []string
+	resp.Error = req.Arguments.Get(ctx, &entityTypes)
+	if resp.Error != nil {
+		return
+	}
+	setResult(ctx, inCondition(fieldEntityType, entityTypes), resp)
+}
@@ -0,0 +1,70 @@
+// Copyright 2024. Clumio, Inc.
+//
+// tag_equals function definition and run implementation.
+
+package clumio_functions
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &tagEqualsFunction{}
+
+// NewTagEqualsFunction is a helper function to simplify the provider implementation.
+func NewTagEqualsFunction() function.Function {
+	return &tagEqualsFunction{}
+}
+
+// tagEqualsFunction is the function implementation.
+type tagEqualsFunction struct{}
+
+// Metadata returns the function name.
+func (f *tagEqualsFunction) Metadata(
+	_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "tag_equals"
+}
+
+// Definition defines the parameters and the return type of the function.
+func (f *tagEqualsFunction) Definition(
+	_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+
+	resp.Definition = function.Definition{
+		Summary: "Builds a condition matching the assets with an AWS tag.",
+		MarkdownDescription: "Returns the condition document matching the assets that have the" +
+			" AWS tag with the given key and value, such as" +
+			" `{\"aws_tag\":{\"$eq\":{\"key\":\"Environment\",\"value\":\"Prod\"}}}`. It can be" +
+			" used as the `bucket_rule` of a `clumio_protection_group`, or combined with" +
+			" `entity_type_in` through `all_of` as the `condition` of a `clumio_policy_rule`.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        paramKey,
+				Description: "The key of the AWS tag.",
+				Validators:  []function.StringParameterValidator{notEmptyValidator{}},
+			},
+			function.StringParameter{
+				Name:        paramValue,
+				Description: "The value of the AWS tag.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run builds the condition document.
+func (f *tagEqualsFunction) Run(
+	ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+
+	var key, value string
+	resp.Error = req.Arguments.Get(ctx, &key, &value)
+	if resp.Error != nil {
+		return
+	}
+	tag := map[string]string{
+		tagKey:   key,
+		tagValue: value,
+	}
+	setResult(ctx, condition{fieldAwsTag: {operatorEq: tag}}, resp)
+}
@@ -0,0 +1,134 @@
+// Copyright 2024. Clumio, Inc.
+//
+// Acceptance test for the condition functions.
+
+package clumio_functions_test
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	clumio_pf "github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework"
+
+	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// TestAccClumioConditionFunctions tests the condition functions. Provider functions can only be
+// called through a provider declared in required_providers, and the acceptance tests serve the
+// provider as hashicorp/clumio.
+func TestAccClumioConditionFunctions(t *testing.T) {
+	baseUrl := os.Getenv(common.ClumioApiBaseUrl)
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { clumio_pf.UtilTestAccPreCheckClumio(t) },
+		ProtoV6ProviderFactories: clumio_pf.TestAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccConditionFunctions, baseUrl),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckOutput("tag_equals",
+						`{"aws_tag":{"$eq":{"key":"Environment","value":"Prod"}}}`),
+					resource.TestCheckOutput("entity_type_in",
+						`{"entity_type":{"$in":["aws_ebs_volume","aws_ec2_instance"]}}`),
+					resource.TestCheckOutput("account_in",
+						`{"aws_account_native_id":{"$in":["123456789012"]}}`),
+					resource.TestCheckOutput("any_of",
+						`{"aws_tag":{"$in":[{"key":"Environment","value":"Prod"},`+
+							`{"key":"Environment","value":"Staging"}]}}`),
+					resource.TestCheckOutput("all_of",
+						`{"aws_tag":{"$eq":{"key":"Environment","value":"Prod"}},`+
+							`"entity_type":{"$in":["aws_ebs_volume"]}}`),
+				),
+			},
+			{
+				Config:      fmt.Sprintf(testAccConditionFunctionsInvalidAccount, baseUrl),
+				ExpectError: regexp.MustCompile(`is not\s+an AWS account ID of 12 digits`),
+			},
+			{
+				Config:      fmt.Sprintf(testAccConditionFunctionsConflictingFields, baseUrl),
+				ExpectError: regexp.MustCompile(`must\s+all\s+filter\s+the\s+same\s+field`),
+			},
+		},
+	})
+}
+
+const testAccConditionFunctions = `
+terraform {
+  required_providers {
+    clumio = {
+      source = "hashicorp/clumio"
+    }
+  }
+}
+
+provider clumio{
+   clumio_api_base_url = "%s"
+}
+
+output "tag_equals" {
+  value = provider::clumio::tag_equals("Environment", "Prod")
+}
+
+output "entity_type_in" {
+  value = provider::clumio::entity_type_in(["aws_ebs_volume", "aws_ec2_instance"])
+}
+
+output "account_in" {
+  value = provider::clumio::account_in(["123456789012"])
+}
+
+output "any_of" {
+  value = provider::clumio::any_of(
+    provider::clumio::tag_equals("Environment", "Prod"),
+    "{\"aws_tag\":{\"$eq\":{\"value\":\"Staging\", \"key\":\"Environment\"}}}",
+  )
+}
+
+output "all_of" {
+  value = provider::clumio::all_of(
+    provider::clumio::entity_type_in(["aws_ebs_volume"]),
+    provider::clumio::tag_equals("Environment", "Prod"),
+  )
+}
+`
+
+const testAccConditionFunctionsInvalidAccount = `
+terraform {
+  required_providers {
+    clumio = {
+      source = "hashicorp/clumio"
+    }
+  }
+}
+
+provider clumio{
+   clumio_api_base_url = "%s"
+}
+
+output "account_in" {
+  value = provider::clumio::account_in(["1234"])
+}
+`
+
+const testAccConditionFunctionsConflictingFields = `
+terraform {
+  required_providers {
+    clumio = {
+      source = "hashicorp/clumio"
+    }
+  }
+}
+
+provider clumio{
+   clumio_api_base_url = "%s"
+}
+
+output "any_of" {
+  value = provider::clumio::any_of(
+    provider::clumio::tag_equals("Environment", "Prod"),
+    provider::clumio::entity_type_in(["aws_ebs_volume"]),
+  )
+}
+`
@@ -4,6 +4,7 @@ package clumio_organizational_unit
 
 const (
 	schemaId                        = "id"
+	schemaTimeouts                  = "timeouts"
 	schemaName                      = "name"
 	schemaDescription               = "description"
 	schemaParentId                  = "parent_id"
@@ -18,6 +19,5 @@ const (
 	http200                         = 200
 	http202                         = 202
 	errorFmt                        = "Error: %v"
-	pollTimeoutInSec                = 3600
 	pollIntervalInSec               = 5
 )
@@ -7,12 +7,12 @@ package clumio_organizational_unit
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	orgUnits "github.com/clumio-code/clumio-go-sdk/controllers/organizational_units"
 	"github.com/clumio-code/clumio-go-sdk/models"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -22,6 +22,9 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
+// apiFieldPaths maps the fields of the API requests to the attributes of the resource.
+var apiFieldPaths = common.AttributePaths(schemaName, schemaDescription, schemaParentId)
+
 // Ensure the implementation satisfies the expected interfaces.
 var (
 	_ resource.Resource                = &clumioOrganizationalUnitResource{}
@@ -46,16 +49,17 @@ type userWithRole struct {
 
 // clumioOrganizationalUnitResource model
 type clumioOrganizationalUnitResourceModel struct {
-	Id                        types.String `tfsdk:"id"`
-	Name                      types.String `tfsdk:"name"`
-	Description               types.String `tfsdk:"description"`
-	ParentId                  types.String `tfsdk:"parent_id"`
-	ChildrenCount             types.Int64  `tfsdk:"children_count"`
-	ConfiguredDatasourceTypes types.List   `tfsdk:"configured_datasource_types"`
-	DescendantIds             types.List   `tfsdk:"descendant_ids"`
-	UserCount                 types.Int64  `tfsdk:"user_count"`
-	Users                     types.List   `tfsdk:"users"`
-	UsersWithRole             types.List   `tfsdk:"users_with_role"`
+	Id                        types.String   `tfsdk:"id"`
+	Name                      types.String   `tfsdk:"name"`
+	Description               types.String   `tfsdk:"description"`
+	ParentId                  types.String   `tfsdk:"parent_id"`
+	ChildrenCount             types.Int64    `tfsdk:"children_count"`
+	ConfiguredDatasourceTypes types.List     `tfsdk:"configured_datasource_types"`
+	DescendantIds             types.List     `tfsdk:"descendant_ids"`
+	UserCount                 types.Int64    `tfsdk:"user_count"`
+	Users                     types.List     `tfsdk:"users"`
+	UsersWithRole             types.List     `tfsdk:"users_with_role"`
+	Timeouts                  timeouts.Value `tfsdk:"timeouts"`
 }
 
 // Metadata returns the resource type name.
@@ -66,7 +70,7 @@ func (r *clumioOrganizationalUnitResource) Metadata(
 
 // Schema defines the schema for the resource.
 func (r *clumioOrganizationalUnitResource) Schema(
-	_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Resource for creating and managing Organizational Unit in Clumio.",
 		Attributes: map[string]schema.Attribute{
@@ -138,6 +142,13 @@ func (r *clumioOrganizationalUnitResource) Schema(
 				},
 			},
 		},
+		Blocks: map[string]schema.Block{
+			schemaTimeouts: timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Update: true,
+				Delete: true,
+			}),
+		},
 	}
 }
 
@@ -162,7 +173,15 @@ func (r *clumioOrganizationalUnitResource) Create(
 		return
 	}
 
-	orgUnitsAPI := orgUnits.NewOrganizationalUnitsV2(r.client.ClumioConfig)
+	createTimeout, diags := plan.Timeouts.Create(ctx, r.client.Timeouts().Create)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	orgUnitsAPI := orgUnits.NewOrganizationalUnitsV2(r.client.WithContext(ctx).ClumioConfig())
 	name := plan.Name.ValueString()
 	parentId := plan.ParentId.ValueString()
 	request := &models.CreateOrganizationalUnitV2Request{
@@ -174,11 +193,11 @@ func (r *clumioOrganizationalUnitResource) Create(
 		request.Description = &description
 	}
 
+	defer r.client.InvalidateLookups(common.LookupOrganizationalUnits)
 	res, apiErr := orgUnitsAPI.CreateOrganizationalUnit(nil, request)
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
-			"Error creating Clumio organizational unit.",
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			"Error creating Clumio organizational unit.", apiErr, apiFieldPaths)...)
 		return
 	}
 
@@ -269,15 +288,16 @@ func (r *clumioOrganizationalUnitResource) Read(
 		return
 	}
 
-	orgUnitsAPI := orgUnits.NewOrganizationalUnitsV2(r.client.ClumioConfig)
+	orgUnitsAPI := orgUnits.NewOrganizationalUnitsV2(r.client.WithContext(ctx).ClumioConfig())
 	res, apiErr := orgUnitsAPI.ReadOrganizationalUnit(state.Id.ValueString(), nil)
 	if apiErr != nil {
-		if strings.Contains(apiErr.Error(), "The resource is not found.") {
-			state.Id = types.StringValue("")
+		if common.NewAPIError(apiErr).IsNotFound() {
+			common.RemoveMissingResource(ctx, resp, fmt.Sprintf(
+				"Clumio organizational unit %q", state.Name.ValueString()))
+			return
 		}
-		resp.Diagnostics.AddError(
-			"Error retrieving Clumio organizational unit.",
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			"Error retrieving Clumio organizational unit.", apiErr, nil)...)
 		return
 	}
 
@@ -313,6 +333,9 @@ func (r *clumioOrganizationalUnitResource) Read(
 	resp.Diagnostics.Append(conversionDiags...)
 	state.DescendantIds = descendantIds
 
+	// Record the ETag sent as a precondition of the next update or deletion.
+	resp.Diagnostics.Append(common.SetPrivateETag(ctx, resp.Private, res.Etag)...)
+
 	// Set refreshed state
 	diags = resp.State.Set(ctx, state)
 	resp.Diagnostics.Append(diags...)
@@ -332,6 +355,14 @@ func (r *clumioOrganizationalUnitResource) Update(
 		return
 	}
 
+	updateTimeout, diags := plan.Timeouts.Update(ctx, r.client.Timeouts().Update)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
 	var state clumioOrganizationalUnitResourceModel
 	diags = req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -339,7 +370,13 @@ func (r *clumioOrganizationalUnitResource) Update(
 		return
 	}
 
-	orgUnitsAPI := orgUnits.NewOrganizationalUnitsV2(r.client.ClumioConfig)
+	etag, diags := common.GetPrivateETag(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	orgUnitsAPI := orgUnits.NewOrganizationalUnitsV2(r.client.WithIfMatch(etag).WithContext(ctx).ClumioConfig())
 	name := plan.Name.ValueString()
 	request := &models.PatchOrganizationalUnitV2Request{
 		Name: &name,
@@ -348,11 +385,12 @@ func (r *clumioOrganizationalUnitResource) Update(
 	if !plan.Description.IsNull() {
 		request.Description = &description
 	}
+	defer r.client.InvalidateLookups(common.LookupOrganizationalUnits)
 	res, apiErr := orgUnitsAPI.PatchOrganizationalUnit(plan.Id.ValueString(), nil, request)
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
 			fmt.Sprintf("Error updating Clumio organizational unit id: %v.", plan.Id.ValueString()),
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+			apiErr, nil)...)
 		return
 	}
 
@@ -423,6 +461,9 @@ func (r *clumioOrganizationalUnitResource) Update(
 	resp.Diagnostics.Append(conversionDiags...)
 	plan.DescendantIds = descendantIds
 
+	// The response does not carry the new ETag, which the next refresh records.
+	resp.Diagnostics.Append(common.SetPrivateETag(ctx, resp.Private, nil)...)
+
 	// Set state to fully populated data.
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
@@ -443,19 +484,36 @@ func (r *clumioOrganizationalUnitResource) Delete(
 		return
 	}
 
-	orgUnitsAPI := orgUnits.NewOrganizationalUnitsV2(r.client.ClumioConfig)
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, r.client.Timeouts().Delete)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	etag, diags := common.GetPrivateETag(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	orgUnitsAPI := orgUnits.NewOrganizationalUnitsV2(r.client.WithIfMatch(etag).WithContext(ctx).ClumioConfig())
+	defer r.client.InvalidateLookups(common.LookupOrganizationalUnits)
 	res, apiErr := orgUnitsAPI.DeleteOrganizationalUnit(state.Id.ValueString(), nil)
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
 			fmt.Sprintf(
 				"Error deleting Clumio organizational unit %v.", state.Id.ValueString()),
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+			apiErr, nil)...)
+		return
 	}
-	err := common.PollTask(ctx, r.client, *res.TaskId, pollTimeoutInSec, pollIntervalInSec)
+	err := common.PollTask(ctx, r.client, *res.TaskId, pollIntervalInSec)
 	if err != nil {
 		resp.Diagnostics.AddError(
-			fmt.Sprintf("Error while polling for Delete OU Task"),
-			fmt.Sprintf(errorFmt, string(err.Error())))
+			fmt.Sprintf(
+				"Error deleting Clumio organizational unit %v.", state.Id.ValueString()),
+			fmt.Sprintf(errorFmt, err))
 	}
 }
 
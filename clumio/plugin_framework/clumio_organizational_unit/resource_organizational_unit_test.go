@@ -5,14 +5,18 @@
 package clumio_organizational_unit_test
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"regexp"
 	"testing"
 
+	orgUnits "github.com/clumio-code/clumio-go-sdk/controllers/organizational_units"
 	clumio_pf "github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework"
 
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
+	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/mock_api"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
@@ -48,10 +52,63 @@ func TestAccResourceClumioOrganizationalUnit(t *testing.T) {
 						regexp.MustCompile(descNameAfter)),
 				),
 			},
+			{
+				ResourceName:      "clumio_organizational_unit.test_ou",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: getTestAccResourceClumioOrganizationalUnit(baseUrl, true),
+				Check: clumio_pf.UtilTestCheckResourceDisappears(
+					"clumio_organizational_unit.test_ou", testAccDeleteOrganizationalUnit),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+// TestAccResourceClumioOrganizationalUnitPreconditionFailed verifies that an update rejected
+// because the organizational unit no longer has the ETag recorded by the last refresh is
+// reported as a change made outside Terraform.
+func TestAccResourceClumioOrganizationalUnitPreconditionFailed(t *testing.T) {
+	baseUrl := os.Getenv(common.ClumioApiBaseUrl)
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			clumio_pf.UtilTestAccPreCheckMockApi(t)
+			clumio_pf.UtilTestAccPreCheckClumio(t)
+		},
+		ProtoV6ProviderFactories: clumio_pf.TestAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: getTestAccResourceClumioOrganizationalUnit(baseUrl, false),
+			},
+			{
+				PreConfig: func() {
+					clumio_pf.TestAccMockApiServer.InjectFault(mock_api.Fault{
+						Method:     http.MethodPatch,
+						Path:       "/organizational-units/{id}",
+						StatusCode: http.StatusPreconditionFailed,
+						Times:      1,
+					})
+				},
+				Config:      getTestAccResourceClumioOrganizationalUnit(baseUrl, true),
+				ExpectError: regexp.MustCompile("changed outside Terraform"),
+			},
 		},
 	})
 }
 
+// testAccDeleteOrganizationalUnit deletes the organizational unit outside of Terraform and
+// waits for the deletion task to complete.
+func testAccDeleteOrganizationalUnit(ctx context.Context, client *common.ApiClient, id string) error {
+	orgUnitsAPI := orgUnits.NewOrganizationalUnitsV2(client.ClumioConfig())
+	res, apiErr := orgUnitsAPI.DeleteOrganizationalUnit(id, nil)
+	if apiErr != nil {
+		return common.NewAPIError(apiErr)
+	}
+	return common.PollTask(ctx, client, *res.TaskId, clumio_pf.TestAccTaskIntervalInSec)
+}
+
 func getTestAccResourceClumioOrganizationalUnit(baseUrl string, update bool) string {
 	content :=
 		`name = "acceptance-test-ou"`
@@ -20,6 +20,12 @@ const (
 	schemaValue                  = "value"
 	schemaOffsets                = "offsets"
 	schemaId                     = "id"
+	schemaTimeouts               = "timeouts"
+	schemaWaitForCompletion      = "wait_for_completion"
+	schemaLastTaskId             = "last_task_id"
+	schemaFailOnTaskError        = "fail_on_task_error"
+	schemaSkipSlaValidation      = "skip_sla_validation"
+	schemaWaitForUnlock          = "wait_for_unlock"
 	schemaLockStatus             = "lock_status"
 	schemaAdvancedSettings       = "advanced_settings"
 	schemaAlternativeReplica     = "alternative_replica"
@@ -36,6 +42,64 @@ const (
 	schemaRDSPitrConfigSync      = "aws_rds_config_sync"
 	schemaApply                  = "apply"
 	schemaRdsLogicalBackup       = "aws_rds_resource_granular_backup"
+	schemaNameRegex              = "name_regex"
+	schemaOperationTypeFilter    = "operation_type"
+	schemaIds                    = "ids"
+	schemaPolicies               = "policies"
+	schemaOperationTypes         = "operation_types"
+
+	// Values of the action_setting of the operations.
+	actionSettingImmediate = "immediate"
+	actionSettingWindow    = "window"
+
+	// Values of the type of the operations.
+	operationProtectionGroupBackup     = "protection_group_backup"
+	operationProtectionGroupContinuous = "protection_group_continuous_backup"
+	operationEBSVolumeBackup           = "aws_ebs_volume_backup"
+	operationEBSVolumeSnapshot         = "aws_ebs_volume_snapshot"
+	operationEC2InstanceBackup         = "aws_ec2_instance_backup"
+	operationEC2InstanceSnapshot       = "aws_ec2_instance_snapshot"
+	operationRDSAwsSnapshot            = "aws_rds_resource_aws_snapshot"
+	operationRDSRollingBackup          = "aws_rds_resource_rolling_backup"
+	operationRDSGranularBackup         = "aws_rds_resource_granular_backup"
+	operationDynamoDBTableSnapshot     = "aws_dynamodb_table_snapshot"
+	operationDynamoDBTableBackup       = "aws_dynamodb_table_backup"
+	operationEC2MssqlDatabaseBackup    = "ec2_mssql_database_backup"
+	operationEC2MssqlLogBackup         = "ec2_mssql_log_backup"
+	operationMssqlDatabaseBackup       = "mssql_database_backup"
+	operationMssqlLogBackup            = "mssql_log_backup"
+
+	// Values of the unit of the SLA parameters.
+	unitMinutes  = "minutes"
+	unitHours    = "hours"
+	unitDays     = "days"
+	unitWeeks    = "weeks"
+	unitMonths   = "months"
+	unitYears    = "years"
+	unitOnDemand = "on_demand"
+
+	// Values of the backup_tier of the advanced settings.
+	backupTierStandard = "standard"
+	backupTierLite     = "lite"
+	backupTierCold     = "cold"
+	backupTierFrozen   = "frozen"
+
+	// Values of the replicas of the MSSQL advanced settings.
+	replicaPrimary       = "primary"
+	replicaSyncSecondary = "sync_secondary"
+	replicaStop          = "stop"
+
+	// Values of the activation_status of a policy.
+	activationStatusActivated   = "activated"
+	activationStatusDeactivated = "deactivated"
+
+	// Value of the lock_status of a policy which can be updated or deleted. The policy is
+	// locked, with a lock_status such as updating or deleting, while a task is in progress.
+	lockStatusUnlocked = "unlocked"
+
+	// Values of the apply of the aws_rds_config_sync advanced settings.
+	applyImmediate         = "immediate"
+	applyMaintenanceWindow = "maintenance_window"
 
 	alternativeReplicaDescFmt = "The alternative replica for MSSQL %s backups. This" +
 		" setting only applies to Availability Group databases. Possible" +
@@ -80,9 +144,14 @@ const (
 	rdsLogicalBackupAdvancedSettingDesc = "Backup tier to store the RDS backup in." +
 		" Valid values are: `standard` and `frozen`. If not provided, the default is `standard`."
 
+	invalidSlaSummary = "Invalid SLA"
+	slaOptOutDetail   = " Set skip_sla_validation to true if the Clumio API accepts the SLA."
+
+	policyLockedSummary = "Clumio policy is locked"
+
 	errorFmt           = "Error: %v"
 	errorPolicyReadMsg = "Error retrieving Clumio Policy."
+	errorPolicyListMsg = "Error listing Clumio Policies."
 
-	timeoutInSec  = 3600
 	intervalInSec = 5
 )
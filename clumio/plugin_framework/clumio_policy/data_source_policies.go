@@ -0,0 +1,311 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the clumio_policies data source, which lists the policies matching a set of filters.
+
+package clumio_policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+
+	apiutils "github.com/clumio-code/clumio-go-sdk/api_utils"
+	policyDefinitions "github.com/clumio-code/clumio-go-sdk/controllers/policy_definitions"
+	"github.com/clumio-code/clumio-go-sdk/models"
+	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &policiesDataSource{}
+	_ datasource.DataSourceWithConfigure = &policiesDataSource{}
+)
+
+// NewPoliciesDataSource is a helper function to simplify the provider implementation.
+func NewPoliciesDataSource() datasource.DataSource {
+	return &policiesDataSource{}
+}
+
+// policiesDataSource is the data source implementation.
+type policiesDataSource struct {
+	client *common.ApiClient
+}
+
+// policySummaryModel maps a policy listed by the clumio_policies data source.
+type policySummaryModel struct {
+	ID                   types.String `tfsdk:"id"`
+	Name                 types.String `tfsdk:"name"`
+	OrganizationalUnitId types.String `tfsdk:"organizational_unit_id"`
+	LockStatus           types.String `tfsdk:"lock_status"`
+	Timezone             types.String `tfsdk:"timezone"`
+	ActivationStatus     types.String `tfsdk:"activation_status"`
+	OperationTypes       []string     `tfsdk:"operation_types"`
+}
+
+// policiesDataSourceModel maps the clumio_policies data source schema.
+type policiesDataSourceModel struct {
+	ID                   types.String          `tfsdk:"id"`
+	NameRegex            types.String          `tfsdk:"name_regex"`
+	OperationType        types.String          `tfsdk:"operation_type"`
+	ActivationStatus     types.String          `tfsdk:"activation_status"`
+	OrganizationalUnitId types.String          `tfsdk:"organizational_unit_id"`
+	IDs                  []string              `tfsdk:"ids"`
+	Policies             []*policySummaryModel `tfsdk:"policies"`
+}
+
+// Metadata returns the data source type name.
+func (d *policiesDataSource) Metadata(
+	_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_policies"
+}
+
+// Schema defines the schema for the data source.
+func (d *policiesDataSource) Schema(
+	_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+
+	resp.Schema = schema.Schema{
+		Description: "Clumio Policies Data Source used to list the policies matching all of" +
+			" the given filters, for instance to pick a policy by a naming convention.",
+		Attributes: map[string]schema.Attribute{
+			schemaId: schema.StringAttribute{
+				Description: "Combination of the name_regex, operation_type," +
+					" activation_status and organizational_unit_id filters.",
+				Computed: true,
+			},
+			schemaNameRegex: schema.StringAttribute{
+				Description: "A regular expression, in RE2 syntax, that the names of the" +
+					" policies must match.",
+				Optional: true,
+				Validators: []validator.String{
+					regexpValidator{},
+				},
+			},
+			schemaOperationTypeFilter: schema.StringAttribute{
+				Description: "The type of an operation, such as aws_ebs_volume_backup, that the" +
+					" policies must have.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(operationTypes...),
+				},
+			},
+			schemaActivationStatus: schema.StringAttribute{
+				Description: "The status the policies must have, either activated or" +
+					" deactivated.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(activationStatusActivated,
+						activationStatusDeactivated),
+				},
+			},
+			schemaOrganizationalUnitId: schema.StringAttribute{
+				Description: "The Clumio-assigned ID of the organizational unit the policies" +
+					" must belong to.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			schemaIds: schema.ListAttribute{
+				Description: "The Clumio-assigned IDs of the matching policies, in the order" +
+					" of the policies attribute.",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+			schemaPolicies: schema.ListNestedAttribute{
+				Description: "The matching policies, sorted by name.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						schemaId: schema.StringAttribute{
+							Description: "The Clumio-assigned ID of the policy.",
+							Computed:    true,
+						},
+						schemaName: schema.StringAttribute{
+							Description: "The name of the policy.",
+							Computed:    true,
+						},
+						schemaOrganizationalUnitId: schema.StringAttribute{
+							Description: "The Clumio-assigned ID of the organizational unit" +
+								" of the policy.",
+							Computed: true,
+						},
+						schemaLockStatus: schema.StringAttribute{
+							Description: "The lock status of the policy, such as unlocked or" +
+								" updating.",
+							Computed: true,
+						},
+						schemaTimezone: schema.StringAttribute{
+							Description: "The time zone of the policy, in IANA format.",
+							Computed:    true,
+						},
+						schemaActivationStatus: schema.StringAttribute{
+							Description: "The status of the policy, either activated or" +
+								" deactivated.",
+							Computed: true,
+						},
+						schemaOperationTypes: schema.ListAttribute{
+							Description: "The types of the operations of the policy, sorted." +
+								" Use the clumio_policy data source to read the operations.",
+							ElementType: types.StringType,
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *policiesDataSource) Configure(
+	_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+
+	if req.ProviderData == nil {
+		return
+	}
+
+	d.client = req.ProviderData.(*common.ApiClient)
+}
+
+// Read lists the matching policies and sets them in the Terraform state.
+func (d *policiesDataSource) Read(
+	ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+
+	var state policiesDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The regular expression was validated with the configuration.
+	var nameRegex *regexp.Regexp
+	if !state.NameRegex.IsNull() {
+		nameRegex = regexp.MustCompile(state.NameRegex.ValueString())
+	}
+
+	pd := policyDefinitions.NewPolicyDefinitionsV1(d.client.WithContext(ctx).ClumioConfig())
+	policies, apiErr := listPolicies(ctx, d.client, pd, &policyFilter{
+		activationStatus:     state.ActivationStatus.ValueString(),
+		organizationalUnitId: state.OrganizationalUnitId.ValueString(),
+	})
+	if apiErr != nil {
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			errorPolicyListMsg, apiErr, nil)...)
+		return
+	}
+
+	state.ID = types.StringValue(fmt.Sprintf("%v_%v_%v_%v", state.NameRegex.ValueString(),
+		state.OperationType.ValueString(), state.ActivationStatus.ValueString(),
+		state.OrganizationalUnitId.ValueString()))
+	state.IDs = []string{}
+	state.Policies = []*policySummaryModel{}
+	for _, policy := range policies {
+		if nameRegex != nil && !nameRegex.MatchString(stringValue(policy.Name)) {
+			continue
+		}
+		summary := &policySummaryModel{
+			ID:                   types.StringValue(stringValue(policy.Id)),
+			Name:                 types.StringValue(stringValue(policy.Name)),
+			OrganizationalUnitId: types.StringValue(stringValue(policy.OrganizationalUnitId)),
+			LockStatus:           types.StringValue(stringValue(policy.LockStatus)),
+			Timezone:             types.StringValue(stringValue(policy.Timezone)),
+			ActivationStatus:     types.StringValue(stringValue(policy.ActivationStatus)),
+			OperationTypes:       []string{},
+		}
+		hasOperationType := state.OperationType.IsNull()
+		for _, operation := range policy.Operations {
+			operationType := stringValue(operation.ClumioType)
+			summary.OperationTypes = append(summary.OperationTypes, operationType)
+			if operationType == state.OperationType.ValueString() {
+				hasOperationType = true
+			}
+		}
+		if !hasOperationType {
+			continue
+		}
+		sort.Strings(summary.OperationTypes)
+		state.Policies = append(state.Policies, summary)
+	}
+	sort.Slice(state.Policies, func(i, j int) bool {
+		left, right := state.Policies[i], state.Policies[j]
+		if left.Name.ValueString() != right.Name.ValueString() {
+			return left.Name.ValueString() < right.Name.ValueString()
+		}
+		return left.ID.ValueString() < right.ID.ValueString()
+	})
+	for _, summary := range state.Policies {
+		state.IDs = append(state.IDs, summary.ID.ValueString())
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// policyFilter holds the filters of a listing of policies that the Clumio API applies. The
+// empty fields do not filter the policies.
+type policyFilter struct {
+	// name matches the policies whose name contains it.
+	name                 string
+	activationStatus     string
+	organizationalUnitId string
+}
+
+// listPolicies returns the policies matching the filter. The listings are cached with the
+// other policy lookups, keyed by their filter.
+func listPolicies(ctx context.Context, client *common.ApiClient,
+	pd policyDefinitions.PolicyDefinitionsV1Client, filter *policyFilter) (
+	[]*models.Policy, *apiutils.APIError) {
+
+	filterMap := map[string]any{}
+	if filter.name != "" {
+		filterMap[schemaName] = map[string]string{"$contains": filter.name}
+	}
+	if filter.activationStatus != "" {
+		filterMap[schemaActivationStatus] = map[string]string{"$eq": filter.activationStatus}
+	}
+	if filter.organizationalUnitId != "" {
+		filterMap[schemaOrganizationalUnitId] = map[string][]string{
+			"$in": {filter.organizationalUnitId},
+		}
+	}
+	var filterStr *string
+	if len(filterMap) > 0 {
+		// A map of strings is always encoded.
+		encoded, _ := json.Marshal(filterMap)
+		filterStr = new(string)
+		*filterStr = string(encoded)
+	}
+
+	// The key of a listing cannot be mistaken for the ID of a policy.
+	key := "list"
+	if filterStr != nil {
+		key = fmt.Sprintf("list %s", *filterStr)
+	}
+	res, apiErr := common.Lookup(ctx, client, common.LookupPolicies, key,
+		func() (*models.ListPoliciesResponse, *apiutils.APIError) {
+			return pd.ListPolicyDefinitions(filterStr, nil)
+		})
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if res.Embedded == nil {
+		return nil, nil
+	}
+	return res.Embedded.Items, nil
+}
+
+// stringValue returns the value of the string returned by the Clumio API, which is empty if
+// the string is missing.
+func stringValue(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
@@ -0,0 +1,336 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the clumio_policy data source, which looks up a policy by ID or exact name.
+
+package clumio_policy
+
+import (
+	"context"
+	"fmt"
+
+	apiutils "github.com/clumio-code/clumio-go-sdk/api_utils"
+	policyDefinitions "github.com/clumio-code/clumio-go-sdk/controllers/policy_definitions"
+	"github.com/clumio-code/clumio-go-sdk/models"
+	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &policyDataSource{}
+	_ datasource.DataSourceWithConfigure = &policyDataSource{}
+)
+
+// NewPolicyDataSource is a helper function to simplify the provider implementation.
+func NewPolicyDataSource() datasource.DataSource {
+	return &policyDataSource{}
+}
+
+// policyDataSource is the data source implementation.
+type policyDataSource struct {
+	client *common.ApiClient
+}
+
+// policyDataSourceModel maps the clumio_policy data source schema.
+type policyDataSourceModel struct {
+	ID                   types.String                     `tfsdk:"id"`
+	Name                 types.String                     `tfsdk:"name"`
+	OrganizationalUnitId types.String                     `tfsdk:"organizational_unit_id"`
+	LockStatus           types.String                     `tfsdk:"lock_status"`
+	Timezone             types.String                     `tfsdk:"timezone"`
+	ActivationStatus     types.String                     `tfsdk:"activation_status"`
+	Operations           map[string]*policyOperationModel `tfsdk:"operations"`
+}
+
+// Metadata returns the data source type name.
+func (d *policyDataSource) Metadata(
+	_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_policy"
+}
+
+// Schema defines the schema for the data source.
+func (d *policyDataSource) Schema(
+	_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+
+	resp.Schema = schema.Schema{
+		Description: "Clumio Policy Data Source used to look up a policy by ID or by exact" +
+			" name, such as a policy managed centrally in another workspace.",
+		Attributes: map[string]schema.Attribute{
+			schemaId: schema.StringAttribute{
+				Description: "The Clumio-assigned ID of the policy. Exactly one of id and" +
+					" name must be set.",
+				Optional: true,
+				Computed: true,
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot(schemaName)),
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			schemaName: schema.StringAttribute{
+				Description: "The exact name of the policy. Exactly one of id and name must" +
+					" be set.",
+				Optional: true,
+				Computed: true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			schemaOrganizationalUnitId: schema.StringAttribute{
+				Description: "The Clumio-assigned ID of the organizational unit of the" +
+					" policy. When set, only the policies of this organizational unit are" +
+					" looked up.",
+				Optional: true,
+				Computed: true,
+			},
+			schemaLockStatus: schema.StringAttribute{
+				Description: "The lock status of the policy, such as unlocked or updating.",
+				Computed:    true,
+			},
+			schemaTimezone: schema.StringAttribute{
+				Description: "The time zone of the policy, in IANA format.",
+				Computed:    true,
+			},
+			schemaActivationStatus: schema.StringAttribute{
+				Description: "The status of the policy, either activated or deactivated.",
+				Computed:    true,
+			},
+			schemaOperations: operationsDataSourceAttribute(),
+		},
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *policyDataSource) Configure(
+	_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+
+	if req.ProviderData == nil {
+		return
+	}
+
+	d.client = req.ProviderData.(*common.ApiClient)
+}
+
+// Read looks up the policy and sets its attributes in the Terraform state.
+func (d *policyDataSource) Read(
+	ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+
+	var state policyDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	pd := policyDefinitions.NewPolicyDefinitionsV1(d.client.WithContext(ctx).ClumioConfig())
+	orgUnitId := state.OrganizationalUnitId.ValueString()
+	var policy *models.Policy
+	if id := state.ID.ValueString(); id != "" {
+		res, apiErr := common.Lookup(ctx, d.client, common.LookupPolicies, id,
+			func() (*models.ReadPolicyResponse, *apiutils.APIError) {
+				return pd.ReadPolicyDefinition(id, nil)
+			})
+		if apiErr != nil {
+			resp.Diagnostics.Append(common.APIErrorDiagnostics(
+				errorPolicyReadMsg, apiErr, nil)...)
+			return
+		}
+		if orgUnitId != "" && stringValue(res.OrganizationalUnitId) != orgUnitId {
+			resp.Diagnostics.AddError("Clumio policy not found.",
+				fmt.Sprintf("The policy %s does not belong to the organizational unit %s.",
+					id, orgUnitId))
+			return
+		}
+		policy = &models.Policy{
+			Id:                   res.Id,
+			Name:                 res.Name,
+			LockStatus:           res.LockStatus,
+			Timezone:             res.Timezone,
+			ActivationStatus:     res.ActivationStatus,
+			OrganizationalUnitId: res.OrganizationalUnitId,
+			Operations:           res.Operations,
+		}
+	} else {
+		name := state.Name.ValueString()
+		policies, apiErr := listPolicies(ctx, d.client, pd, &policyFilter{
+			name:                 name,
+			organizationalUnitId: orgUnitId,
+		})
+		if apiErr != nil {
+			resp.Diagnostics.Append(common.APIErrorDiagnostics(
+				errorPolicyListMsg, apiErr, nil)...)
+			return
+		}
+		var matches []*models.Policy
+		for _, item := range policies {
+			if stringValue(item.Name) == name {
+				matches = append(matches, item)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			resp.Diagnostics.AddError("Clumio policy not found.",
+				fmt.Sprintf("No policy is named %q.", name))
+			return
+		case 1:
+			policy = matches[0]
+		default:
+			resp.Diagnostics.AddError("More than one Clumio policy found.",
+				fmt.Sprintf("%d policies are named %q. Set organizational_unit_id or id to"+
+					" select one of them.", len(matches), name))
+			return
+		}
+	}
+
+	state.ID = types.StringValue(stringValue(policy.Id))
+	state.Name = types.StringValue(stringValue(policy.Name))
+	state.OrganizationalUnitId = types.StringValue(stringValue(policy.OrganizationalUnitId))
+	state.LockStatus = types.StringValue(stringValue(policy.LockStatus))
+	state.Timezone = types.StringValue(stringValue(policy.Timezone))
+	state.ActivationStatus = types.StringValue(stringValue(policy.ActivationStatus))
+	operations, diags := mapClumioOperationsToSchemaOperations(ctx, policy.Operations)
+	resp.Diagnostics.Append(diags...)
+	state.Operations = make(map[string]*policyOperationModel, len(operations))
+	for _, operation := range operations {
+		state.Operations[operation.OperationType.ValueString()] = operation
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// operationsDataSourceAttribute returns the computed operations of the policy data sources,
+// which have the same attributes as the operations of the clumio_policy resource.
+func operationsDataSourceAttribute() schema.MapNestedAttribute {
+	unitValueAttributes := map[string]schema.Attribute{
+		schemaUnit: schema.StringAttribute{
+			Description: "The measurement unit of the SLA parameter.",
+			Computed:    true,
+		},
+		schemaValue: schema.Int64Attribute{
+			Description: "The measurement value of the SLA parameter.",
+			Computed:    true,
+		},
+	}
+	rpoAttributes := map[string]schema.Attribute{
+		schemaUnit:  unitValueAttributes[schemaUnit],
+		schemaValue: unitValueAttributes[schemaValue],
+		schemaOffsets: schema.ListAttribute{
+			Description: "The offset values of the SLA parameter.",
+			ElementType: types.Int64Type,
+			Computed:    true,
+		},
+	}
+	replicaAttribute := func(description string) schema.SingleNestedAttribute {
+		return schema.SingleNestedAttribute{
+			Description: description,
+			Computed:    true,
+			Attributes: map[string]schema.Attribute{
+				schemaAlternativeReplica: schema.StringAttribute{
+					Description: "The alternative replica for MSSQL backups.",
+					Computed:    true,
+				},
+				schemaPreferredReplica: schema.StringAttribute{
+					Description: "The primary preferred replica for MSSQL backups.",
+					Computed:    true,
+				},
+			},
+		}
+	}
+	backupTierAttribute := func(description string) schema.SingleNestedAttribute {
+		return schema.SingleNestedAttribute{
+			Description: description,
+			Computed:    true,
+			Attributes: map[string]schema.Attribute{
+				schemaBackupTier: schema.StringAttribute{
+					Description: "The backup tier to store the backup in.",
+					Computed:    true,
+				},
+			},
+		}
+	}
+
+	return schema.MapNestedAttribute{
+		Description: "The operations of the policy, keyed by their type.",
+		Computed:    true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				schemaActionSetting: schema.StringAttribute{
+					Description: "Whether the policy takes action immediately or during the" +
+						" backup window.",
+					Computed: true,
+				},
+				schemaOperationType: schema.StringAttribute{
+					Description: "The type of the operation.",
+					Computed:    true,
+				},
+				schemaBackupWindowTz: schema.SingleNestedAttribute{
+					Description: "The start and end times of the backup window, in the time" +
+						" zone of the policy.",
+					Computed: true,
+					Attributes: map[string]schema.Attribute{
+						schemaStartTime: schema.StringAttribute{
+							Description: "The time when the backup window opens.",
+							Computed:    true,
+						},
+						schemaEndTime: schema.StringAttribute{
+							Description: "The time when the backup window closes.",
+							Computed:    true,
+						},
+					},
+				},
+				schemaSlas: schema.SetNestedAttribute{
+					Description: "The service level agreements (SLA) of the operation.",
+					Computed:    true,
+					NestedObject: schema.NestedAttributeObject{
+						Attributes: map[string]schema.Attribute{
+							schemaRetentionDuration: schema.SingleNestedAttribute{
+								Description: "The retention time of the backups.",
+								Computed:    true,
+								Attributes:  unitValueAttributes,
+							},
+							schemaRpoFrequency: schema.SingleNestedAttribute{
+								Description: "The minimum frequency between backups.",
+								Computed:    true,
+								Attributes:  rpoAttributes,
+							},
+						},
+					},
+				},
+				schemaAdvancedSettings: schema.SingleNestedAttribute{
+					Description: "Additional operation-specific policy settings.",
+					Computed:    true,
+					Attributes: map[string]schema.Attribute{
+						schemaEc2MssqlDatabaseBackup: replicaAttribute(mssqlDatabaseBackupDesc),
+						schemaEc2MssqlLogBackup:      replicaAttribute(mssqlLogBackupDesc),
+						schemaMssqlDatabaseBackup:    replicaAttribute(mssqlDatabaseBackupDesc),
+						schemaMssqlLogBackup:         replicaAttribute(mssqlLogBackupDesc),
+						schemaProtectionGroupBackup: backupTierAttribute(
+							"Settings of the protection_group_backup operation."),
+						schemaEBSVolumeBackup:   backupTierAttribute(ebsBackupDesc),
+						schemaEC2InstanceBackup: backupTierAttribute(ec2BackupDesc),
+						schemaRDSPitrConfigSync: schema.SingleNestedAttribute{
+							Description: rdsPitrConfigSyncDesc,
+							Computed:    true,
+							Attributes: map[string]schema.Attribute{
+								schemaApply: schema.StringAttribute{
+									Description: "When the configuration of PITR is synced," +
+										" either immediate or maintenance_window.",
+									Computed: true,
+								},
+							},
+						},
+						schemaRdsLogicalBackup: backupTierAttribute(rdsLogicalBackupDesc),
+					},
+				},
+				schemaBackupAwsRegion: schema.StringAttribute{
+					Description: "The region in which the backups are stored.",
+					Computed:    true,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright 2024. Clumio, Inc.
+
+// Acceptance tests for the clumio_policy and clumio_policies data sources.
+package clumio_policy_test
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	clumio_pf "github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework"
+	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceClumioPolicy(t *testing.T) {
+	ebs := "operations.aws_ebs_volume_backup"
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { clumio_pf.UtilTestAccPreCheckClumio(t) },
+		ProtoV6ProviderFactories: clumio_pf.TestAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: getTestAccDataSourceClumioPolicy(""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(
+						"data.clumio_policy.by_name", "id", "clumio_policy.test_policy_a", "id"),
+					resource.TestCheckResourceAttrPair(
+						"data.clumio_policy.by_id", "name",
+						"clumio_policy.test_policy_b", "name"),
+					resource.TestCheckResourceAttr(
+						"data.clumio_policy.by_name", "timezone", "UTC"),
+					resource.TestCheckResourceAttr(
+						"data.clumio_policy.by_name", "activation_status", "activated"),
+					resource.TestCheckResourceAttr(
+						"data.clumio_policy.by_name", "operations.%", "1"),
+					resource.TestCheckResourceAttr(
+						"data.clumio_policy.by_name", ebs+".type", "aws_ebs_volume_backup"),
+					resource.TestCheckResourceAttr(
+						"data.clumio_policy.by_name", ebs+".action_setting", "window"),
+					resource.TestCheckResourceAttr(
+						"data.clumio_policy.by_name", ebs+".backup_window_tz.start_time",
+						"01:00"),
+					resource.TestCheckResourceAttr(
+						"data.clumio_policy.by_name",
+						ebs+".advanced_settings.aws_ebs_volume_backup.backup_tier", "lite"),
+					resource.TestCheckResourceAttr(
+						"data.clumio_policy.by_name", ebs+".slas.#", "1"),
+					resource.TestCheckTypeSetElemNestedAttrs(
+						"data.clumio_policy.by_name", ebs+".slas.*", map[string]string{
+							"retention_duration.unit":  "days",
+							"retention_duration.value": "30",
+							"rpo_frequency.unit":       "days",
+							"rpo_frequency.value":      "1",
+						}),
+					resource.TestCheckResourceAttr(
+						"data.clumio_policy.by_id", "operations.%", "2"),
+					resource.TestCheckResourceAttr(
+						"data.clumio_policies.all", "ids.#", "2"),
+					resource.TestCheckResourceAttrPair(
+						"data.clumio_policies.all", "ids.0", "clumio_policy.test_policy_a", "id"),
+					resource.TestCheckResourceAttrPair(
+						"data.clumio_policies.all", "ids.1", "clumio_policy.test_policy_b", "id"),
+					resource.TestCheckResourceAttr(
+						"data.clumio_policies.all", "policies.0.operation_types.#", "1"),
+					resource.TestCheckResourceAttr(
+						"data.clumio_policies.ec2", "policies.#", "1"),
+					resource.TestCheckResourceAttrPair(
+						"data.clumio_policies.ec2", "policies.0.id",
+						"clumio_policy.test_policy_b", "id"),
+					resource.TestCheckResourceAttr(
+						"data.clumio_policies.ec2", "policies.0.operation_types.0",
+						"aws_ebs_volume_backup"),
+					resource.TestCheckResourceAttr(
+						"data.clumio_policies.ec2", "policies.0.operation_types.1",
+						"aws_ec2_instance_backup"),
+					resource.TestCheckResourceAttr(
+						"data.clumio_policies.deactivated", "ids.#", "0"),
+				),
+			},
+			{
+				Config: getTestAccDataSourceClumioPolicy(`
+data "clumio_policy" "missing" {
+	name = "${clumio_policy.test_policy_a.name}-missing"
+}`),
+				ExpectError: regexp.MustCompile("No policy is named"),
+			},
+			{
+				Config: getTestAccDataSourceClumioPolicy(`
+data "clumio_policy" "both" {
+	id = clumio_policy.test_policy_a.id
+	name = clumio_policy.test_policy_a.name
+}`),
+				ExpectError: regexp.MustCompile("Invalid Attribute Combination"),
+			},
+			{
+				Config: getTestAccDataSourceClumioPolicy(`
+data "clumio_policies" "invalid" {
+	name_regex = "acceptance-test-data-source-policy-("
+}`),
+				ExpectError: regexp.MustCompile("(?s)Invalid Attribute Value.*RE2"),
+			},
+			{
+				Config: getTestAccDataSourceClumioPolicy(""),
+			},
+		},
+	})
+}
+
+// getTestAccDataSourceClumioPolicy returns the configuration of two policies and of the data
+// sources reading them, followed by the extra configuration.
+func getTestAccDataSourceClumioPolicy(extra string) string {
+	baseUrl := os.Getenv(common.ClumioApiBaseUrl)
+	return fmt.Sprintf(testAccDataSourceClumioPolicy, baseUrl) + extra
+}
+
+const testAccDataSourceClumioPolicy = `
+provider clumio{
+	clumio_api_base_url = "%s"
+}
+
+resource "clumio_policy" "test_policy_a" {
+	name = "acceptance-test-data-source-policy-a"
+	timezone = "UTC"
+	operations {
+		action_setting = "window"
+		type = "aws_ebs_volume_backup"
+		backup_window_tz {
+			start_time = "01:00"
+			end_time = "05:00"
+		}
+		slas {
+			retention_duration {
+				unit = "days"
+				value = 30
+			}
+			rpo_frequency {
+				unit = "days"
+				value = 1
+			}
+		}
+		advanced_settings {
+			aws_ebs_volume_backup {
+				backup_tier = "lite"
+			}
+		}
+	}
+}
+
+resource "clumio_policy" "test_policy_b" {
+	name = "acceptance-test-data-source-policy-b"
+	operations {
+		action_setting = "immediate"
+		type = "aws_ebs_volume_backup"
+		slas {
+			retention_duration {
+				unit = "days"
+				value = 5
+			}
+			rpo_frequency {
+				unit = "days"
+				value = 1
+			}
+		}
+	}
+	operations {
+		action_setting = "immediate"
+		type = "aws_ec2_instance_backup"
+		slas {
+			retention_duration {
+				unit = "days"
+				value = 5
+			}
+			rpo_frequency {
+				unit = "days"
+				value = 1
+			}
+		}
+	}
+}
+
+data "clumio_policy" "by_name" {
+	name = clumio_policy.test_policy_a.name
+}
+
+data "clumio_policy" "by_id" {
+	id = clumio_policy.test_policy_b.id
+}
+
+data "clumio_policies" "all" {
+	name_regex = "^acceptance-test-data-source-policy-[ab]$"
+	depends_on = [clumio_policy.test_policy_a, clumio_policy.test_policy_b]
+}
+
+data "clumio_policies" "ec2" {
+	name_regex = "^acceptance-test-data-source-policy-"
+	operation_type = "aws_ec2_instance_backup"
+	activation_status = "activated"
+	depends_on = [clumio_policy.test_policy_a, clumio_policy.test_policy_b]
+}
+
+data "clumio_policies" "deactivated" {
+	name_regex = "^acceptance-test-data-source-policy-"
+	activation_status = "deactivated"
+	depends_on = [clumio_policy.test_policy_a, clumio_policy.test_policy_b]
+}
+`
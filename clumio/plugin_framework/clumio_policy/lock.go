@@ -0,0 +1,155 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the handling of the lock held on a policy while one of its tasks is in progress,
+// during which the Clumio API rejects the updates and deletions of the policy.
+
+package clumio_policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	policyDefinitions "github.com/clumio-code/clumio-go-sdk/controllers/policy_definitions"
+	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// ModifyPlan fails the plan of an update or deletion of a locked policy, unless
+// wait_for_unlock is set, in which case the apply waits for the lock to clear.
+func (r *policyResource) ModifyPlan(
+	ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+
+	// Nothing is sent to a locked policy on create or when the policy is left unchanged.
+	// The client is not configured yet when the plan is validated offline.
+	if req.State.Raw.IsNull() || r.client == nil {
+		return
+	}
+	destroy := req.Plan.Raw.IsNull()
+	if !destroy && !policyUpdated(req.Plan.Raw, req.State.Raw) {
+		return
+	}
+
+	var state policyResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	waitForUnlock := state.WaitForUnlock
+	if !destroy {
+		resp.Diagnostics.Append(req.Plan.GetAttribute(
+			ctx, path.Root(schemaWaitForUnlock), &waitForUnlock)...)
+	}
+	// The apply decides whether to wait once wait_for_unlock is known.
+	if resp.Diagnostics.HasError() || state.ID.ValueString() == "" ||
+		waitForUnlock.IsUnknown() {
+		return
+	}
+
+	pd := policyDefinitions.NewPolicyDefinitionsV1(r.client.WithContext(ctx).ClumioConfig())
+	res, apiErr := pd.ReadPolicyDefinition(state.ID.ValueString(), nil)
+	if apiErr != nil {
+		// A policy deleted outside of Terraform is recreated, and the refresh reports
+		// the other errors.
+		tflog.Warn(ctx, fmt.Sprintf("Unable to read the lock status of policy %s: %v",
+			state.ID.ValueString(), common.NewAPIError(apiErr)))
+		return
+	}
+	lockStatus := lockStatusUnlocked
+	if res.LockStatus != nil {
+		lockStatus = *res.LockStatus
+	}
+	if lockStatus == lockStatusUnlocked {
+		return
+	}
+
+	action := "updated"
+	if destroy {
+		action = "deleted"
+	}
+	if !waitForUnlock.IsNull() && !waitForUnlock.IsUnknown() {
+		resp.Diagnostics.AddWarning(policyLockedSummary,
+			fmt.Sprintf("The policy %q is locked (lock status %q). It will be %s once the"+
+				" lock clears, waiting up to %s as set by wait_for_unlock.",
+				state.Name.ValueString(), lockStatus, action, waitForUnlock.ValueString()))
+		return
+	}
+	resp.Diagnostics.AddError(policyLockedSummary,
+		fmt.Sprintf("The policy %q is locked (lock status %q) by an operation in progress"+
+			" and cannot be %s until the operation completes. Apply again once the policy"+
+			" is unlocked, or set wait_for_unlock to wait for the lock to clear.",
+			state.Name.ValueString(), lockStatus, action))
+}
+
+// policyUpdated returns whether the plan changes the attributes of the policy sent to the
+// Clumio API, rather than only the attributes known to Terraform such as wait_for_completion.
+// A plan whose attributes are not known yet is assumed to change them.
+func policyUpdated(plan tftypes.Value, state tftypes.Value) bool {
+	var planAttributes, stateAttributes map[string]tftypes.Value
+	if plan.As(&planAttributes) != nil || state.As(&stateAttributes) != nil {
+		return true
+	}
+	for _, name := range []string{schemaName, schemaTimezone, schemaActivationStatus,
+		schemaOrganizationalUnitId, schemaOperations} {
+		if !planAttributes[name].Equal(stateAttributes[name]) {
+			return true
+		}
+	}
+	return false
+}
+
+// waitForPolicyUnlock waits for the lock of the policy to clear when wait_for_unlock is set,
+// reading the policy every interval. The wait is bounded by both wait_for_unlock and the
+// deadline of ctx, derived from the timeouts of the operation.
+func waitForPolicyUnlock(ctx context.Context, pd policyDefinitions.PolicyDefinitionsV1Client,
+	policyId string, waitForUnlock types.String, interval time.Duration) diag.Diagnostics {
+
+	var diags diag.Diagnostics
+	if waitForUnlock.IsNull() || waitForUnlock.IsUnknown() {
+		return diags
+	}
+	wait, err := time.ParseDuration(waitForUnlock.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root(schemaWaitForUnlock), "Invalid wait_for_unlock",
+			fmt.Sprintf(errorFmt, err))
+		return diags
+	}
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	for {
+		res, apiErr := pd.ReadPolicyDefinition(policyId, nil)
+		if apiErr != nil {
+			diags.Append(common.APIErrorDiagnostics(errorPolicyReadMsg, apiErr, nil)...)
+			return diags
+		}
+		lockStatus := lockStatusUnlocked
+		if res.LockStatus != nil {
+			lockStatus = *res.LockStatus
+		}
+		if lockStatus == lockStatusUnlocked {
+			return diags
+		}
+		tflog.Debug(ctx, "Waiting for the Clumio policy to be unlocked", map[string]any{
+			"policy_id":   policyId,
+			"lock_status": lockStatus,
+		})
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			detail := fmt.Sprintf(errorFmt, ctx.Err())
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				detail = fmt.Sprintf("The policy %s is still locked (lock status %q).",
+					policyId, lockStatus)
+			}
+			diags.AddError("Timed out waiting for the Clumio policy to be unlocked.",
+				detail)
+			return diags
+		case <-timer.C:
+		}
+	}
+}
@@ -6,18 +6,22 @@ package clumio_policy
 import (
 	"context"
 	"fmt"
-	"strings"
+	"strconv"
 
 	apiutils "github.com/clumio-code/clumio-go-sdk/api_utils"
 	policyDefinitions "github.com/clumio-code/clumio-go-sdk/controllers/policy_definitions"
 	"github.com/clumio-code/clumio-go-sdk/models"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
@@ -26,9 +30,12 @@ import (
 )
 
 var (
-	_ resource.Resource                = &policyResource{}
-	_ resource.ResourceWithConfigure   = &policyResource{}
-	_ resource.ResourceWithImportState = &policyResource{}
+	_ resource.Resource                   = &policyResource{}
+	_ resource.ResourceWithConfigure      = &policyResource{}
+	_ resource.ResourceWithImportState    = &policyResource{}
+	_ resource.ResourceWithModifyPlan     = &policyResource{}
+	_ resource.ResourceWithUpgradeState   = &policyResource{}
+	_ resource.ResourceWithValidateConfig = &policyResource{}
 )
 
 type policyResource struct {
@@ -54,24 +61,24 @@ type pitrConfigModel struct {
 }
 
 type advancedSettingsModel struct {
-	EC2MssqlDatabaseBackup []*replicaModel    `tfsdk:"ec2_mssql_database_backup"`
-	EC2MssqlLogBackup      []*replicaModel    `tfsdk:"ec2_mssql_log_backup"`
-	MssqlDatabaseBackup    []*replicaModel    `tfsdk:"mssql_database_backup"`
-	MssqlLogBackup         []*replicaModel    `tfsdk:"mssql_log_backup"`
-	ProtectionGroupBackup  []*backupTierModel `tfsdk:"protection_group_backup"`
-	EBSVolumeBackup        []*backupTierModel `tfsdk:"aws_ebs_volume_backup"`
-	EC2InstanceBackup      []*backupTierModel `tfsdk:"aws_ec2_instance_backup"`
-	RDSPitrConfigSync      []*pitrConfigModel `tfsdk:"aws_rds_config_sync"`
-	RDSLogicalBackup       []*backupTierModel `tfsdk:"aws_rds_resource_granular_backup"`
+	EC2MssqlDatabaseBackup *replicaModel    `tfsdk:"ec2_mssql_database_backup"`
+	EC2MssqlLogBackup      *replicaModel    `tfsdk:"ec2_mssql_log_backup"`
+	MssqlDatabaseBackup    *replicaModel    `tfsdk:"mssql_database_backup"`
+	MssqlLogBackup         *replicaModel    `tfsdk:"mssql_log_backup"`
+	ProtectionGroupBackup  *backupTierModel `tfsdk:"protection_group_backup"`
+	EBSVolumeBackup        *backupTierModel `tfsdk:"aws_ebs_volume_backup"`
+	EC2InstanceBackup      *backupTierModel `tfsdk:"aws_ec2_instance_backup"`
+	RDSPitrConfigSync      *pitrConfigModel `tfsdk:"aws_rds_config_sync"`
+	RDSLogicalBackup       *backupTierModel `tfsdk:"aws_rds_resource_granular_backup"`
 }
 
 type policyOperationModel struct {
-	ActionSetting    types.String             `tfsdk:"action_setting"`
-	OperationType    types.String             `tfsdk:"type"`
-	BackupWindowTz   []*backupWindowModel     `tfsdk:"backup_window_tz"`
-	Slas             []*slaModel              `tfsdk:"slas"`
-	AdvancedSettings []*advancedSettingsModel `tfsdk:"advanced_settings"`
-	BackupAwsRegion  types.String             `tfsdk:"backup_aws_region"`
+	ActionSetting    types.String           `tfsdk:"action_setting"`
+	OperationType    types.String           `tfsdk:"type"`
+	BackupWindowTz   *backupWindowModel     `tfsdk:"backup_window_tz"`
+	Slas             []*slaModel            `tfsdk:"slas"`
+	AdvancedSettings *advancedSettingsModel `tfsdk:"advanced_settings"`
+	BackupAwsRegion  types.String           `tfsdk:"backup_aws_region"`
 }
 
 type unitValueModel struct {
@@ -86,8 +93,8 @@ type rpoModel struct {
 }
 
 type slaModel struct {
-	RetentionDuration []*unitValueModel `tfsdk:"retention_duration"`
-	RPOFrequency      []*rpoModel       `tfsdk:"rpo_frequency"`
+	RetentionDuration *unitValueModel `tfsdk:"retention_duration"`
+	RPOFrequency      *rpoModel       `tfsdk:"rpo_frequency"`
 }
 
 type backupWindowModel struct {
@@ -103,6 +110,12 @@ type policyResourceModel struct {
 	ActivationStatus     types.String            `tfsdk:"activation_status"`
 	OrganizationalUnitId types.String            `tfsdk:"organizational_unit_id"`
 	Operations           []*policyOperationModel `tfsdk:"operations"`
+	WaitForCompletion    types.Bool              `tfsdk:"wait_for_completion"`
+	LastTaskID           types.String            `tfsdk:"last_task_id"`
+	FailOnTaskError      types.Bool              `tfsdk:"fail_on_task_error"`
+	SkipSlaValidation    types.Bool              `tfsdk:"skip_sla_validation"`
+	WaitForUnlock        types.String            `tfsdk:"wait_for_unlock"`
+	Timeouts             timeouts.Value          `tfsdk:"timeouts"`
 }
 
 // Metadata returns the data source type name.
@@ -113,12 +126,24 @@ func (r *policyResource) Metadata(
 
 // Schema defines the schema for the data source.
 func (r *policyResource) Schema(
-	_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 
-	unitAttribute := schema.StringAttribute{
+	retentionUnitAttribute := schema.StringAttribute{
 		Required: true,
 		Description: "The measurement unit of the SLA parameter. Values include" +
-			" hours, days, months, and years.",
+			" hours, days, weeks, months, and years.",
+		Validators: []validator.String{
+			stringvalidator.OneOf(retentionUnits...),
+		},
+	}
+
+	rpoUnitAttribute := schema.StringAttribute{
+		Required: true,
+		Description: "The measurement unit of the SLA parameter. Values include" +
+			" minutes, hours, days, weeks, months, years, and on_demand.",
+		Validators: []validator.String{
+			stringvalidator.OneOf(rpoUnits...),
+		},
 	}
 
 	valueAttribute := schema.Int64Attribute{
@@ -127,12 +152,12 @@ func (r *policyResource) Schema(
 	}
 
 	unitValueSchemaAttributes := map[string]schema.Attribute{
-		schemaUnit:  unitAttribute,
+		schemaUnit:  retentionUnitAttribute,
 		schemaValue: valueAttribute,
 	}
 
 	rpoValueSchemaAttributes := map[string]schema.Attribute{
-		schemaUnit:  unitAttribute,
+		schemaUnit:  rpoUnitAttribute,
 		schemaValue: valueAttribute,
 		schemaOffsets: schema.ListAttribute{
 			Optional:    true,
@@ -145,10 +170,16 @@ func (r *policyResource) Schema(
 		schemaAlternativeReplica: schema.StringAttribute{
 			Optional:    true,
 			Description: fmt.Sprintf(alternativeReplicaDescFmt, "database"),
+			Validators: []validator.String{
+				stringvalidator.OneOf(replicaPrimary, replicaSyncSecondary, replicaStop),
+			},
 		},
 		schemaPreferredReplica: schema.StringAttribute{
 			Optional:    true,
 			Description: fmt.Sprintf(preferredReplicaDescFmt, "database"),
+			Validators: []validator.String{
+				stringvalidator.OneOf(replicaPrimary, replicaSyncSecondary),
+			},
 		},
 	}
 
@@ -156,123 +187,99 @@ func (r *policyResource) Schema(
 		schemaAlternativeReplica: schema.StringAttribute{
 			Optional:    true,
 			Description: fmt.Sprintf(alternativeReplicaDescFmt, "log"),
+			Validators: []validator.String{
+				stringvalidator.OneOf(replicaPrimary, replicaSyncSecondary, replicaStop),
+			},
 		},
 		schemaPreferredReplica: schema.StringAttribute{
 			Optional:    true,
 			Description: fmt.Sprintf(preferredReplicaDescFmt, "log"),
+			Validators: []validator.String{
+				stringvalidator.OneOf(replicaPrimary, replicaSyncSecondary),
+			},
 		},
 	}
 
 	advancedSettingsSchemaBlocks := map[string]schema.Block{
-		schemaEc2MssqlDatabaseBackup: schema.SetNestedBlock{
+		schemaEc2MssqlDatabaseBackup: schema.SingleNestedBlock{
 			Description: mssqlDatabaseBackupDesc,
-			NestedObject: schema.NestedBlockObject{
-				Attributes: databaseBackupSchemaAttributes,
-			},
-			Validators: []validator.Set{
-				setvalidator.SizeAtMost(1),
-			},
+			Attributes:  databaseBackupSchemaAttributes,
 		},
-		schemaEc2MssqlLogBackup: schema.SetNestedBlock{
+		schemaEc2MssqlLogBackup: schema.SingleNestedBlock{
 			Description: mssqlLogBackupDesc,
-			NestedObject: schema.NestedBlockObject{
-				Attributes: logBackupSchemaAttributes,
-			},
-			Validators: []validator.Set{
-				setvalidator.SizeAtMost(1),
-			},
+			Attributes:  logBackupSchemaAttributes,
 		},
-		schemaMssqlDatabaseBackup: schema.SetNestedBlock{
+		schemaMssqlDatabaseBackup: schema.SingleNestedBlock{
 			Description: mssqlDatabaseBackupDesc,
-			NestedObject: schema.NestedBlockObject{
-				Attributes: databaseBackupSchemaAttributes,
-			},
-			Validators: []validator.Set{
-				setvalidator.SizeAtMost(1),
-			},
+			Attributes:  databaseBackupSchemaAttributes,
 		},
-		schemaMssqlLogBackup: schema.SetNestedBlock{
+		schemaMssqlLogBackup: schema.SingleNestedBlock{
 			Description: mssqlLogBackupDesc,
-			NestedObject: schema.NestedBlockObject{
-				Attributes: logBackupSchemaAttributes,
-			},
-			Validators: []validator.Set{
-				setvalidator.SizeAtMost(1),
-			},
+			Attributes:  logBackupSchemaAttributes,
 		},
-		schemaProtectionGroupBackup: schema.SetNestedBlock{
+		schemaProtectionGroupBackup: schema.SingleNestedBlock{
 			Description: "Additional policy configuration settings for the" +
 				" protection_group_backup operation. If this operation is not of" +
 				" type protection_group_backup, then this field is omitted from" +
 				" the response.",
-			NestedObject: schema.NestedBlockObject{
-				Attributes: map[string]schema.Attribute{
-					schemaBackupTier: schema.StringAttribute{
-						Optional: true,
-						Description: "Backup tier to store the backup in. Valid values are:" +
-							" cold, frozen",
+			Attributes: map[string]schema.Attribute{
+				schemaBackupTier: schema.StringAttribute{
+					Optional: true,
+					Description: "Backup tier to store the backup in. Valid values are:" +
+						" cold, frozen",
+					Validators: []validator.String{
+						stringvalidator.OneOf(backupTierCold, backupTierFrozen),
 					},
 				},
 			},
-			Validators: []validator.Set{
-				setvalidator.SizeAtMost(1),
-			},
 		},
-		schemaEBSVolumeBackup: schema.SetNestedBlock{
+		schemaEBSVolumeBackup: schema.SingleNestedBlock{
 			Description: ebsBackupDesc,
-			NestedObject: schema.NestedBlockObject{
-				Attributes: map[string]schema.Attribute{
-					schemaBackupTier: schema.StringAttribute{
-						Optional:    true,
-						Description: secureVaultLiteDesc,
+			Attributes: map[string]schema.Attribute{
+				schemaBackupTier: schema.StringAttribute{
+					Optional:    true,
+					Description: secureVaultLiteDesc,
+					Validators: []validator.String{
+						stringvalidator.OneOf(backupTierStandard, backupTierLite),
 					},
 				},
 			},
-			Validators: []validator.Set{
-				setvalidator.SizeAtMost(1),
-			},
 		},
-		schemaEC2InstanceBackup: schema.SetNestedBlock{
+		schemaEC2InstanceBackup: schema.SingleNestedBlock{
 			Description: ec2BackupDesc,
-			NestedObject: schema.NestedBlockObject{
-				Attributes: map[string]schema.Attribute{
-					schemaBackupTier: schema.StringAttribute{
-						Optional:    true,
-						Description: secureVaultLiteDesc,
+			Attributes: map[string]schema.Attribute{
+				schemaBackupTier: schema.StringAttribute{
+					Optional:    true,
+					Description: secureVaultLiteDesc,
+					Validators: []validator.String{
+						stringvalidator.OneOf(backupTierStandard, backupTierLite),
 					},
 				},
 			},
-			Validators: []validator.Set{
-				setvalidator.SizeAtMost(1),
-			},
 		},
-		schemaRDSPitrConfigSync: schema.SetNestedBlock{
+		schemaRDSPitrConfigSync: schema.SingleNestedBlock{
 			Description: rdsPitrConfigSyncDesc,
-			NestedObject: schema.NestedBlockObject{
-				Attributes: map[string]schema.Attribute{
-					schemaApply: schema.StringAttribute{
-						Optional:    true,
-						Description: pitrConfigDesc,
+			Attributes: map[string]schema.Attribute{
+				schemaApply: schema.StringAttribute{
+					Optional:    true,
+					Description: pitrConfigDesc,
+					Validators: []validator.String{
+						stringvalidator.OneOf(applyImmediate, applyMaintenanceWindow),
 					},
 				},
 			},
-			Validators: []validator.Set{
-				setvalidator.SizeAtMost(1),
-			},
 		},
-		schemaRdsLogicalBackup: schema.SetNestedBlock{
+		schemaRdsLogicalBackup: schema.SingleNestedBlock{
 			Description: rdsLogicalBackupDesc,
-			NestedObject: schema.NestedBlockObject{
-				Attributes: map[string]schema.Attribute{
-					schemaBackupTier: schema.StringAttribute{
-						Optional:    true,
-						Description: rdsLogicalBackupAdvancedSettingDesc,
+			Attributes: map[string]schema.Attribute{
+				schemaBackupTier: schema.StringAttribute{
+					Optional:    true,
+					Description: rdsLogicalBackupAdvancedSettingDesc,
+					Validators: []validator.String{
+						stringvalidator.OneOf(backupTierStandard, backupTierFrozen),
 					},
 				},
 			},
-			Validators: []validator.Set{
-				setvalidator.SizeAtMost(1),
-			},
 		},
 	}
 
@@ -284,6 +291,10 @@ func (r *policyResource) Schema(
 				" `mm` represents the minute of the day based on" +
 				" the 24 hour clock.",
 			Optional: true,
+			Validators: []validator.String{
+				stringvalidator.RegexMatches(timeOfDayRegex,
+					"value must be a time in the format hh:mm"),
+			},
 		},
 		schemaEndTime: schema.StringAttribute{
 			Description: "The time when the backup window closes." +
@@ -301,23 +312,24 @@ func (r *policyResource) Schema(
 			PlanModifiers: []planmodifier.String{
 				stringplanmodifier.UseStateForUnknown(),
 			},
+			Validators: []validator.String{
+				stringvalidator.RegexMatches(optionalTimeOfDayRegex,
+					"value must be a time in the format hh:mm or empty"),
+			},
 		},
 	}
 
 	slaSchemaBlocks := map[string]schema.Block{
-		schemaRetentionDuration: schema.SetNestedBlock{
+		schemaRetentionDuration: schema.SingleNestedBlock{
 			Description: "The retention time for this SLA. " +
 				"For example, to retain the backup for 1 month," +
 				" set unit=months and value=1.",
-			NestedObject: schema.NestedBlockObject{
-				Attributes: unitValueSchemaAttributes,
-			},
-			Validators: []validator.Set{
-				setvalidator.IsRequired(),
-				setvalidator.SizeAtMost(1),
+			Attributes: unitValueSchemaAttributes,
+			Validators: []validator.Object{
+				objectvalidator.IsRequired(),
 			},
 		},
-		schemaRpoFrequency: schema.SetNestedBlock{
+		schemaRpoFrequency: schema.SingleNestedBlock{
 			Description: "The minimum frequency between " +
 				"backups for this SLA. Also known as the " +
 				"recovery point objective (RPO) interval. For" +
@@ -329,12 +341,9 @@ func (r *policyResource) Schema(
 				"specify a day of week for Weekly SLA. For example, " +
 				"set offsets=[1] will trigger backup on every " +
 				"Monday.",
-			NestedObject: schema.NestedBlockObject{
-				Attributes: rpoValueSchemaAttributes,
-			},
-			Validators: []validator.Set{
-				setvalidator.IsRequired(),
-				setvalidator.SizeAtMost(1),
+			Attributes: rpoValueSchemaAttributes,
+			Validators: []validator.Object{
+				objectvalidator.IsRequired(),
 			},
 		},
 	}
@@ -346,13 +355,19 @@ func (r *policyResource) Schema(
 				"immediate: to start backup process immediately" +
 				"window: to start backup in the specified window",
 			Required: true,
+			Validators: []validator.String{
+				stringvalidator.OneOf(actionSettingImmediate, actionSettingWindow),
+			},
 		},
 		schemaOperationType: schema.StringAttribute{
 			Description: "The type of operation to be performed. Depending on the type " +
 				"selected, `advanced_settings` may also be required. See the API " +
 				"Documentation for \"List policies\" for more information about the " +
-				"supported types.",
+				"supported types. Each type can only be used by one operation.",
 			Required: true,
+			Validators: []validator.String{
+				stringvalidator.OneOf(operationTypes...),
+			},
 		},
 		schemaBackupAwsRegion: schema.StringAttribute{
 			Description: "The region in which this backup is stored. This might be used " +
@@ -360,28 +375,22 @@ func (r *policyResource) Schema(
 				"example: `us-east-1`, `us-west-2`, .... If no value is provided, it " +
 				"defaults to in-region (the asset's source region).",
 			Optional: true,
+			Validators: []validator.String{
+				stringvalidator.RegexMatches(awsRegionRegex,
+					"value must be an AWS region, such as us-west-2"),
+			},
 		},
 	}
 
 	operationSchemaBlocks := map[string]schema.Block{
-		schemaBackupWindowTz: schema.SetNestedBlock{
+		schemaBackupWindowTz: schema.SingleNestedBlock{
 			Description: "The start and end times for the customized" +
 				" backup window that reflects the user-defined timezone.",
-			NestedObject: schema.NestedBlockObject{
-				Attributes: backupWindowSchemaAttributes,
-			},
-			Validators: []validator.Set{
-				setvalidator.SizeAtMost(1),
-			},
+			Attributes: backupWindowSchemaAttributes,
 		},
-		schemaAdvancedSettings: schema.SetNestedBlock{
+		schemaAdvancedSettings: schema.SingleNestedBlock{
 			Description: "Additional operation-specific policy settings.",
-			NestedObject: schema.NestedBlockObject{
-				Blocks: advancedSettingsSchemaBlocks,
-			},
-			Validators: []validator.Set{
-				setvalidator.SizeAtMost(1),
-			},
+			Blocks:      advancedSettingsSchemaBlocks,
 		},
 		schemaSlas: schema.SetNestedBlock{
 			Description: "The service level agreement (SLA) for the policy." +
@@ -401,6 +410,9 @@ func (r *policyResource) Schema(
 		// This description is used by the documentation generator and the language server.
 		Description: "Clumio Policy Resource used to schedule backups on" +
 			" Clumio supported data sources.",
+		// Version 1 turns the nested blocks of the operations with a single element into
+		// single nested blocks.
+		Version: 1,
 		Attributes: map[string]schema.Attribute{
 			schemaId: schema.StringAttribute{
 				Description: "Policy Id.",
@@ -431,6 +443,9 @@ func (r *policyResource) Schema(
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
 				},
+				Validators: []validator.String{
+					timezoneValidator{},
+				},
 			},
 			schemaActivationStatus: schema.StringAttribute{
 				Description: "The status of the policy. Valid values are:" +
@@ -455,8 +470,34 @@ func (r *policyResource) Schema(
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
+			schemaWaitForCompletion: common.WaitForCompletionAttribute(),
+			schemaLastTaskId:        common.LastTaskIdAttribute(),
+			schemaFailOnTaskError:   common.FailOnTaskErrorAttribute(),
+			schemaSkipSlaValidation: schema.BoolAttribute{
+				Description: "Set to true to skip the checks of the SLAs of the operations" +
+					" at plan time, such as a retention shorter than the RPO frequency, for the" +
+					" SLAs which the Clumio API accepts nonetheless. Defaults to false.",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+			},
+			schemaWaitForUnlock: schema.StringAttribute{
+				Description: "The maximum time to wait for the lock held on the policy while" +
+					" one of its tasks is in progress to clear before updating or deleting" +
+					" the policy, as a duration string such as `10m`. When unset, the plan" +
+					" fails if the policy is locked.",
+				Optional: true,
+				Validators: []validator.String{
+					durationValidator{},
+				},
+			},
 		},
 		Blocks: map[string]schema.Block{
+			schemaTimeouts: timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Update: true,
+				Delete: true,
+			}),
 			schemaOperations: schema.SetNestedBlock{
 				Description: "Each data source to be protected should have details provided in " +
 					"the list of operations. These details include information such as how often " +
@@ -493,7 +534,16 @@ func (r *policyResource) Create(
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	pd := policyDefinitions.NewPolicyDefinitionsV1(r.client.ClumioConfig)
+
+	createTimeout, diags := plan.Timeouts.Create(ctx, r.client.Timeouts().Create)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	pd := policyDefinitions.NewPolicyDefinitionsV1(r.client.WithContext(ctx).ClumioConfig())
 	activationStatus := plan.ActivationStatus.ValueString()
 	name := plan.Name.ValueString()
 	timezone := plan.Timezone.ValueString()
@@ -508,20 +558,20 @@ func (r *policyResource) Create(
 		Operations:           policyOperations,
 		OrganizationalUnitId: &orgUnitId,
 	}
+	defer r.client.InvalidateLookups(common.LookupPolicies)
 	res, apiErr := pd.CreatePolicyDefinition(pdRequest)
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
-			"Error creating policy definition.",
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			"Error creating policy definition.", apiErr, nil)...)
 		return
 	}
 	plan.ID = types.StringValue(*res.Id)
-	apiErr, diags = readPolicyAndUpdateModel(ctx, &plan, pd)
+	plan.LastTaskID = types.StringNull()
+	apiErr, diags = readPolicyAndUpdateModel(ctx, &plan, pd, resp.Private)
 	resp.Diagnostics.Append(diags...)
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
-			errorPolicyReadMsg,
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			errorPolicyReadMsg, apiErr, nil)...)
 		return
 	}
 	diags = resp.State.Set(ctx, plan)
@@ -534,7 +584,7 @@ func (r *policyResource) Create(
 // Read refreshes the Terraform state with the latest data.
 func (r *policyResource) Read(
 	ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	pd := policyDefinitions.NewPolicyDefinitionsV1(r.client.ClumioConfig)
+	pd := policyDefinitions.NewPolicyDefinitionsV1(r.client.WithContext(ctx).ClumioConfig())
 	// Get current state
 	var state policyResourceModel
 	diags := req.State.Get(ctx, &state)
@@ -542,18 +592,27 @@ func (r *policyResource) Read(
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	apiErr, diags := readPolicyAndUpdateModel(ctx, &state, pd)
+	apiErr, diags := readPolicyAndUpdateModel(ctx, &state, pd, resp.Private)
 	resp.Diagnostics.Append(diags...)
 	if apiErr != nil {
-		if strings.Contains(apiErr.Error(), "The resource is not found.") {
-			state.ID = types.StringValue("")
-		} else {
-			resp.Diagnostics.AddError(
-				errorPolicyReadMsg,
-				fmt.Sprintf(errorFmt, string(apiErr.Response)))
+		if common.NewAPIError(apiErr).IsNotFound() {
+			common.RemoveMissingResource(ctx, resp,
+				fmt.Sprintf("Clumio policy %q", state.Name.ValueString()))
 			return
 		}
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			errorPolicyReadMsg, apiErr, nil)...)
+		return
+	}
+	common.SetTaskAttributeDefaults(&state.WaitForCompletion, &state.FailOnTaskError)
+	// skip_sla_validation is only known to Terraform and is missing after an import.
+	if state.SkipSlaValidation.IsNull() {
+		state.SkipSlaValidation = types.BoolValue(false)
 	}
+	_, diags = common.LastTaskDiagnostics(ctx, r.client, resp.Private,
+		state.WaitForCompletion, state.LastTaskID, state.FailOnTaskError,
+		fmt.Sprintf("policy %q", state.Name.ValueString()))
+	resp.Diagnostics.Append(diags...)
 	diags = resp.State.Set(ctx, state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -564,14 +623,29 @@ func (r *policyResource) Read(
 // Update updates the resource and sets the updated Terraform state on success.
 func (r *policyResource) Update(
 	ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan policyResourceModel
+	var plan, state policyResourceModel
 	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// Nothing is sent to the Clumio API when only the attributes known to Terraform change.
+	if !policyUpdated(req.Plan.Raw, req.State.Raw) {
+		plan.LastTaskID = state.LastTaskID
+		resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+		return
+	}
+
+	updateTimeout, diags := plan.Timeouts.Update(ctx, r.client.Timeouts().Update)
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
 
-	pd := policyDefinitions.NewPolicyDefinitionsV1(r.client.ClumioConfig)
+	pd := policyDefinitions.NewPolicyDefinitionsV1(r.client.WithContext(ctx).ClumioConfig())
 	activationStatus := plan.ActivationStatus.ValueString()
 	name := plan.Name.ValueString()
 	timezone := plan.Timezone.ValueString()
@@ -588,34 +662,53 @@ func (r *policyResource) Update(
 		Operations:           policyOperations,
 		OrganizationalUnitId: &orgUnitId,
 	}
+	resp.Diagnostics.Append(waitForPolicyUnlock(
+		ctx, pd, plan.ID.ValueString(), plan.WaitForUnlock,
+		r.client.PollInterval(intervalInSec))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(checkPolicyUnchanged(ctx, pd, plan.ID.ValueString(), req.Private,
+		fmt.Sprintf("Error updating Policy Definition %v.", plan.ID.ValueString()))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer r.client.InvalidateLookups(common.LookupPolicies)
 	res, apiErr := pd.UpdatePolicyDefinition(plan.ID.ValueString(), nil, pdRequest)
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
 			fmt.Sprintf(
-				"Error updating Policy Definition %v.", plan.ID.ValueString()),
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+				"Error updating Policy Definition %v.", plan.ID.ValueString()), apiErr, nil)...)
 		return
 	}
-	err := common.PollTask(ctx, r.client, *res.TaskId, timeoutInSec, intervalInSec)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			fmt.Sprintf(
-				"Error updating Policy Definition %v.", plan.ID.ValueString()),
-			fmt.Sprintf(errorFmt, err.Error()))
-		return
+	plan.LastTaskID = types.StringValue(*res.TaskId)
+	if plan.WaitForCompletion.ValueBool() {
+		err := common.PollTask(ctx, r.client, *res.TaskId, intervalInSec)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				fmt.Sprintf(
+					"Error updating Policy Definition %v.", plan.ID.ValueString()),
+				fmt.Sprintf(errorFmt, err.Error()))
+			return
+		}
 	}
-	apiErr, diags = readPolicyAndUpdateModel(ctx, &plan, pd)
+	lockStatus := plan.LockStatus
+	apiErr, diags = readPolicyAndUpdateModel(ctx, &plan, pd, resp.Private)
 	resp.Diagnostics.Append(diags...)
 	if apiErr != nil {
-		if strings.Contains(apiErr.Error(), "The resource is not found.") {
+		if common.NewAPIError(apiErr).IsNotFound() {
 			plan.ID = types.StringValue("")
 		} else {
-			resp.Diagnostics.AddError(
-				errorPolicyReadMsg,
-				fmt.Sprintf(errorFmt, string(apiErr.Response)))
+			resp.Diagnostics.Append(common.APIErrorDiagnostics(
+				errorPolicyReadMsg, apiErr, nil)...)
 			return
 		}
 	}
+	if !plan.WaitForCompletion.ValueBool() {
+		// The policy stays locked until the task completes. The planned lock status is
+		// kept so that the state matches the plan, and is refreshed by the next read.
+		plan.LockStatus = lockStatus
+	}
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
@@ -634,16 +727,38 @@ func (r *policyResource) Delete(
 		return
 	}
 
-	pd := policyDefinitions.NewPolicyDefinitionsV1(r.client.ClumioConfig)
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, r.client.Timeouts().Delete)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	pd := policyDefinitions.NewPolicyDefinitionsV1(r.client.WithContext(ctx).ClumioConfig())
+	resp.Diagnostics.Append(waitForPolicyUnlock(
+		ctx, pd, state.ID.ValueString(), state.WaitForUnlock,
+		r.client.PollInterval(intervalInSec))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(checkPolicyUnchanged(ctx, pd, state.ID.ValueString(), req.Private,
+		fmt.Sprintf("Error deleting Policy Definition %v.", state.ID.ValueString()))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer r.client.InvalidateLookups(common.LookupPolicies)
 	res, apiErr := pd.DeletePolicyDefinition(state.ID.ValueString())
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
 			fmt.Sprintf(
-				"Error deleting Policy Definition %v.", state.ID.ValueString()),
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+				"Error deleting Policy Definition %v.", state.ID.ValueString()), apiErr, nil)...)
+		return
+	}
+	if !state.WaitForCompletion.ValueBool() {
 		return
 	}
-	err := common.PollTask(ctx, r.client, *res.TaskId, timeoutInSec, intervalInSec)
+	err := common.PollTask(ctx, r.client, *res.TaskId, intervalInSec)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			fmt.Sprintf(
@@ -659,14 +774,18 @@ func (r *policyResource) ImportState(ctx context.Context, req resource.ImportSta
 	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
 }
 
+// readPolicyAndUpdateModel reads the policy into the model, and records its version in the
+// private state so that the next update or deletion can check that the policy was not changed
+// outside Terraform in the meantime.
 func readPolicyAndUpdateModel(ctx context.Context,
-	state *policyResourceModel, pd policyDefinitions.PolicyDefinitionsV1Client) (
-	*apiutils.APIError, diag.Diagnostics) {
+	state *policyResourceModel, pd policyDefinitions.PolicyDefinitionsV1Client,
+	private common.PrivateState) (*apiutils.APIError, diag.Diagnostics) {
 	res, apiErr := pd.ReadPolicyDefinition(state.ID.ValueString(), nil)
 	if apiErr != nil {
 		tflog.Error(ctx, fmt.Sprintf("Error retrieving policy with ID: %s. Error: %v", state.ID.ValueString(), apiErr))
 		return apiErr, nil
 	}
+	diags := common.SetPrivateVersion(ctx, private, policyVersion(res))
 	state.LockStatus = types.StringValue(*res.LockStatus)
 	state.Name = types.StringValue(*res.Name)
 	state.Timezone = types.StringValue(*res.Timezone)
@@ -676,11 +795,37 @@ func readPolicyAndUpdateModel(ctx context.Context,
 	if res.OrganizationalUnitId != nil {
 		state.OrganizationalUnitId = types.StringValue(*res.OrganizationalUnitId)
 	}
-	stateOp, diags := mapClumioOperationsToSchemaOperations(ctx, res.Operations)
+	stateOp, opDiags := mapClumioOperationsToSchemaOperations(ctx, res.Operations)
+	diags.Append(opDiags...)
 	state.Operations = stateOp
 	return nil, diags
 }
 
+// checkPolicyUnchanged reads the policy right before it is updated or deleted, and reports
+// an error with the given summary if it was changed outside Terraform since the last refresh.
+// The policy definitions API returns no ETag, so its updated time is compared instead.
+func checkPolicyUnchanged(ctx context.Context, pd policyDefinitions.PolicyDefinitionsV1Client,
+	policyId string, private common.PrivateState, summary string) diag.Diagnostics {
+	res, apiErr := pd.ReadPolicyDefinition(policyId, nil)
+	if apiErr != nil {
+		// The update or deletion reports a policy deleted in the meantime.
+		if common.NewAPIError(apiErr).IsNotFound() {
+			return nil
+		}
+		return common.APIErrorDiagnostics(errorPolicyReadMsg, apiErr, nil)
+	}
+	return common.CheckPrivateVersion(ctx, private, policyVersion(res), summary)
+}
+
+// policyVersion returns the version of the policy recorded in the private state, which is its
+// updated time, or an empty string if the API did not return it.
+func policyVersion(res *models.ReadPolicyResponse) string {
+	if res.UpdatedTime == nil {
+		return ""
+	}
+	return strconv.FormatInt(*res.UpdatedTime, 10)
+}
+
 // mapSchemaOperationsToClumioOperations maps the schema operations to the Clumio API
 // request operations.
 func mapSchemaOperationsToClumioOperations(ctx context.Context,
@@ -695,8 +840,8 @@ func mapSchemaOperationsToClumioOperations(ctx context.Context,
 
 		var backupWindowTz *models.BackupWindow
 		if operation.BackupWindowTz != nil {
-			startTime := operation.BackupWindowTz[0].StartTime.ValueString()
-			endTime := operation.BackupWindowTz[0].EndTime.ValueString()
+			startTime := operation.BackupWindowTz.StartTime.ValueString()
+			endTime := operation.BackupWindowTz.EndTime.ValueString()
 			backupWindowTz = &models.BackupWindow{
 				EndTime:   &endTime,
 				StartTime: &startTime,
@@ -712,8 +857,8 @@ func mapSchemaOperationsToClumioOperations(ctx context.Context,
 			for _, operationSla := range operation.Slas {
 				backupSLA := &models.BackupSLA{}
 				if operationSla.RetentionDuration != nil {
-					unit := operationSla.RetentionDuration[0].Unit.ValueString()
-					value := operationSla.RetentionDuration[0].Value.ValueInt64()
+					unit := operationSla.RetentionDuration.Unit.ValueString()
+					value := operationSla.RetentionDuration.Value.ValueInt64()
 					backupSLA.RetentionDuration = &models.RetentionBackupSLAParam{
 						Unit:  &unit,
 						Value: &value,
@@ -721,9 +866,9 @@ func mapSchemaOperationsToClumioOperations(ctx context.Context,
 				}
 				if operationSla.RPOFrequency != nil {
 					var offsets []*int64
-					unit := operationSla.RPOFrequency[0].Unit.ValueString()
-					value := operationSla.RPOFrequency[0].Value.ValueInt64()
-					diags = operationSla.RPOFrequency[0].Offsets.ElementsAs(ctx, &offsets, true)
+					unit := operationSla.RPOFrequency.Unit.ValueString()
+					value := operationSla.RPOFrequency.Value.ValueInt64()
+					diags = operationSla.RPOFrequency.Offsets.ElementsAs(ctx, &offsets, true)
 					backupSLA.RpoFrequency = &models.RPOBackupSLAParam{
 						Unit:    &unit,
 						Value:   &value,
@@ -752,7 +897,7 @@ func mapSchemaOperationsToClumioOperations(ctx context.Context,
 func mapClumioOperationsToSchemaOperations(ctx context.Context,
 	operations []*models.PolicyOperation) ([]*policyOperationModel, diag.Diagnostics) {
 	var diags diag.Diagnostics
-	schemaOperations := make([]*policyOperationModel, 0)
+	schemaOperations := make([]*policyOperationModel, 0, len(operations))
 	for _, operation := range operations {
 		schemaOperation := &policyOperationModel{}
 		schemaOperation.ActionSetting = types.StringValue(*operation.ActionSetting)
@@ -763,10 +908,10 @@ func mapClumioOperationsToSchemaOperations(ctx context.Context,
 		}
 
 		if operation.BackupWindowTz != nil {
-			window := &backupWindowModel{}
-			window.StartTime = types.StringValue(*operation.BackupWindowTz.StartTime)
-			window.EndTime = types.StringValue(*operation.BackupWindowTz.EndTime)
-			schemaOperation.BackupWindowTz = []*backupWindowModel{window}
+			schemaOperation.BackupWindowTz = &backupWindowModel{
+				StartTime: types.StringValue(*operation.BackupWindowTz.StartTime),
+				EndTime:   types.StringValue(*operation.BackupWindowTz.EndTime),
+			}
 		}
 
 		if operation.Slas != nil {
@@ -774,23 +919,19 @@ func mapClumioOperationsToSchemaOperations(ctx context.Context,
 			for _, sla := range operation.Slas {
 				backupSla := &slaModel{}
 				if sla.RetentionDuration != nil {
-					backupSla.RetentionDuration = []*unitValueModel{
-						{
-							Unit:  types.StringValue(*sla.RetentionDuration.Unit),
-							Value: types.Int64Value(*sla.RetentionDuration.Value),
-						},
+					backupSla.RetentionDuration = &unitValueModel{
+						Unit:  types.StringValue(*sla.RetentionDuration.Unit),
+						Value: types.Int64Value(*sla.RetentionDuration.Value),
 					}
 				}
 				if sla.RpoFrequency != nil {
 					offsets, rpoDiags := types.ListValueFrom(ctx,
 						types.Int64Type, sla.RpoFrequency.Offsets)
 					diags = rpoDiags
-					backupSla.RPOFrequency = []*rpoModel{
-						{
-							Unit:    types.StringValue(*sla.RpoFrequency.Unit),
-							Value:   types.Int64Value(*sla.RpoFrequency.Value),
-							Offsets: offsets,
-						},
+					backupSla.RPOFrequency = &rpoModel{
+						Unit:    types.StringValue(*sla.RpoFrequency.Unit),
+						Value:   types.Int64Value(*sla.RpoFrequency.Value),
+						Offsets: offsets,
 					}
 				}
 				backupSlas = append(backupSlas, backupSla)
@@ -799,87 +940,56 @@ func mapClumioOperationsToSchemaOperations(ctx context.Context,
 		}
 		if operation.AdvancedSettings != nil {
 			advSettings := &advancedSettingsModel{}
-			if operation.AdvancedSettings.Ec2MssqlDatabaseBackup != nil {
-				advSettings.EC2MssqlDatabaseBackup = []*replicaModel{
-					{
-						AlternativeReplica: types.StringValue(
-							*operation.AdvancedSettings.Ec2MssqlDatabaseBackup.AlternativeReplica),
-						PreferredReplica: types.StringValue(
-							*operation.AdvancedSettings.Ec2MssqlDatabaseBackup.PreferredReplica),
-					},
+			if setting := operation.AdvancedSettings.Ec2MssqlDatabaseBackup; setting != nil {
+				advSettings.EC2MssqlDatabaseBackup = &replicaModel{
+					AlternativeReplica: types.StringValue(*setting.AlternativeReplica),
+					PreferredReplica:   types.StringValue(*setting.PreferredReplica),
 				}
 			}
-			if operation.AdvancedSettings.Ec2MssqlLogBackup != nil {
-				advSettings.EC2MssqlLogBackup = []*replicaModel{
-					{
-						AlternativeReplica: types.StringValue(
-							*operation.AdvancedSettings.Ec2MssqlLogBackup.AlternativeReplica),
-						PreferredReplica: types.StringValue(
-							*operation.AdvancedSettings.Ec2MssqlLogBackup.PreferredReplica),
-					},
+			if setting := operation.AdvancedSettings.Ec2MssqlLogBackup; setting != nil {
+				advSettings.EC2MssqlLogBackup = &replicaModel{
+					AlternativeReplica: types.StringValue(*setting.AlternativeReplica),
+					PreferredReplica:   types.StringValue(*setting.PreferredReplica),
 				}
 			}
-			if operation.AdvancedSettings.MssqlDatabaseBackup != nil {
-				advSettings.MssqlDatabaseBackup = []*replicaModel{
-					{
-						AlternativeReplica: types.StringValue(
-							*operation.AdvancedSettings.MssqlDatabaseBackup.AlternativeReplica),
-						PreferredReplica: types.StringValue(
-							*operation.AdvancedSettings.MssqlDatabaseBackup.PreferredReplica),
-					},
+			if setting := operation.AdvancedSettings.MssqlDatabaseBackup; setting != nil {
+				advSettings.MssqlDatabaseBackup = &replicaModel{
+					AlternativeReplica: types.StringValue(*setting.AlternativeReplica),
+					PreferredReplica:   types.StringValue(*setting.PreferredReplica),
 				}
 			}
-			if operation.AdvancedSettings.MssqlLogBackup != nil {
-				advSettings.MssqlLogBackup = []*replicaModel{
-					{
-						AlternativeReplica: types.StringValue(
-							*operation.AdvancedSettings.MssqlLogBackup.AlternativeReplica),
-						PreferredReplica: types.StringValue(
-							*operation.AdvancedSettings.MssqlLogBackup.PreferredReplica),
-					},
+			if setting := operation.AdvancedSettings.MssqlLogBackup; setting != nil {
+				advSettings.MssqlLogBackup = &replicaModel{
+					AlternativeReplica: types.StringValue(*setting.AlternativeReplica),
+					PreferredReplica:   types.StringValue(*setting.PreferredReplica),
 				}
 			}
-			if operation.AdvancedSettings.ProtectionGroupBackup != nil {
-				advSettings.ProtectionGroupBackup = []*backupTierModel{
-					{
-						BackupTier: types.StringValue(
-							*operation.AdvancedSettings.ProtectionGroupBackup.BackupTier),
-					},
+			if setting := operation.AdvancedSettings.ProtectionGroupBackup; setting != nil {
+				advSettings.ProtectionGroupBackup = &backupTierModel{
+					BackupTier: types.StringValue(*setting.BackupTier),
 				}
 			}
-			if operation.AdvancedSettings.AwsEbsVolumeBackup != nil {
-				advSettings.EBSVolumeBackup = []*backupTierModel{
-					{
-						BackupTier: types.StringValue(
-							*operation.AdvancedSettings.AwsEbsVolumeBackup.BackupTier),
-					},
+			if setting := operation.AdvancedSettings.AwsEbsVolumeBackup; setting != nil {
+				advSettings.EBSVolumeBackup = &backupTierModel{
+					BackupTier: types.StringValue(*setting.BackupTier),
 				}
 			}
-			if operation.AdvancedSettings.AwsEc2InstanceBackup != nil {
-				advSettings.EC2InstanceBackup = []*backupTierModel{
-					{
-						BackupTier: types.StringValue(
-							*operation.AdvancedSettings.AwsEc2InstanceBackup.BackupTier),
-					},
+			if setting := operation.AdvancedSettings.AwsEc2InstanceBackup; setting != nil {
+				advSettings.EC2InstanceBackup = &backupTierModel{
+					BackupTier: types.StringValue(*setting.BackupTier),
 				}
 			}
-			if operation.AdvancedSettings.AwsRdsConfigSync != nil {
-				advSettings.RDSPitrConfigSync = []*pitrConfigModel{
-					{
-						Apply: types.StringValue(
-							*operation.AdvancedSettings.AwsRdsConfigSync.Apply),
-					},
+			if setting := operation.AdvancedSettings.AwsRdsConfigSync; setting != nil {
+				advSettings.RDSPitrConfigSync = &pitrConfigModel{
+					Apply: types.StringValue(*setting.Apply),
 				}
 			}
-			if operation.AdvancedSettings.AwsRdsResourceGranularBackup != nil {
-				advSettings.RDSLogicalBackup = []*backupTierModel{
-					{
-						BackupTier: types.StringValue(
-							*operation.AdvancedSettings.AwsRdsResourceGranularBackup.BackupTier),
-					},
+			if setting := operation.AdvancedSettings.AwsRdsResourceGranularBackup; setting != nil {
+				advSettings.RDSLogicalBackup = &backupTierModel{
+					BackupTier: types.StringValue(*setting.BackupTier),
 				}
 			}
-			schemaOperation.AdvancedSettings = []*advancedSettingsModel{advSettings}
+			schemaOperation.AdvancedSettings = advSettings
 		}
 		schemaOperations = append(schemaOperations, schemaOperation)
 	}
@@ -891,90 +1001,66 @@ func mapClumioOperationsToSchemaOperations(ctx context.Context,
 // the advanced_settings from the schema.
 func getOperationAdvancedSettings(
 	operation *policyOperationModel) *models.PolicyAdvancedSettings {
-	var advancedSettings *models.PolicyAdvancedSettings
-	if operation.AdvancedSettings != nil {
-		advancedSettings = &models.PolicyAdvancedSettings{}
-		if operation.AdvancedSettings[0].EBSVolumeBackup != nil {
-			backupTier :=
-				operation.AdvancedSettings[0].EBSVolumeBackup[0].BackupTier.ValueString()
-			advancedSettings.AwsEbsVolumeBackup = &models.EBSBackupAdvancedSetting{
-				BackupTier: &backupTier,
-			}
+	settings := operation.AdvancedSettings
+	if settings == nil {
+		return nil
+	}
+	advancedSettings := &models.PolicyAdvancedSettings{}
+	if settings.EBSVolumeBackup != nil {
+		advancedSettings.AwsEbsVolumeBackup = &models.EBSBackupAdvancedSetting{
+			BackupTier: stringValuePtr(settings.EBSVolumeBackup.BackupTier),
 		}
-		if operation.AdvancedSettings[0].EC2InstanceBackup != nil {
-			backupTier :=
-				operation.AdvancedSettings[0].EC2InstanceBackup[0].BackupTier.ValueString()
-			advancedSettings.AwsEc2InstanceBackup = &models.EC2BackupAdvancedSetting{
-				BackupTier: &backupTier,
-			}
+	}
+	if settings.EC2InstanceBackup != nil {
+		advancedSettings.AwsEc2InstanceBackup = &models.EC2BackupAdvancedSetting{
+			BackupTier: stringValuePtr(settings.EC2InstanceBackup.BackupTier),
 		}
-		if operation.AdvancedSettings[0].ProtectionGroupBackup != nil {
-			backupTier :=
-				operation.AdvancedSettings[0].ProtectionGroupBackup[0].BackupTier.ValueString()
-			advancedSettings.ProtectionGroupBackup =
-				&models.ProtectionGroupBackupAdvancedSetting{
-					BackupTier: &backupTier,
-				}
+	}
+	if settings.ProtectionGroupBackup != nil {
+		advancedSettings.ProtectionGroupBackup = &models.ProtectionGroupBackupAdvancedSetting{
+			BackupTier: stringValuePtr(settings.ProtectionGroupBackup.BackupTier),
 		}
-		if operation.AdvancedSettings[0].EC2MssqlDatabaseBackup != nil {
-			alternativeReplica := operation.AdvancedSettings[0].EC2MssqlDatabaseBackup[0].
-				AlternativeReplica.ValueString()
-			preferredReplica := operation.AdvancedSettings[0].EC2MssqlDatabaseBackup[0].
-				PreferredReplica.ValueString()
-			advancedSettings.Ec2MssqlDatabaseBackup =
-				&models.EC2MSSQLDatabaseBackupAdvancedSetting{
-					AlternativeReplica: &alternativeReplica,
-					PreferredReplica:   &preferredReplica,
-				}
+	}
+	if settings.EC2MssqlDatabaseBackup != nil {
+		advancedSettings.Ec2MssqlDatabaseBackup = &models.EC2MSSQLDatabaseBackupAdvancedSetting{
+			AlternativeReplica: stringValuePtr(settings.EC2MssqlDatabaseBackup.AlternativeReplica),
+			PreferredReplica:   stringValuePtr(settings.EC2MssqlDatabaseBackup.PreferredReplica),
 		}
-		if operation.AdvancedSettings[0].EC2MssqlLogBackup != nil {
-			alternativeReplica := operation.AdvancedSettings[0].EC2MssqlLogBackup[0].
-				AlternativeReplica.ValueString()
-			preferredReplica := operation.AdvancedSettings[0].EC2MssqlLogBackup[0].
-				PreferredReplica.ValueString()
-			advancedSettings.Ec2MssqlLogBackup =
-				&models.EC2MSSQLLogBackupAdvancedSetting{
-					AlternativeReplica: &alternativeReplica,
-					PreferredReplica:   &preferredReplica,
-				}
+	}
+	if settings.EC2MssqlLogBackup != nil {
+		advancedSettings.Ec2MssqlLogBackup = &models.EC2MSSQLLogBackupAdvancedSetting{
+			AlternativeReplica: stringValuePtr(settings.EC2MssqlLogBackup.AlternativeReplica),
+			PreferredReplica:   stringValuePtr(settings.EC2MssqlLogBackup.PreferredReplica),
 		}
-		if operation.AdvancedSettings[0].MssqlDatabaseBackup != nil {
-			alternativeReplica := operation.AdvancedSettings[0].MssqlDatabaseBackup[0].
-				AlternativeReplica.ValueString()
-			preferredReplica := operation.AdvancedSettings[0].MssqlDatabaseBackup[0].
-				PreferredReplica.ValueString()
-			advancedSettings.MssqlDatabaseBackup =
-				&models.MSSQLDatabaseBackupAdvancedSetting{
-					AlternativeReplica: &alternativeReplica,
-					PreferredReplica:   &preferredReplica,
-				}
+	}
+	if settings.MssqlDatabaseBackup != nil {
+		advancedSettings.MssqlDatabaseBackup = &models.MSSQLDatabaseBackupAdvancedSetting{
+			AlternativeReplica: stringValuePtr(settings.MssqlDatabaseBackup.AlternativeReplica),
+			PreferredReplica:   stringValuePtr(settings.MssqlDatabaseBackup.PreferredReplica),
 		}
-		if operation.AdvancedSettings[0].MssqlLogBackup != nil {
-			alternativeReplica := operation.AdvancedSettings[0].MssqlLogBackup[0].
-				AlternativeReplica.ValueString()
-			preferredReplica := operation.AdvancedSettings[0].MssqlLogBackup[0].
-				PreferredReplica.ValueString()
-			advancedSettings.MssqlLogBackup =
-				&models.MSSQLLogBackupAdvancedSetting{
-					AlternativeReplica: &alternativeReplica,
-					PreferredReplica:   &preferredReplica,
-				}
+	}
+	if settings.MssqlLogBackup != nil {
+		advancedSettings.MssqlLogBackup = &models.MSSQLLogBackupAdvancedSetting{
+			AlternativeReplica: stringValuePtr(settings.MssqlLogBackup.AlternativeReplica),
+			PreferredReplica:   stringValuePtr(settings.MssqlLogBackup.PreferredReplica),
 		}
-		if operation.AdvancedSettings[0].RDSPitrConfigSync != nil {
-			apply := operation.AdvancedSettings[0].RDSPitrConfigSync[0].Apply.ValueString()
-			advancedSettings.AwsRdsConfigSync =
-				&models.RDSConfigSyncAdvancedSetting{
-					Apply: &apply,
-				}
+	}
+	if settings.RDSPitrConfigSync != nil {
+		advancedSettings.AwsRdsConfigSync = &models.RDSConfigSyncAdvancedSetting{
+			Apply: stringValuePtr(settings.RDSPitrConfigSync.Apply),
 		}
-		if operation.AdvancedSettings[0].RDSLogicalBackup != nil {
-			backupTier :=
-				operation.AdvancedSettings[0].RDSLogicalBackup[0].BackupTier.ValueString()
-			advancedSettings.AwsRdsResourceGranularBackup =
-				&models.RDSLogicalBackupAdvancedSetting{
-					BackupTier: &backupTier,
-				}
+	}
+	if settings.RDSLogicalBackup != nil {
+		advancedSettings.AwsRdsResourceGranularBackup = &models.RDSLogicalBackupAdvancedSetting{
+			BackupTier: stringValuePtr(settings.RDSLogicalBackup.BackupTier),
 		}
 	}
 	return advancedSettings
 }
+
+// stringValuePtr returns a pointer to the value of the string, which is empty if the string is
+// null.
+func stringValuePtr(v types.String) *string {
+	value := v.ValueString()
+	return &value
+}
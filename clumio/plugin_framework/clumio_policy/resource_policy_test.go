@@ -4,13 +4,17 @@
 package clumio_policy_test
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"regexp"
 	"testing"
+	"time"
 
+	policyDefinitions "github.com/clumio-code/clumio-go-sdk/controllers/policy_definitions"
 	clumio_pf "github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
+	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/mock_api"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
@@ -34,6 +38,12 @@ func TestAccResourceClumioPolicy(t *testing.T) {
 			{
 				Config: getTestAccResourceClumioPolicyWindow(true),
 			},
+			{
+				ResourceName:            "clumio_policy.test_policy",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"last_task_id"},
+			},
 			{
 				Config: getTestAccResourceClumioPolicySecureVaultLite(false),
 			},
@@ -60,12 +70,219 @@ func TestAccResourceClumioPolicy(t *testing.T) {
 			},
 			{
 				Config:      getTestAccResourceClumioPolicyBackupRegion(2),
-				ExpectError: regexp.MustCompile(".*Error running apply.*"),
+				ExpectError: regexp.MustCompile("(?s)Invalid Attribute Value Match.*AWS region"),
+			},
+			{
+				Config: getTestAccResourceClumioPolicyBackupRegion(1),
+				Check: clumio_pf.UtilTestCheckResourceDisappears(
+					"clumio_policy.test_policy", testAccDeletePolicy),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+// TestAccResourceClumioPolicyValidation tests that invalid configurations are rejected at
+// plan time, before reaching the Clumio API.
+func TestAccResourceClumioPolicyValidation(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { clumio_pf.UtilTestAccPreCheckClumio(t) },
+		ProtoV6ProviderFactories: clumio_pf.TestAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: getTestAccResourceClumioPolicyInvalid(
+					"UTC", "aws_ebs_volume_backup", "days", "01:00", ""),
+				PlanOnly: true,
+				ExpectError: regexp.MustCompile(
+					"(?s)Invalid advanced_settings.*only apply to the operations of"),
+			},
+			{
+				Config: getTestAccResourceClumioPolicyInvalid(
+					"UTC", "aws_ebs_volume_backups", "days", "01:00", ""),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("(?s)Invalid Attribute Value Match.*type"),
+			},
+			{
+				Config: getTestAccResourceClumioPolicyInvalid(
+					"UTC", "ec2_mssql_log_backup", "fortnights", "01:00", ""),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("(?s)Invalid Attribute Value Match.*unit"),
+			},
+			{
+				Config: getTestAccResourceClumioPolicyInvalid(
+					"UTC", "ec2_mssql_log_backup", "days", "25:00", ""),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("(?s)Invalid Attribute Value Match.*hh:mm"),
+			},
+			{
+				Config: getTestAccResourceClumioPolicyInvalid(
+					"UTC", "ec2_mssql_log_backup", "days", "01:00", "secondary"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("(?s)Invalid Attribute Value Match.*replica"),
+			},
+			{
+				Config: getTestAccResourceClumioPolicyInvalid(
+					"Mars/Olympus_Mons", "ec2_mssql_log_backup", "days", "01:00", ""),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("(?s)Invalid Attribute Value.*IANA time zone"),
+			},
+			{
+				Config: fmt.Sprintf(testAccResourceClumioPolicyDuplicateType,
+					os.Getenv(common.ClumioApiBaseUrl)),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("(?s)Duplicate operation type"),
+			},
+		},
+	})
+}
+
+// TestAccResourceClumioPolicySlaValidation tests that inconsistent SLAs are rejected at plan
+// time unless skip_sla_validation is set.
+func TestAccResourceClumioPolicySlaValidation(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { clumio_pf.UtilTestAccPreCheckClumio(t) },
+		ProtoV6ProviderFactories: clumio_pf.TestAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: getTestAccResourceClumioPolicySlas(false,
+					testAccPolicySla("days", 1, "weeks", 1, "")),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("(?s)Invalid SLA.*retention_duration of 1 days"),
+			},
+			{
+				Config: getTestAccResourceClumioPolicySlas(false,
+					testAccPolicySla("days", 7, "days", 1, "offsets = [1]")),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("(?s)Invalid SLA.*offsets only apply"),
+			},
+			{
+				Config: getTestAccResourceClumioPolicySlas(false,
+					testAccPolicySla("weeks", 4, "weeks", 1, "offsets = [7]")),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("(?s)Invalid SLA.*between 0 and 6"),
+			},
+			{
+				Config: getTestAccResourceClumioPolicySlas(false,
+					testAccPolicySla("days", 7, "on_demand", 1, "")),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("(?s)Invalid SLA.*has no value"),
+			},
+			{
+				Config: getTestAccResourceClumioPolicySlas(false,
+					testAccPolicySla("days", 7, "days", 1, "")+
+						testAccPolicySla("days", 14, "days", 1, "")),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("(?s)Invalid SLA.*same rpo_frequency"),
+			},
+			{
+				Config: getTestAccResourceClumioPolicySlas(true,
+					testAccPolicySla("days", 1, "weeks", 1, "")),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
 			},
 		},
 	})
 }
 
+// TestAccResourceClumioPolicyLocked tests that the update and deletion of a locked policy
+// fail at plan time unless wait_for_unlock is set, and that the plans leaving the policy
+// unchanged in Clumio are not checked. It requires the mock Clumio API, whose
+// policies stay locked until the task of their update completes.
+func TestAccResourceClumioPolicyLocked(t *testing.T) {
+	t.Cleanup(func() {
+		if clumio_pf.TestAccMockApiServer != nil {
+			clumio_pf.TestAccMockApiServer.SetTaskDuration(mock_api.DefaultTaskDuration)
+		}
+	})
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			clumio_pf.UtilTestAccPreCheckMockApi(t)
+			clumio_pf.UtilTestAccPreCheckClumio(t)
+		},
+		ProtoV6ProviderFactories: clumio_pf.TestAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      getTestAccResourceClumioPolicyLocked("lock-test-policy", "soon"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("(?s)Invalid Attribute Value.*positive duration"),
+			},
+			{
+				Config: getTestAccResourceClumioPolicyLocked("lock-test-policy", ""),
+			},
+			{
+				// The policy stays locked after this update.
+				PreConfig: func() {
+					clumio_pf.TestAccMockApiServer.SetTaskDuration(time.Hour)
+				},
+				Config: getTestAccResourceClumioPolicyLocked("lock-test-policy-1", ""),
+			},
+			{
+				Config:      getTestAccResourceClumioPolicyLocked("lock-test-policy-2", ""),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("(?s)Clumio policy is locked.*cannot be updated"),
+			},
+			{
+				Config:      testAccResourceClumioPolicyLockedProvider(),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("(?s)Clumio policy is locked.*cannot be deleted"),
+			},
+			{
+				// Changing the attributes known to Terraform alone sends nothing to the
+				// locked policy.
+				Config: getTestAccResourceClumioPolicyLocked("lock-test-policy-1", "2m"),
+				Check: resource.TestCheckResourceAttr(
+					"clumio_policy.test_policy", "wait_for_unlock", "2m"),
+			},
+			{
+				// The lock clears while the update waits for it.
+				PreConfig: func() {
+					clumio_pf.TestAccMockApiServer.SetTaskDuration(mock_api.DefaultTaskDuration)
+					time.AfterFunc(2*time.Second, clumio_pf.TestAccMockApiServer.CompleteTasks)
+				},
+				Config: getTestAccResourceClumioPolicyLocked("lock-test-policy-2", "2m"),
+				Check: resource.TestCheckResourceAttr(
+					"clumio_policy.test_policy", "name", "lock-test-policy-2"),
+			},
+		},
+	})
+}
+
+// TestAccResourceClumioPolicyUpgradeV0 tests that the state of a policy created by the last
+// release of the provider, whose operations are not declared in the order of their types, is
+// upgraded without planning any change.
+func TestAccResourceClumioPolicyUpgradeV0(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { clumio_pf.UtilTestAccPreCheckClumio(t) },
+		Steps: []resource.TestStep{
+			{
+				ExternalProviders: map[string]resource.ExternalProvider{
+					"clumio": {
+						Source:            "clumio-code/clumio",
+						VersionConstraint: "0.5.9",
+					},
+				},
+				Config: getTestAccResourceClumioPolicyUpgrade(),
+			},
+			{
+				ProtoV6ProviderFactories: clumio_pf.TestAccProtoV6ProviderFactories,
+				Config:                   getTestAccResourceClumioPolicyUpgrade(),
+				PlanOnly:                 true,
+			},
+		},
+	})
+}
+
+// testAccDeletePolicy deletes the policy outside of Terraform and waits for the deletion
+// task to complete.
+func testAccDeletePolicy(ctx context.Context, client *common.ApiClient, id string) error {
+	pd := policyDefinitions.NewPolicyDefinitionsV1(client.ClumioConfig())
+	res, apiErr := pd.DeletePolicyDefinition(id)
+	if apiErr != nil {
+		return common.NewAPIError(apiErr)
+	}
+	return common.PollTask(ctx, client, *res.TaskId, clumio_pf.TestAccTaskIntervalInSec)
+}
+
 func getTestAccResourceClumioPolicyWindow(update bool) string {
 	baseUrl := os.Getenv(common.ClumioApiBaseUrl)
 	name := "acceptance-test-policy-1234"
@@ -241,6 +458,65 @@ func getTestAccResourceClumioPolicyBackupRegion(scenario int) string {
 	return fmt.Sprintf(testAccResourceClumioPolicy, baseUrl, name, timezone, region)
 }
 
+// getTestAccResourceClumioPolicyInvalid returns the configuration of a policy with an
+// ec2_mssql_log_backup advanced settings block, built from the given values so that the
+// validation tests can make one of them invalid.
+func getTestAccResourceClumioPolicyUpgrade() string {
+	baseUrl := os.Getenv(common.ClumioApiBaseUrl)
+	return fmt.Sprintf(testAccResourceClumioPolicyUpgrade, baseUrl)
+}
+
+func getTestAccResourceClumioPolicyInvalid(
+	timezone, operationType, unit, startTime, preferredReplica string) string {
+	baseUrl := os.Getenv(common.ClumioApiBaseUrl)
+	if preferredReplica == "" {
+		preferredReplica = "primary"
+	}
+	return fmt.Sprintf(testAccResourceClumioPolicyInvalid, baseUrl, timezone, operationType,
+		startTime, unit, preferredReplica)
+}
+
+// getTestAccResourceClumioPolicySlas returns the configuration of a policy with the given
+// SLAs.
+func getTestAccResourceClumioPolicySlas(skipSlaValidation bool, slas string) string {
+	baseUrl := os.Getenv(common.ClumioApiBaseUrl)
+	return fmt.Sprintf(testAccResourceClumioPolicySlas, baseUrl, skipSlaValidation, slas)
+}
+
+// getTestAccResourceClumioPolicyLocked returns the configuration of a policy which does not
+// wait for the completion of its updates, so that it stays locked after them.
+func getTestAccResourceClumioPolicyLocked(name string, waitForUnlock string) string {
+	baseUrl := os.Getenv(common.ClumioApiBaseUrl)
+	waitForUnlockAttr := ""
+	if waitForUnlock != "" {
+		waitForUnlockAttr = fmt.Sprintf("wait_for_unlock = %q", waitForUnlock)
+	}
+	return fmt.Sprintf(testAccResourceClumioPolicyLocked, baseUrl, name, waitForUnlockAttr)
+}
+
+// testAccResourceClumioPolicyLockedProvider returns the configuration of the provider alone,
+// which plans the deletion of the policy.
+func testAccResourceClumioPolicyLockedProvider() string {
+	return fmt.Sprintf(testAccResourceClumioPolicyProvider, os.Getenv(common.ClumioApiBaseUrl))
+}
+
+// testAccPolicySla returns an slas block with the given retention and RPO.
+func testAccPolicySla(retentionUnit string, retentionValue int, rpoUnit string,
+	rpoValue int, rpoOffsets string) string {
+	return fmt.Sprintf(`
+		slas {
+			retention_duration {
+				unit = "%s"
+				value = %d
+			}
+			rpo_frequency {
+				unit = "%s"
+				value = %d
+				%s
+			}
+		}`, retentionUnit, retentionValue, rpoUnit, rpoValue, rpoOffsets)
+}
+
 const testAccResourceClumioPolicy = `
 provider clumio{
 	clumio_api_base_url = "%s"
@@ -659,3 +935,180 @@ resource "clumio_policy" "test_policy" {
 	}
 }
 `
+
+const testAccResourceClumioPolicyUpgrade = `
+provider clumio{
+	clumio_api_base_url = "%s"
+}
+
+resource "clumio_policy" "test_policy" {
+	name = "acceptance-test-policy-upgrade"
+	operations {
+		action_setting = "immediate"
+		type = "protection_group_backup"
+		slas {
+			retention_duration {
+				unit = "months"
+				value = 3
+			}
+			rpo_frequency {
+				unit = "days"
+				value = 2
+			}
+		}
+		advanced_settings {
+			protection_group_backup {
+				backup_tier = "cold"
+			}
+		}
+	}
+	operations {
+		action_setting = "window"
+		type = "aws_ebs_volume_backup"
+		backup_window_tz {
+			start_time = "01:00"
+			end_time = "05:00"
+		}
+		slas {
+			retention_duration {
+				unit = "days"
+				value = 30
+			}
+			rpo_frequency {
+				unit = "days"
+				value = 1
+			}
+		}
+		slas {
+			retention_duration {
+				unit = "months"
+				value = 3
+			}
+			rpo_frequency {
+				unit = "weeks"
+				value = 1
+				offsets = [1]
+			}
+		}
+	}
+}
+`
+
+const testAccResourceClumioPolicyInvalid = `
+provider clumio{
+	clumio_api_base_url = "%s"
+}
+
+resource "clumio_policy" "test_policy" {
+	name = "acceptance-test-policy-invalid"
+	timezone = "%s"
+	operations {
+		action_setting = "window"
+		type = "%s"
+		backup_window_tz {
+			start_time = "%s"
+		}
+		slas {
+			retention_duration {
+				unit = "%s"
+				value = 5
+			}
+			rpo_frequency {
+				unit = "days"
+				value = 1
+			}
+		}
+		advanced_settings {
+			ec2_mssql_log_backup {
+				preferred_replica = "%s"
+			}
+		}
+	}
+}
+`
+
+const testAccResourceClumioPolicyDuplicateType = `
+provider clumio{
+	clumio_api_base_url = "%s"
+}
+
+resource "clumio_policy" "test_policy" {
+	name = "acceptance-test-policy-duplicate-type"
+	operations {
+		action_setting = "immediate"
+		type = "aws_ebs_volume_backup"
+		slas {
+			retention_duration {
+				unit = "days"
+				value = 5
+			}
+			rpo_frequency {
+				unit = "days"
+				value = 1
+			}
+		}
+	}
+	operations {
+		action_setting = "window"
+		type = "aws_ebs_volume_backup"
+		slas {
+			retention_duration {
+				unit = "months"
+				value = 3
+			}
+			rpo_frequency {
+				unit = "weeks"
+				value = 1
+			}
+		}
+	}
+}
+`
+
+const testAccResourceClumioPolicySlas = `
+provider clumio{
+	clumio_api_base_url = "%s"
+}
+
+resource "clumio_policy" "test_policy" {
+	name = "acceptance-test-policy-slas"
+	skip_sla_validation = %t
+	operations {
+		action_setting = "immediate"
+		type = "aws_ebs_volume_backup"
+		%s
+	}
+}
+`
+
+const testAccResourceClumioPolicyLocked = `
+provider clumio{
+	clumio_api_base_url = "%s"
+}
+
+resource "clumio_policy" "test_policy" {
+	name = "%s"
+	wait_for_completion = false
+	%s
+	operations {
+		action_setting = "immediate"
+		type = "aws_ebs_volume_backup"
+		slas {
+			retention_duration {
+				unit = "days"
+				value = 5
+			}
+			rpo_frequency {
+				unit = "days"
+				value = 1
+			}
+		}
+	}
+}
+`
+
+const testAccResourceClumioPolicyProvider = `
+provider clumio{
+	clumio_api_base_url = "%s"
+}
+`
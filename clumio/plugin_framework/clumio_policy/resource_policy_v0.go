@@ -0,0 +1,255 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the version 0 of the clumio_policy schema, whose operations and their children
+// were sets of nested blocks, and the upgrade of its state to the current version.
+
+package clumio_policy
+
+import (
+	"context"
+
+	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type advancedSettingsModelV0 struct {
+	EC2MssqlDatabaseBackup []*replicaModel    `tfsdk:"ec2_mssql_database_backup"`
+	EC2MssqlLogBackup      []*replicaModel    `tfsdk:"ec2_mssql_log_backup"`
+	MssqlDatabaseBackup    []*replicaModel    `tfsdk:"mssql_database_backup"`
+	MssqlLogBackup         []*replicaModel    `tfsdk:"mssql_log_backup"`
+	ProtectionGroupBackup  []*backupTierModel `tfsdk:"protection_group_backup"`
+	EBSVolumeBackup        []*backupTierModel `tfsdk:"aws_ebs_volume_backup"`
+	EC2InstanceBackup      []*backupTierModel `tfsdk:"aws_ec2_instance_backup"`
+	RDSPitrConfigSync      []*pitrConfigModel `tfsdk:"aws_rds_config_sync"`
+	RDSLogicalBackup       []*backupTierModel `tfsdk:"aws_rds_resource_granular_backup"`
+}
+
+type policyOperationModelV0 struct {
+	ActionSetting    types.String               `tfsdk:"action_setting"`
+	OperationType    types.String               `tfsdk:"type"`
+	BackupWindowTz   []*backupWindowModel       `tfsdk:"backup_window_tz"`
+	Slas             []*slaModelV0              `tfsdk:"slas"`
+	AdvancedSettings []*advancedSettingsModelV0 `tfsdk:"advanced_settings"`
+	BackupAwsRegion  types.String               `tfsdk:"backup_aws_region"`
+}
+
+type slaModelV0 struct {
+	RetentionDuration []*unitValueModel `tfsdk:"retention_duration"`
+	RPOFrequency      []*rpoModel       `tfsdk:"rpo_frequency"`
+}
+
+type policyResourceModelV0 struct {
+	ID                   types.String              `tfsdk:"id"`
+	LockStatus           types.String              `tfsdk:"lock_status"`
+	Name                 types.String              `tfsdk:"name"`
+	Timezone             types.String              `tfsdk:"timezone"`
+	ActivationStatus     types.String              `tfsdk:"activation_status"`
+	OrganizationalUnitId types.String              `tfsdk:"organizational_unit_id"`
+	Operations           []*policyOperationModelV0 `tfsdk:"operations"`
+	WaitForCompletion    types.Bool                `tfsdk:"wait_for_completion"`
+	LastTaskID           types.String              `tfsdk:"last_task_id"`
+	FailOnTaskError      types.Bool                `tfsdk:"fail_on_task_error"`
+	SkipSlaValidation    types.Bool                `tfsdk:"skip_sla_validation"`
+	Timeouts             timeouts.Value            `tfsdk:"timeouts"`
+}
+
+// UpgradeState returns the upgraders of the prior versions of the state to the current one.
+func (r *policyResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	schemaV0 := policySchemaV0(ctx)
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema:   &schemaV0,
+			StateUpgrader: upgradePolicyStateV0,
+		},
+	}
+}
+
+// policySchemaV0 returns the version 0 of the schema. It only holds what is needed to read
+// the prior state.
+func policySchemaV0(ctx context.Context) schema.Schema {
+	replicaBlock := schema.SetNestedBlock{
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				schemaAlternativeReplica: schema.StringAttribute{Optional: true},
+				schemaPreferredReplica:   schema.StringAttribute{Optional: true},
+			},
+		},
+	}
+	backupTierBlock := schema.SetNestedBlock{
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				schemaBackupTier: schema.StringAttribute{Optional: true},
+			},
+		},
+	}
+	unitValueAttributes := map[string]schema.Attribute{
+		schemaUnit:  schema.StringAttribute{Required: true},
+		schemaValue: schema.Int64Attribute{Required: true},
+	}
+	rpoAttributes := map[string]schema.Attribute{
+		schemaUnit:  schema.StringAttribute{Required: true},
+		schemaValue: schema.Int64Attribute{Required: true},
+		schemaOffsets: schema.ListAttribute{
+			Optional:    true,
+			ElementType: types.Int64Type,
+		},
+	}
+
+	operationBlock := schema.SetNestedBlock{
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				schemaActionSetting:   schema.StringAttribute{Required: true},
+				schemaOperationType:   schema.StringAttribute{Required: true},
+				schemaBackupAwsRegion: schema.StringAttribute{Optional: true},
+			},
+			Blocks: map[string]schema.Block{
+				schemaBackupWindowTz: schema.SetNestedBlock{
+					NestedObject: schema.NestedBlockObject{
+						Attributes: map[string]schema.Attribute{
+							schemaStartTime: schema.StringAttribute{Optional: true},
+							schemaEndTime: schema.StringAttribute{
+								Optional: true,
+								Computed: true,
+							},
+						},
+					},
+				},
+				schemaAdvancedSettings: schema.SetNestedBlock{
+					NestedObject: schema.NestedBlockObject{
+						Blocks: map[string]schema.Block{
+							schemaEc2MssqlDatabaseBackup: replicaBlock,
+							schemaEc2MssqlLogBackup:      replicaBlock,
+							schemaMssqlDatabaseBackup:    replicaBlock,
+							schemaMssqlLogBackup:         replicaBlock,
+							schemaProtectionGroupBackup:  backupTierBlock,
+							schemaEBSVolumeBackup:        backupTierBlock,
+							schemaEC2InstanceBackup:      backupTierBlock,
+							schemaRDSPitrConfigSync: schema.SetNestedBlock{
+								NestedObject: schema.NestedBlockObject{
+									Attributes: map[string]schema.Attribute{
+										schemaApply: schema.StringAttribute{Optional: true},
+									},
+								},
+							},
+							schemaRdsLogicalBackup: backupTierBlock,
+						},
+					},
+				},
+				schemaSlas: schema.SetNestedBlock{
+					NestedObject: schema.NestedBlockObject{
+						Blocks: map[string]schema.Block{
+							schemaRetentionDuration: schema.SetNestedBlock{
+								NestedObject: schema.NestedBlockObject{
+									Attributes: unitValueAttributes,
+								},
+							},
+							schemaRpoFrequency: schema.SetNestedBlock{
+								NestedObject: schema.NestedBlockObject{
+									Attributes: rpoAttributes,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	return schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			schemaId:                   schema.StringAttribute{Computed: true},
+			schemaLockStatus:           schema.StringAttribute{Computed: true},
+			schemaName:                 schema.StringAttribute{Required: true},
+			schemaTimezone:             schema.StringAttribute{Optional: true, Computed: true},
+			schemaActivationStatus:     schema.StringAttribute{Optional: true, Computed: true},
+			schemaOrganizationalUnitId: schema.StringAttribute{Optional: true, Computed: true},
+			schemaWaitForCompletion:    schema.BoolAttribute{Optional: true, Computed: true},
+			schemaLastTaskId:           schema.StringAttribute{Computed: true},
+			schemaFailOnTaskError:      schema.BoolAttribute{Optional: true, Computed: true},
+			schemaSkipSlaValidation:    schema.BoolAttribute{Optional: true, Computed: true},
+		},
+		Blocks: map[string]schema.Block{
+			schemaTimeouts: timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Update: true,
+				Delete: true,
+			}),
+			schemaOperations: operationBlock,
+		},
+	}
+}
+
+// upgradePolicyStateV0 upgrades the version 0 of the state, turning the single elements of the
+// nested blocks of the operations into single nested blocks. It does not reach the Clumio API.
+func upgradePolicyStateV0(ctx context.Context, req resource.UpgradeStateRequest,
+	resp *resource.UpgradeStateResponse) {
+
+	var prior policyResourceModelV0
+	resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state := policyResourceModel{
+		ID:                   prior.ID,
+		LockStatus:           prior.LockStatus,
+		Name:                 prior.Name,
+		Timezone:             prior.Timezone,
+		ActivationStatus:     prior.ActivationStatus,
+		OrganizationalUnitId: prior.OrganizationalUnitId,
+		WaitForCompletion:    prior.WaitForCompletion,
+		LastTaskID:           prior.LastTaskID,
+		FailOnTaskError:      prior.FailOnTaskError,
+		SkipSlaValidation:    prior.SkipSlaValidation,
+		Timeouts:             prior.Timeouts,
+	}
+	// The attributes added to the version 0 over time are missing from the older states.
+	common.SetTaskAttributeDefaults(&state.WaitForCompletion, &state.FailOnTaskError)
+	if state.SkipSlaValidation.IsNull() {
+		state.SkipSlaValidation = types.BoolValue(false)
+	}
+
+	state.Operations = make([]*policyOperationModel, 0, len(prior.Operations))
+	for _, priorOperation := range prior.Operations {
+		operation := &policyOperationModel{
+			ActionSetting:   priorOperation.ActionSetting,
+			OperationType:   priorOperation.OperationType,
+			BackupWindowTz:  firstOrNil(priorOperation.BackupWindowTz),
+			BackupAwsRegion: priorOperation.BackupAwsRegion,
+		}
+		for _, priorSla := range priorOperation.Slas {
+			operation.Slas = append(operation.Slas, &slaModel{
+				RetentionDuration: firstOrNil(priorSla.RetentionDuration),
+				RPOFrequency:      firstOrNil(priorSla.RPOFrequency),
+			})
+		}
+		if len(priorOperation.AdvancedSettings) > 0 {
+			priorSettings := priorOperation.AdvancedSettings[0]
+			operation.AdvancedSettings = &advancedSettingsModel{
+				EC2MssqlDatabaseBackup: firstOrNil(priorSettings.EC2MssqlDatabaseBackup),
+				EC2MssqlLogBackup:      firstOrNil(priorSettings.EC2MssqlLogBackup),
+				MssqlDatabaseBackup:    firstOrNil(priorSettings.MssqlDatabaseBackup),
+				MssqlLogBackup:         firstOrNil(priorSettings.MssqlLogBackup),
+				ProtectionGroupBackup:  firstOrNil(priorSettings.ProtectionGroupBackup),
+				EBSVolumeBackup:        firstOrNil(priorSettings.EBSVolumeBackup),
+				EC2InstanceBackup:      firstOrNil(priorSettings.EC2InstanceBackup),
+				RDSPitrConfigSync:      firstOrNil(priorSettings.RDSPitrConfigSync),
+				RDSLogicalBackup:       firstOrNil(priorSettings.RDSLogicalBackup),
+			}
+		}
+		state.Operations = append(state.Operations, operation)
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+}
+
+// firstOrNil returns the first element of the single element of a version 0 nested block, or
+// nil if the block is unset.
+func firstOrNil[T any](elements []*T) *T {
+	if len(elements) == 0 {
+		return nil
+	}
+	return elements[0]
+}
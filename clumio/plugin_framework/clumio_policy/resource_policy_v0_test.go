@@ -0,0 +1,244 @@
+// Copyright 2024. Clumio, Inc.
+
+// Unit tests of the upgrade of the version 0 of the clumio_policy state.
+package clumio_policy_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/clumio_policy"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// testPolicyStateV0 is a version 0 state of a policy written before wait_for_completion and
+// skip_sla_validation were added.
+const testPolicyStateV0 = `{
+	"id": "policy-id",
+	"lock_status": "unlocked",
+	"name": "acceptance-test-policy",
+	"timezone": "UTC",
+	"activation_status": "activated",
+	"organizational_unit_id": "ou-id",
+	"operations": [
+		{
+			"action_setting": "window",
+			"type": "aws_ebs_volume_backup",
+			"backup_aws_region": "us-west-2",
+			"backup_window_tz": [{"start_time": "01:00", "end_time": "05:00"}],
+			"slas": [
+				{
+					"retention_duration": [{"unit": "days", "value": 30}],
+					"rpo_frequency": [{"unit": "days", "value": 1, "offsets": null}]
+				},
+				{
+					"retention_duration": [{"unit": "months", "value": 3}],
+					"rpo_frequency": [{"unit": "weeks", "value": 1, "offsets": [1]}]
+				}
+			],
+			"advanced_settings": [{"aws_ebs_volume_backup": [{"backup_tier": "lite"}]}]
+		},
+		{
+			"action_setting": "immediate",
+			"type": "%s",
+			"backup_aws_region": null,
+			"backup_window_tz": [],
+			"slas": [
+				{
+					"retention_duration": [{"unit": "months", "value": 3}],
+					"rpo_frequency": [{"unit": "days", "value": 2, "offsets": null}]
+				}
+			],
+			"advanced_settings": []
+		}
+	]
+}`
+
+// upgradePolicyStateV0 upgrades the version 0 state, in which the second operation has the
+// given type, to the current version.
+func upgradePolicyStateV0(t *testing.T, secondOperationType string) *resource.UpgradeStateResponse {
+	t.Helper()
+	ctx := context.Background()
+	r := clumio_policy.NewPolicyResource()
+	upgrader := r.(resource.ResourceWithUpgradeState).UpgradeState(ctx)[0]
+
+	priorType := upgrader.PriorSchema.Type().TerraformType(ctx)
+	raw, err := tftypes.ValueFromJSONWithOpts(
+		[]byte(strings.Replace(testPolicyStateV0, "%s", secondOperationType, 1)), priorType,
+		tftypes.ValueFromJSONOpts{IgnoreUndefinedAttributes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+	resp := &resource.UpgradeStateResponse{
+		State: tfsdk.State{
+			Schema: schemaResp.Schema,
+			Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+		},
+	}
+	upgrader.StateUpgrader(ctx, resource.UpgradeStateRequest{
+		State: &tfsdk.State{Schema: *upgrader.PriorSchema, Raw: raw},
+	}, resp)
+	return resp
+}
+
+// upgradedOperations returns the operations of the upgraded state by type.
+func upgradedOperations(t *testing.T, resp *resource.UpgradeStateResponse) map[string]attr.Value {
+	t.Helper()
+	var operations types.Set
+	resp.Diagnostics.Append(resp.State.GetAttribute(
+		context.Background(), path.Root("operations"), &operations)...)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("got diagnostics %v", resp.Diagnostics)
+	}
+	operationsByType := map[string]attr.Value{}
+	for _, operation := range operations.Elements() {
+		operationType := operation.(types.Object).Attributes()["type"].(types.String)
+		operationsByType[operationType.ValueString()] = operation
+	}
+	return operationsByType
+}
+
+func TestUpgradePolicyStateV0(t *testing.T) {
+	ctx := context.Background()
+	resp := upgradePolicyStateV0(t, "protection_group_backup")
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("got diagnostics %v", resp.Diagnostics)
+	}
+	operations := upgradedOperations(t, resp)
+	if len(operations) != 2 {
+		t.Fatalf("got the operations %v, want 2 operations", operations)
+	}
+
+	ebs := path.Root("operations").AtSetValue(operations["aws_ebs_volume_backup"])
+	for name, want := range map[string]struct {
+		path  path.Path
+		value string
+	}{
+		"action_setting":    {ebs.AtName("action_setting"), "window"},
+		"backup_aws_region": {ebs.AtName("backup_aws_region"), "us-west-2"},
+		"end_time":          {ebs.AtName("backup_window_tz").AtName("end_time"), "05:00"},
+		"backup_tier": {ebs.AtName("advanced_settings").AtName("aws_ebs_volume_backup").
+			AtName("backup_tier"), "lite"},
+		"id": {path.Root("id"), "policy-id"},
+	} {
+		var got types.String
+		resp.Diagnostics.Append(resp.State.GetAttribute(ctx, want.path, &got)...)
+		if got.ValueString() != want.value {
+			t.Errorf("got %s %q, want %q", name, got.ValueString(), want.value)
+		}
+	}
+
+	var slas types.Set
+	resp.Diagnostics.Append(resp.State.GetAttribute(ctx, ebs.AtName("slas"), &slas)...)
+	if len(slas.Elements()) != 2 {
+		t.Errorf("got %d SLAs, want 2", len(slas.Elements()))
+	}
+
+	pg := path.Root("operations").AtSetValue(operations["protection_group_backup"])
+	for _, nullPath := range []path.Path{
+		pg.AtName("backup_window_tz"), pg.AtName("advanced_settings"),
+	} {
+		var got types.Object
+		resp.Diagnostics.Append(resp.State.GetAttribute(ctx, nullPath, &got)...)
+		if !got.IsNull() {
+			t.Errorf("got %s %v, want null", nullPath, got)
+		}
+	}
+
+	for _, name := range []string{"wait_for_completion", "fail_on_task_error",
+		"skip_sla_validation"} {
+		var got types.Bool
+		resp.Diagnostics.Append(resp.State.GetAttribute(ctx, path.Root(name), &got)...)
+		if got.IsNull() {
+			t.Errorf("got a null %s, want its default", name)
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("got diagnostics %v", resp.Diagnostics)
+	}
+}
+
+// testPolicyConfigOperations are the operations of the configuration of the version 0 state
+// upgraded with an aws_dynamodb_table_backup operation, in another order than the one of the
+// state.
+const testPolicyConfigOperations = `[
+	{
+		"action_setting": "immediate",
+		"type": "aws_dynamodb_table_backup",
+		"backup_aws_region": null,
+		"backup_window_tz": null,
+		"slas": [
+			{
+				"retention_duration": {"unit": "months", "value": 3},
+				"rpo_frequency": {"unit": "days", "value": 2, "offsets": null}
+			}
+		],
+		"advanced_settings": null
+	},
+	{
+		"action_setting": "window",
+		"type": "aws_ebs_volume_backup",
+		"backup_aws_region": "us-west-2",
+		"backup_window_tz": {"start_time": "01:00", "end_time": "05:00"},
+		"slas": [
+			{
+				"retention_duration": {"unit": "months", "value": 3},
+				"rpo_frequency": {"unit": "weeks", "value": 1, "offsets": [1]}
+			},
+			{
+				"retention_duration": {"unit": "days", "value": 30},
+				"rpo_frequency": {"unit": "days", "value": 1, "offsets": null}
+			}
+		],
+		"advanced_settings": {
+			"ec2_mssql_database_backup": null,
+			"ec2_mssql_log_backup": null,
+			"mssql_database_backup": null,
+			"mssql_log_backup": null,
+			"protection_group_backup": null,
+			"aws_ebs_volume_backup": {"backup_tier": "lite"},
+			"aws_ec2_instance_backup": null,
+			"aws_rds_config_sync": null,
+			"aws_rds_resource_granular_backup": null
+		}
+	}
+]`
+
+// TestUpgradePolicyStateV0PlansNoChange tests that the operations upgraded from a version 0
+// state, which are not in the order of their types, are equal to the ones of the configuration
+// declared in another order, so that the plan following the upgrade is empty.
+func TestUpgradePolicyStateV0PlansNoChange(t *testing.T) {
+	ctx := context.Background()
+	resp := upgradePolicyStateV0(t, "aws_dynamodb_table_backup")
+	var upgraded types.Set
+	resp.Diagnostics.Append(resp.State.GetAttribute(
+		ctx, path.Root("operations"), &upgraded)...)
+	operationsType, diags := resp.State.Schema.TypeAtPath(ctx, path.Root("operations"))
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("got diagnostics %v", resp.Diagnostics)
+	}
+
+	raw, err := tftypes.ValueFromJSONWithOpts([]byte(testPolicyConfigOperations),
+		operationsType.TerraformType(ctx), tftypes.ValueFromJSONOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := operationsType.ValueFromTerraform(ctx, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !upgraded.Equal(config) {
+		t.Errorf("got the upgraded operations %v, want the ones of the configuration %v",
+			upgraded, config)
+	}
+}
@@ -0,0 +1,359 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the validation of the clumio_policy resource configuration at plan time.
+
+package clumio_policy
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+	// Embeds the IANA Time Zone database so that the time zones are validated the same way
+	// regardless of the time zones installed on the host running Terraform.
+	_ "time/tzdata"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+var (
+	// operationTypes are the types of the operations of a policy.
+	operationTypes = []string{
+		operationProtectionGroupBackup,
+		operationProtectionGroupContinuous,
+		operationEBSVolumeBackup,
+		operationEBSVolumeSnapshot,
+		operationEC2InstanceBackup,
+		operationEC2InstanceSnapshot,
+		operationRDSAwsSnapshot,
+		operationRDSRollingBackup,
+		operationRDSGranularBackup,
+		operationDynamoDBTableSnapshot,
+		operationDynamoDBTableBackup,
+		operationEC2MssqlDatabaseBackup,
+		operationEC2MssqlLogBackup,
+		operationMssqlDatabaseBackup,
+		operationMssqlLogBackup,
+	}
+
+	// retentionUnits are the units of the retention_duration of an SLA.
+	retentionUnits = []string{unitHours, unitDays, unitWeeks, unitMonths, unitYears}
+
+	// rpoUnits are the units of the rpo_frequency of an SLA.
+	rpoUnits = []string{
+		unitMinutes, unitHours, unitDays, unitWeeks, unitMonths, unitYears, unitOnDemand,
+	}
+
+	// advancedSettingsOperationTypes maps each block of the advanced_settings to the type of
+	// the operations it applies to.
+	advancedSettingsOperationTypes = map[string]string{
+		schemaEc2MssqlDatabaseBackup: operationEC2MssqlDatabaseBackup,
+		schemaEc2MssqlLogBackup:      operationEC2MssqlLogBackup,
+		schemaMssqlDatabaseBackup:    operationMssqlDatabaseBackup,
+		schemaMssqlLogBackup:         operationMssqlLogBackup,
+		schemaProtectionGroupBackup:  operationProtectionGroupBackup,
+		schemaEBSVolumeBackup:        operationEBSVolumeBackup,
+		schemaEC2InstanceBackup:      operationEC2InstanceBackup,
+		schemaRDSPitrConfigSync:      operationRDSAwsSnapshot,
+		schemaRdsLogicalBackup:       operationRDSGranularBackup,
+	}
+
+	// unitDurationsInMinutes are the durations in minutes of the units of the SLAs, to
+	// compare the retention and the RPO of an SLA. A month is the twelfth of a year.
+	unitDurationsInMinutes = map[string]int64{
+		unitMinutes: 1,
+		unitHours:   60,
+		unitDays:    24 * 60,
+		unitWeeks:   7 * 24 * 60,
+		unitMonths:  365 * 24 * 60 / 12,
+		unitYears:   365 * 24 * 60,
+	}
+
+	// offsetRanges are the ranges of the offsets of the RPOs with an offset: the day of the
+	// week from Sunday (0) to Saturday (6), and the day of the month.
+	offsetRanges = map[string][2]int64{
+		unitWeeks:  {0, 6},
+		unitMonths: {1, 31},
+	}
+
+	// timeOfDayRegex matches the hh:mm times of the backup windows.
+	timeOfDayRegex = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+
+	// optionalTimeOfDayRegex matches the hh:mm times of the backup windows, or an empty
+	// string.
+	optionalTimeOfDayRegex = regexp.MustCompile(`^(([01][0-9]|2[0-3]):[0-5][0-9])?$`)
+
+	// awsRegionRegex matches the names of the AWS regions, such as us-west-2 or
+	// us-gov-east-1.
+	awsRegionRegex = regexp.MustCompile(`^[a-z]{2}(-gov|-iso[a-z]?)?-[a-z]+-[0-9]+$`)
+)
+
+// timezoneValidator validates that a string is a time zone of the IANA Time Zone database.
+// An empty string is accepted as the time zone is Computed.
+type timezoneValidator struct{}
+
+// Description returns a plain text description of the validator's behavior.
+func (v timezoneValidator) Description(_ context.Context) string {
+	return "value must be an IANA time zone, such as America/Los_Angeles or Etc/UTC"
+}
+
+// MarkdownDescription returns a markdown formatted description of the validator's behavior.
+func (v timezoneValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString performs the validation.
+func (v timezoneValidator) ValidateString(
+	ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	timezone := req.ConfigValue.ValueString()
+	if timezone == "" {
+		return
+	}
+	// LoadLocation also accepts Local, the time zone of the host, which is not an IANA
+	// time zone.
+	if _, err := time.LoadLocation(timezone); err != nil || timezone == "Local" {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid Attribute Value",
+			fmt.Sprintf("Attribute %s %s, got: %q", req.Path, v.Description(ctx), timezone))
+	}
+}
+
+// durationValidator validates that a string is a positive duration, such as 10m.
+type durationValidator struct{}
+
+// Description returns a plain text description of the validator's behavior.
+func (v durationValidator) Description(_ context.Context) string {
+	return "value must be a positive duration, such as 30s or 10m"
+}
+
+// MarkdownDescription returns a markdown formatted description of the validator's behavior.
+func (v durationValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString performs the validation.
+func (v durationValidator) ValidateString(
+	ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if d, err := time.ParseDuration(req.ConfigValue.ValueString()); err != nil || d <= 0 {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid Attribute Value",
+			fmt.Sprintf("Attribute %s %s, got: %q", req.Path, v.Description(ctx),
+				req.ConfigValue.ValueString()))
+	}
+}
+
+// regexpValidator validates that a string is a regular expression in RE2 syntax.
+type regexpValidator struct{}
+
+// Description returns a plain text description of the validator's behavior.
+func (v regexpValidator) Description(_ context.Context) string {
+	return "value must be a regular expression in RE2 syntax"
+}
+
+// MarkdownDescription returns a markdown formatted description of the validator's behavior.
+func (v regexpValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString performs the validation.
+func (v regexpValidator) ValidateString(
+	ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if _, err := regexp.Compile(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid Attribute Value",
+			fmt.Sprintf("Attribute %s %s, got: %q: %v", req.Path, v.Description(ctx),
+				req.ConfigValue.ValueString(), err))
+	}
+}
+
+// ValidateConfig validates that each type is used by a single operation, that the
+// advanced_settings of each operation apply to the type of the operation, and that the SLAs of
+// the operations are consistent unless skip_sla_validation is set.
+func (r *policyResource) ValidateConfig(
+	ctx context.Context, req resource.ValidateConfigRequest,
+	resp *resource.ValidateConfigResponse) {
+
+	var operations types.Set
+	resp.Diagnostics.Append(req.Config.GetAttribute(
+		ctx, path.Root(schemaOperations), &operations)...)
+	var skipSlaValidation types.Bool
+	resp.Diagnostics.Append(req.Config.GetAttribute(
+		ctx, path.Root(schemaSkipSlaValidation), &skipSlaValidation)...)
+	if resp.Diagnostics.HasError() || operations.IsNull() || operations.IsUnknown() {
+		return
+	}
+	// The SLAs are validated once it is known whether the checks are skipped.
+	validateSlas := !skipSlaValidation.IsUnknown() && !skipSlaValidation.ValueBool()
+	seenTypes := map[string]bool{}
+	for _, operation := range operations.Elements() {
+		operationPath := path.Root(schemaOperations).AtSetValue(operation)
+		operationAttrs := knownObjectAttributes(operation)
+		if validateSlas {
+			if slas, ok := operationAttrs[schemaSlas].(types.Set); ok {
+				validateOperationSlas(ctx, operationPath, slas, &resp.Diagnostics)
+			}
+		}
+		operationTypeValue, ok := operationAttrs[schemaOperationType].(types.String)
+		if !ok || operationTypeValue.IsNull() || operationTypeValue.IsUnknown() {
+			continue
+		}
+		operationType := operationTypeValue.ValueString()
+		if seenTypes[operationType] {
+			resp.Diagnostics.AddAttributeError(operationPath.AtName(schemaOperationType),
+				"Duplicate operation type",
+				fmt.Sprintf("Another operation has the same type %s. A policy has a single"+
+					" operation of each type; merge the SLAs of the operations into one of"+
+					" them.", operationType))
+			continue
+		}
+		seenTypes[operationType] = true
+		advancedSettings := knownObjectAttributes(operationAttrs[schemaAdvancedSettings])
+		for name, settings := range advancedSettings {
+			if settings.IsNull() {
+				continue
+			}
+			allowedType := advancedSettingsOperationTypes[name]
+			if allowedType == "" || allowedType == operationType {
+				continue
+			}
+			resp.Diagnostics.AddAttributeError(
+				operationPath.AtName(schemaAdvancedSettings).AtName(name),
+				"Invalid advanced_settings",
+				fmt.Sprintf("The %s advanced settings only apply to the operations of"+
+					" type %s, not to an operation of type %s. Remove the %s settings or"+
+					" change the type of the operation.", name, allowedType, operationType,
+					name))
+		}
+	}
+}
+
+// validateOperationSlas validates the SLAs of the operation at the given path: the backups
+// must be retained at least until the next one, the offsets must be days of the week or of
+// the month of a weekly or monthly RPO, an on_demand RPO has no value, and two SLAs cannot
+// have the same RPO. The SLAs whose values are not known yet are skipped.
+func validateOperationSlas(ctx context.Context, operationPath path.Path, slas types.Set,
+	diags *diag.Diagnostics) {
+
+	rpoPaths := map[string]path.Path{}
+	for _, slaValue := range slas.Elements() {
+		slaObject, ok := slaValue.(types.Object)
+		if !ok || slaObject.IsNull() || slaObject.IsUnknown() {
+			continue
+		}
+		// The SLAs with an unknown retention_duration or rpo_frequency cannot be converted
+		// and are validated once known.
+		var sla slaModel
+		if slaObject.As(ctx, &sla, basetypes.ObjectAsOptions{}).HasError() ||
+			sla.RetentionDuration == nil || sla.RPOFrequency == nil {
+			continue
+		}
+		slaPath := operationPath.AtName(schemaSlas).AtSetValue(slaValue)
+		retentionPath := slaPath.AtName(schemaRetentionDuration)
+		rpoPath := slaPath.AtName(schemaRpoFrequency)
+		retention, rpo := sla.RetentionDuration, sla.RPOFrequency
+		if rpo.Unit.IsUnknown() {
+			continue
+		}
+		rpoUnit := rpo.Unit.ValueString()
+
+		if rpoUnit == unitOnDemand && !rpo.Value.IsUnknown() && rpo.Value.ValueInt64() != 0 {
+			diags.AddAttributeError(rpoPath.AtName(schemaValue), invalidSlaSummary,
+				fmt.Sprintf("An rpo_frequency with unit %s has no value, got: %d. Set"+
+					" the value to 0.", unitOnDemand, rpo.Value.ValueInt64())+slaOptOutDetail)
+		}
+
+		offsets, offsetsKnown := validateRpoOffsets(ctx, rpoPath, rpo, diags)
+
+		retentionMinutes, retentionOk := unitDurationsInMinutes[retention.Unit.ValueString()]
+		rpoMinutes, rpoOk := unitDurationsInMinutes[rpoUnit]
+		if retentionOk && rpoOk && !retention.Value.IsUnknown() && !rpo.Value.IsUnknown() &&
+			retention.Value.ValueInt64()*retentionMinutes < rpo.Value.ValueInt64()*rpoMinutes {
+			diags.AddAttributeError(retentionPath, invalidSlaSummary,
+				fmt.Sprintf("The retention_duration of %d %s is shorter than the"+
+					" rpo_frequency of %d %s of the SLA, so that each backup would expire"+
+					" before the next one is taken.", retention.Value.ValueInt64(),
+					retention.Unit.ValueString(), rpo.Value.ValueInt64(), rpoUnit)+
+					slaOptOutDetail)
+		}
+
+		if rpo.Value.IsUnknown() || !offsetsKnown {
+			continue
+		}
+		rpoKey := fmt.Sprintf("%d %s %v", rpo.Value.ValueInt64(), rpoUnit, offsets)
+		if _, ok := rpoPaths[rpoKey]; ok {
+			diags.AddAttributeError(rpoPath, invalidSlaSummary,
+				fmt.Sprintf("Another SLA of the operation has the same rpo_frequency of"+
+					" %d %s. Merge the SLAs into the one with the longest"+
+					" retention_duration.", rpo.Value.ValueInt64(), rpoUnit)+slaOptOutDetail)
+			continue
+		}
+		rpoPaths[rpoKey] = rpoPath
+	}
+}
+
+// validateRpoOffsets validates the offsets of the RPO at the given path and returns them,
+// along with whether they are all known.
+func validateRpoOffsets(ctx context.Context, rpoPath path.Path, rpo *rpoModel,
+	diags *diag.Diagnostics) ([]int64, bool) {
+
+	if rpo.Offsets.IsUnknown() {
+		return nil, false
+	}
+	var offsets []types.Int64
+	if rpo.Offsets.ElementsAs(ctx, &offsets, false).HasError() {
+		return nil, false
+	}
+	if len(offsets) == 0 {
+		return nil, true
+	}
+	offsetsPath := rpoPath.AtName(schemaOffsets)
+	offsetRange, ok := offsetRanges[rpo.Unit.ValueString()]
+	if !ok {
+		diags.AddAttributeError(offsetsPath, invalidSlaSummary,
+			fmt.Sprintf("The offsets only apply to an rpo_frequency with unit %s or %s,"+
+				" not %s.", unitWeeks, unitMonths, rpo.Unit.ValueString())+slaOptOutDetail)
+		return nil, false
+	}
+	values := make([]int64, 0, len(offsets))
+	for i, offset := range offsets {
+		if offset.IsUnknown() || offset.IsNull() {
+			return nil, false
+		}
+		value := offset.ValueInt64()
+		if value < offsetRange[0] || value > offsetRange[1] {
+			diags.AddAttributeError(offsetsPath.AtListIndex(i), invalidSlaSummary,
+				fmt.Sprintf("The offsets of an rpo_frequency with unit %s must be between"+
+					" %d and %d, got: %d.", rpo.Unit.ValueString(), offsetRange[0],
+					offsetRange[1], value)+slaOptOutDetail)
+		}
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	return values, true
+}
+
+// knownObjectAttributes returns the attributes of the value if it is a known object, and
+// nil otherwise.
+func knownObjectAttributes(value attr.Value) map[string]attr.Value {
+	object, ok := value.(types.Object)
+	if !ok || object.IsNull() || object.IsUnknown() {
+		return nil
+	}
+	return object.Attributes()
+}
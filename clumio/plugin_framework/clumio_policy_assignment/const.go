@@ -4,6 +4,10 @@ package clumio_policy_assignment
 
 const (
 	schemaId                   = "id"
+	schemaTimeouts             = "timeouts"
+	schemaWaitForCompletion    = "wait_for_completion"
+	schemaLastTaskId           = "last_task_id"
+	schemaFailOnTaskError      = "fail_on_task_error"
 	schemaEntityId             = "entity_id"
 	schemaEntityType           = "entity_type"
 	schemaPolicyId             = "policy_id"
@@ -12,7 +16,6 @@ const (
 	entityTypeProtectionGroup = "protection_group"
 	protectionGroupBackup     = "protection_group_backup"
 
-	timeoutInSec  = 3600
 	intervalInSec = 5
 
 	errorFmt = "Error: %v"
@@ -15,6 +15,7 @@ import (
 	"github.com/clumio-code/clumio-go-sdk/models"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	validators "github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -41,16 +42,20 @@ func NewPolicyAssignmentResource() resource.Resource {
 }
 
 type policyAssignmentResourceModel struct {
-	ID                   types.String `tfsdk:"id"`
-	EntityID             types.String `tfsdk:"entity_id"`
-	EntityType           types.String `tfsdk:"entity_type"`
-	PolicyID             types.String `tfsdk:"policy_id"`
-	OrganizationalUnitID types.String `tfsdk:"organizational_unit_id"`
+	ID                   types.String   `tfsdk:"id"`
+	EntityID             types.String   `tfsdk:"entity_id"`
+	EntityType           types.String   `tfsdk:"entity_type"`
+	PolicyID             types.String   `tfsdk:"policy_id"`
+	OrganizationalUnitID types.String   `tfsdk:"organizational_unit_id"`
+	WaitForCompletion    types.Bool     `tfsdk:"wait_for_completion"`
+	LastTaskID           types.String   `tfsdk:"last_task_id"`
+	FailOnTaskError      types.Bool     `tfsdk:"fail_on_task_error"`
+	Timeouts             timeouts.Value `tfsdk:"timeouts"`
 }
 
 // Schema defines the schema for the data source.
 func (r *clumioPolicyAssignmentResource) Schema(
-	_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		// This description is used by the documentation generator and the language server.
 		Description: "Clumio Policy Assignment Resource used to assign (or unassign)" +
@@ -88,6 +93,16 @@ func (r *clumioPolicyAssignmentResource) Schema(
 				Optional: true,
 				Computed: true,
 			},
+			schemaWaitForCompletion: common.WaitForCompletionAttribute(),
+			schemaLastTaskId:        common.LastTaskIdAttribute(),
+			schemaFailOnTaskError:   common.FailOnTaskErrorAttribute(),
+		},
+		Blocks: map[string]schema.Block{
+			schemaTimeouts: timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -124,21 +139,24 @@ func (r *clumioPolicyAssignmentResource) Create(
 		return
 	}
 
-	if plan.OrganizationalUnitID.ValueString() != "" {
-		r.client.ClumioConfig.OrganizationalUnitContext =
-			plan.OrganizationalUnitID.ValueString()
-		defer r.clearOUContext()
+	createTimeout, diags := plan.Timeouts.Create(ctx, r.client.Timeouts().Create)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
 
-	pa := policyAssignments.NewPolicyAssignmentsV1(r.client.ClumioConfig)
+	client := r.client.WithOrganizationalUnit(plan.OrganizationalUnitID.ValueString())
+
+	pa := policyAssignments.NewPolicyAssignmentsV1(client.WithContext(ctx).ClumioConfig())
 	// Validation to check if the policy id mentioned supports protection_group_backup operation.
-	pdv1 := policyDefinitions.NewPolicyDefinitionsV1(r.client.ClumioConfig)
+	pdv1 := policyDefinitions.NewPolicyDefinitionsV1(client.WithContext(ctx).ClumioConfig())
 	policyId := plan.PolicyID.ValueString()
 	policy, apiErr := pdv1.ReadPolicyDefinition(policyId, nil)
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
-			fmt.Sprintf("Error reading the policy with id : %v", policyId),
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			fmt.Sprintf("Error reading the policy with id : %v", policyId), apiErr, nil)...)
 		return
 	}
 	correctPolicyType := false
@@ -159,33 +177,35 @@ func (r *clumioPolicyAssignmentResource) Create(
 	res, apiErr := pa.SetPolicyAssignments(paRequest)
 	assignment := paRequest.Items[0]
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
 			fmt.Sprintf("Error assigning policy %v to entity %v", policyId,
-				*assignment.Entity.Id),
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+				*assignment.Entity.Id), apiErr, nil)...)
 		return
 	}
-	err := common.PollTask(ctx, r.client, *res.TaskId, timeoutInSec, intervalInSec)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			fmt.Sprintf("Error assigning policy %v to entity %v.", policyId,
-				*assignment.Entity.Id),
-			fmt.Sprintf(errorFmt, apiErr.Response))
-		return
+	plan.LastTaskID = types.StringValue(*res.TaskId)
+	if plan.WaitForCompletion.ValueBool() {
+		err := common.PollTask(ctx, client, *res.TaskId, intervalInSec)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				fmt.Sprintf("Error assigning policy %v to entity %v.", policyId,
+					*assignment.Entity.Id),
+				fmt.Sprintf(errorFmt, err))
+			return
+		}
 	}
 	entityType := plan.EntityType.ValueString()
 	plan.ID = types.StringValue(
 		fmt.Sprintf("%s_%s_%s", *assignment.PolicyId, *assignment.Entity.Id, entityType))
-	protectionGroup := protectionGroups.NewProtectionGroupsV1(r.client.ClumioConfig)
+	protectionGroup := protectionGroups.NewProtectionGroupsV1(client.WithContext(ctx).ClumioConfig())
 	readResponse, apiErr := protectionGroup.ReadProtectionGroup(*assignment.Entity.Id)
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
 			fmt.Sprintf(
-				"Error reading Protection Group %v.", *assignment.Entity.Id),
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+				"Error reading Protection Group %v.", *assignment.Entity.Id), apiErr, nil)...)
 		return
 	}
-	if *readResponse.ProtectionInfo.PolicyId != policyId {
+	// The policy is only applied once the task completes.
+	if plan.WaitForCompletion.ValueBool() && *readResponse.ProtectionInfo.PolicyId != policyId {
 		errMsg := fmt.Sprintf(
 			"Protection group with id: %s does not have policy %s applied",
 			*assignment.Entity.Id, policyId)
@@ -210,34 +230,44 @@ func (r *clumioPolicyAssignmentResource) Read(
 		return
 	}
 
-	if state.OrganizationalUnitID.ValueString() != "" {
-		r.client.ClumioConfig.OrganizationalUnitContext =
-			state.OrganizationalUnitID.ValueString()
-		defer r.clearOUContext()
-	}
+	client := r.client.WithOrganizationalUnit(state.OrganizationalUnitID.ValueString())
 
 	idSplits := strings.Split(state.ID.ValueString(), "_")
 	if len(idSplits) < 3 {
 		resp.Diagnostics.AddError("Invalid ID.",
 			fmt.Sprintf("Invalid id %s for policy_assignment", state.ID.ValueString()))
+		return
 	}
 	policyId, entityId, entityType :=
 		idSplits[0], idSplits[1], strings.Join(idSplits[2:], "_")
+	common.SetTaskAttributeDefaults(&state.WaitForCompletion, &state.FailOnTaskError)
+	pending, diags := common.LastTaskDiagnostics(ctx, client, resp.Private,
+		state.WaitForCompletion, state.LastTaskID, state.FailOnTaskError,
+		fmt.Sprintf("the assignment of policy %s to %s %s", policyId, entityType, entityId))
+	resp.Diagnostics.Append(diags...)
 	switch entityType {
 	case entityTypeProtectionGroup:
-		protectionGroup := protectionGroups.NewProtectionGroupsV1(r.client.ClumioConfig)
+		protectionGroup := protectionGroups.NewProtectionGroupsV1(client.WithContext(ctx).ClumioConfig())
+		assignment := fmt.Sprintf(
+			"Clumio policy assignment of policy %s to protection group %s", policyId, entityId)
 		readResponse, apiErr := protectionGroup.ReadProtectionGroup(entityId)
 		if apiErr != nil {
-			resp.Diagnostics.AddError(
+			if common.NewAPIError(apiErr).IsNotFound() {
+				common.RemoveMissingResource(ctx, resp, assignment)
+				return
+			}
+			resp.Diagnostics.Append(common.APIErrorDiagnostics(
 				fmt.Sprintf(
-					"Error reading Protection Group %v.", entityId),
-				fmt.Sprintf(errorFmt, string(apiErr.Response)))
+					"Error reading Protection Group %v.", entityId), apiErr, nil)...)
 			return
 		}
-		if *readResponse.ProtectionInfo.PolicyId != policyId {
-			errMsg := fmt.Sprintf("Protection group with id: %s does not have policy %s applied",
-				entityId, policyId)
-			resp.Diagnostics.AddError(errMsg, errMsg)
+		// The assignment no longer exists if the policy was removed from the protection
+		// group or replaced by another one outside of Terraform. While the task of the
+		// assignment is pending, the policy may not be applied yet.
+		if !pending && (readResponse.ProtectionInfo == nil ||
+			readResponse.ProtectionInfo.PolicyId == nil ||
+			*readResponse.ProtectionInfo.PolicyId != policyId) {
+			common.RemoveMissingResource(ctx, resp, assignment)
 			return
 		}
 		state.PolicyID = types.StringValue(policyId)
@@ -266,21 +296,24 @@ func (r *clumioPolicyAssignmentResource) Update(
 		return
 	}
 
-	if plan.OrganizationalUnitID.ValueString() != "" {
-		r.client.ClumioConfig.OrganizationalUnitContext =
-			plan.OrganizationalUnitID.ValueString()
-		defer r.clearOUContext()
+	updateTimeout, diags := plan.Timeouts.Update(ctx, r.client.Timeouts().Update)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
 
-	pa := policyAssignments.NewPolicyAssignmentsV1(r.client.ClumioConfig)
+	client := r.client.WithOrganizationalUnit(plan.OrganizationalUnitID.ValueString())
+
+	pa := policyAssignments.NewPolicyAssignmentsV1(client.WithContext(ctx).ClumioConfig())
 	// Validation to check if the policy id mentioned supports protection_group_backup operation.
-	pdv1 := policyDefinitions.NewPolicyDefinitionsV1(r.client.ClumioConfig)
+	pdv1 := policyDefinitions.NewPolicyDefinitionsV1(client.WithContext(ctx).ClumioConfig())
 	policyId := plan.PolicyID.ValueString()
 	policy, apiErr := pdv1.ReadPolicyDefinition(policyId, nil)
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
-			fmt.Sprintf("Error reading the policy with id : %v", policyId),
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			fmt.Sprintf("Error reading the policy with id : %v", policyId), apiErr, nil)...)
 		return
 	}
 	correctPolicyType := false
@@ -301,31 +334,33 @@ func (r *clumioPolicyAssignmentResource) Update(
 	res, apiErr := pa.SetPolicyAssignments(paRequest)
 	assignment := paRequest.Items[0]
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
 			fmt.Sprintf("Error assigning policy %v to entity %v", policyId,
-				*assignment.Entity.Id),
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+				*assignment.Entity.Id), apiErr, nil)...)
 		return
 	}
 
-	err := common.PollTask(ctx, r.client, *res.TaskId, timeoutInSec, intervalInSec)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			fmt.Sprintf("Error assigning policy %v to entity %v.", policyId,
-				*assignment.Entity.Id),
-			fmt.Sprintf(errorFmt, apiErr.Response))
-		return
+	plan.LastTaskID = types.StringValue(*res.TaskId)
+	if plan.WaitForCompletion.ValueBool() {
+		err := common.PollTask(ctx, client, *res.TaskId, intervalInSec)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				fmt.Sprintf("Error assigning policy %v to entity %v.", policyId,
+					*assignment.Entity.Id),
+				fmt.Sprintf(errorFmt, err))
+			return
+		}
 	}
-	protectionGroup := protectionGroups.NewProtectionGroupsV1(r.client.ClumioConfig)
+	protectionGroup := protectionGroups.NewProtectionGroupsV1(client.WithContext(ctx).ClumioConfig())
 	readResponse, apiErr := protectionGroup.ReadProtectionGroup(*assignment.Entity.Id)
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
 			fmt.Sprintf(
-				"Error reading Protection Group %v.", *assignment.Entity.Id),
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+				"Error reading Protection Group %v.", *assignment.Entity.Id), apiErr, nil)...)
 		return
 	}
-	if *readResponse.ProtectionInfo.PolicyId != policyId {
+	// The policy is only applied once the task completes.
+	if plan.WaitForCompletion.ValueBool() && *readResponse.ProtectionInfo.PolicyId != policyId {
 		errMsg := fmt.Sprintf(
 			"Protection group with id: %s does not have policy %s applied",
 			*assignment.Entity.Id, policyId)
@@ -351,21 +386,24 @@ func (r *clumioPolicyAssignmentResource) Delete(
 		return
 	}
 
-	if state.OrganizationalUnitID.ValueString() != "" {
-		r.client.ClumioConfig.OrganizationalUnitContext =
-			state.OrganizationalUnitID.ValueString()
-		defer r.clearOUContext()
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, r.client.Timeouts().Delete)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
 
-	pa := policyAssignments.NewPolicyAssignmentsV1(r.client.ClumioConfig)
+	client := r.client.WithOrganizationalUnit(state.OrganizationalUnitID.ValueString())
+
+	pa := policyAssignments.NewPolicyAssignmentsV1(client.WithContext(ctx).ClumioConfig())
 	paRequest := mapSchemaPolicyAssignmentToClumioPolicyAssignment(state, true)
 	_, apiErr := pa.SetPolicyAssignments(paRequest)
 	if apiErr != nil {
 		assignment := paRequest.Items[0]
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
 			fmt.Sprintf(
-				"Error unassigning policy from entity %v.", *assignment.Entity.Id),
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+				"Error unassigning policy from entity %v.", *assignment.Entity.Id), apiErr, nil)...)
 		return
 	}
 }
@@ -400,7 +438,3 @@ func mapSchemaPolicyAssignmentToClumioPolicyAssignment(
 		},
 	}
 }
-
-func (r *clumioPolicyAssignmentResource) clearOUContext() {
-	r.client.ClumioConfig.OrganizationalUnitContext = ""
-}
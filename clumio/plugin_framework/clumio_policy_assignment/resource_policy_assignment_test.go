@@ -5,10 +5,14 @@
 package clumio_policy_assignment_test
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 
+	policyAssignments "github.com/clumio-code/clumio-go-sdk/controllers/policy_assignments"
+	"github.com/clumio-code/clumio-go-sdk/models"
 	clumio_pf "github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
 
@@ -24,10 +28,45 @@ func TestAccResourceClumioPolicyAssignment(t *testing.T) {
 			{
 				Config: getTestAccResourceClumioPolicyAssignment(policyId),
 			},
+			{
+				Config: getTestAccResourceClumioPolicyAssignment(policyId),
+				Check: clumio_pf.UtilTestCheckResourceDisappears(
+					"clumio_policy_assignment.test_policy_assignment", testAccUnassignPolicy),
+				ExpectNonEmptyPlan: true,
+			},
 		},
 	})
 }
 
+// testAccUnassignPolicy removes the policy from the protection group of the assignment
+// outside of Terraform and waits for the task to complete.
+func testAccUnassignPolicy(ctx context.Context, client *common.ApiClient, id string) error {
+	idSplits := strings.Split(id, "_")
+	if len(idSplits) < 3 {
+		return fmt.Errorf("invalid policy assignment id %s", id)
+	}
+	entityId, entityType := idSplits[1], strings.Join(idSplits[2:], "_")
+	action := "unassign"
+	policyId := ""
+	pa := policyAssignments.NewPolicyAssignmentsV1(client.ClumioConfig())
+	res, apiErr := pa.SetPolicyAssignments(&models.SetPolicyAssignmentsV1Request{
+		Items: []*models.AssignmentInputModel{
+			{
+				Action: &action,
+				Entity: &models.AssignmentEntity{
+					Id:         &entityId,
+					ClumioType: &entityType,
+				},
+				PolicyId: &policyId,
+			},
+		},
+	})
+	if apiErr != nil {
+		return common.NewAPIError(apiErr)
+	}
+	return common.PollTask(ctx, client, *res.TaskId, clumio_pf.TestAccTaskIntervalInSec)
+}
+
 func getTestAccResourceClumioPolicyAssignment(policyId string) string {
 	baseUrl := os.Getenv(common.ClumioApiBaseUrl)
 	return fmt.Sprintf(testAccResourceClumioPolicyAssignment, baseUrl)
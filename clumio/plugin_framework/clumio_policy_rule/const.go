@@ -5,12 +5,15 @@ package clumio_policy_rule
 const (
 	schemaName                 = "name"
 	schemaId                   = "id"
+	schemaTimeouts             = "timeouts"
+	schemaWaitForCompletion    = "wait_for_completion"
+	schemaLastTaskId           = "last_task_id"
+	schemaFailOnTaskError      = "fail_on_task_error"
 	schemaCondition            = "condition"
 	schemaBeforeRuleId         = "before_rule_id"
 	schemaPolicyId             = "policy_id"
 	schemaOrganizationalUnitId = "organizational_unit_id"
 
-	timeoutInSec  = 3600
 	intervalInSec = 5
 
 	errorFmt = "Error: %v"
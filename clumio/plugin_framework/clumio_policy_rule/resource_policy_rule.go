@@ -11,6 +11,7 @@ import (
 	"github.com/clumio-code/clumio-go-sdk/models"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -29,23 +30,30 @@ type policyRuleResource struct {
 	client *common.ApiClient
 }
 
+// apiFieldPaths maps the fields of the API requests to the attributes of the resource.
+var apiFieldPaths = common.AttributePaths(schemaName, schemaCondition)
+
 // NewPolicyRuleResource is a helper function to simplify the provider implementation.
 func NewPolicyRuleResource() resource.Resource {
 	return &policyRuleResource{}
 }
 
 type policyRuleResourceModel struct {
-	ID                   types.String `tfsdk:"id"`
-	Name                 types.String `tfsdk:"name"`
-	Condition            types.String `tfsdk:"condition"`
-	BeforeRuleID         types.String `tfsdk:"before_rule_id"`
-	PolicyID             types.String `tfsdk:"policy_id"`
-	OrganizationalUnitID types.String `tfsdk:"organizational_unit_id"`
+	ID                   types.String   `tfsdk:"id"`
+	Name                 types.String   `tfsdk:"name"`
+	Condition            types.String   `tfsdk:"condition"`
+	BeforeRuleID         types.String   `tfsdk:"before_rule_id"`
+	PolicyID             types.String   `tfsdk:"policy_id"`
+	OrganizationalUnitID types.String   `tfsdk:"organizational_unit_id"`
+	WaitForCompletion    types.Bool     `tfsdk:"wait_for_completion"`
+	LastTaskID           types.String   `tfsdk:"last_task_id"`
+	FailOnTaskError      types.Bool     `tfsdk:"fail_on_task_error"`
+	Timeouts             timeouts.Value `tfsdk:"timeouts"`
 }
 
 // Schema defines the schema for the data source.
 func (r *policyRuleResource) Schema(
-	_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		// This description is used by the documentation generator and the language server.
 		Description: "Clumio Policy Rule Resource used to determine how" +
@@ -92,6 +100,16 @@ func (r *policyRuleResource) Schema(
 				Optional: true,
 				Computed: true,
 			},
+			schemaWaitForCompletion: common.WaitForCompletionAttribute(),
+			schemaLastTaskId:        common.LastTaskIdAttribute(),
+			schemaFailOnTaskError:   common.FailOnTaskErrorAttribute(),
+		},
+		Blocks: map[string]schema.Block{
+			schemaTimeouts: timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -128,13 +146,17 @@ func (r *policyRuleResource) Create(
 		return
 	}
 
-	if plan.OrganizationalUnitID.ValueString() != "" {
-		r.client.ClumioConfig.OrganizationalUnitContext =
-			plan.OrganizationalUnitID.ValueString()
-		defer r.clearOUContext()
+	createTimeout, diags := plan.Timeouts.Create(ctx, r.client.Timeouts().Create)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	client := r.client.WithOrganizationalUnit(plan.OrganizationalUnitID.ValueString())
 
-	pr := policyRules.NewPolicyRulesV1(r.client.ClumioConfig)
+	pr := policyRules.NewPolicyRulesV1(client.WithContext(ctx).ClumioConfig())
 	condition := plan.Condition.ValueString()
 	name := plan.Name.ValueString()
 	beforeRuleId := plan.BeforeRuleID.ValueString()
@@ -155,18 +177,21 @@ func (r *policyRuleResource) Create(
 	}
 	res, apiErr := pr.CreatePolicyRule(prRequest)
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
 			fmt.Sprintf("Error starting task to create policy rule %v.", name),
-			fmt.Sprintf(errorFmt, apiErr.Response))
+			apiErr, apiFieldPaths)...)
 		return
 
 	}
-	err := common.PollTask(ctx, r.client, *res.TaskId, timeoutInSec, intervalInSec)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			fmt.Sprintf("Error creating policy rule %v.", name),
-			fmt.Sprintf(errorFmt, apiErr.Response))
-		return
+	plan.LastTaskID = types.StringValue(*res.TaskId)
+	if plan.WaitForCompletion.ValueBool() {
+		err := common.PollTask(ctx, client, *res.TaskId, intervalInSec)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				fmt.Sprintf("Error creating policy rule %v.", name),
+				fmt.Sprintf(errorFmt, err))
+			return
+		}
 	}
 
 	plan.ID = types.StringValue(*res.Rule.Id)
@@ -188,13 +213,17 @@ func (r *policyRuleResource) Update(
 		return
 	}
 
-	if plan.OrganizationalUnitID.ValueString() != "" {
-		r.client.ClumioConfig.OrganizationalUnitContext =
-			plan.OrganizationalUnitID.ValueString()
-		defer r.clearOUContext()
+	updateTimeout, diags := plan.Timeouts.Update(ctx, r.client.Timeouts().Update)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	client := r.client.WithOrganizationalUnit(plan.OrganizationalUnitID.ValueString())
 
-	pr := policyRules.NewPolicyRulesV1(r.client.ClumioConfig)
+	pr := policyRules.NewPolicyRulesV1(client.WithContext(ctx).ClumioConfig())
 	condition := plan.Condition.ValueString()
 	name := plan.Name.ValueString()
 	beforeRuleId := plan.BeforeRuleID.ValueString()
@@ -215,17 +244,20 @@ func (r *policyRuleResource) Update(
 	}
 	res, apiErr := pr.UpdatePolicyRule(plan.ID.ValueString(), prRequest)
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
 			fmt.Sprintf("Error starting task to update policy rule %v.", name),
-			fmt.Sprintf(errorFmt, apiErr.Response))
+			apiErr, apiFieldPaths)...)
 		return
 	}
-	err := common.PollTask(ctx, r.client, *res.TaskId, timeoutInSec, intervalInSec)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			fmt.Sprintf("Error updating policy rule %v.", name),
-			fmt.Sprintf(errorFmt, apiErr.Response))
-		return
+	plan.LastTaskID = types.StringValue(*res.TaskId)
+	if plan.WaitForCompletion.ValueBool() {
+		err := common.PollTask(ctx, client, *res.TaskId, intervalInSec)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				fmt.Sprintf("Error updating policy rule %v.", name),
+				fmt.Sprintf(errorFmt, err))
+			return
+		}
 	}
 	plan.OrganizationalUnitID = types.StringValue(*res.Rule.OrganizationalUnitId)
 	diags = resp.State.Set(ctx, plan)
@@ -244,19 +276,20 @@ func (r *policyRuleResource) Read(
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	pr := policyRules.NewPolicyRulesV1(r.client.ClumioConfig)
 
-	if state.OrganizationalUnitID.ValueString() != "" {
-		r.client.ClumioConfig.OrganizationalUnitContext =
-			state.OrganizationalUnitID.ValueString()
-		defer r.clearOUContext()
-	}
+	client := r.client.WithOrganizationalUnit(state.OrganizationalUnitID.ValueString())
+	pr := policyRules.NewPolicyRulesV1(client.WithContext(ctx).ClumioConfig())
 
 	res, apiErr := pr.ReadPolicyRule(state.ID.ValueString())
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
+		if common.NewAPIError(apiErr).IsNotFound() {
+			common.RemoveMissingResource(ctx, resp,
+				fmt.Sprintf("Clumio policy rule %q", state.Name.ValueString()))
+			return
+		}
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
 			fmt.Sprintf("Error retrieving policy rule %v.", state.Name.ValueString()),
-			fmt.Sprintf(errorFmt, apiErr.Response))
+			apiErr, nil)...)
 		return
 	}
 	state.Name = types.StringValue(*res.Name)
@@ -266,6 +299,11 @@ func (r *policyRuleResource) Read(
 	}
 	state.PolicyID = types.StringValue(*res.Action.AssignPolicy.PolicyId)
 	state.OrganizationalUnitID = types.StringValue(*res.OrganizationalUnitId)
+	common.SetTaskAttributeDefaults(&state.WaitForCompletion, &state.FailOnTaskError)
+	_, diags = common.LastTaskDiagnostics(ctx, client, resp.Private,
+		state.WaitForCompletion, state.LastTaskID, state.FailOnTaskError,
+		fmt.Sprintf("policy rule %q", state.Name.ValueString()))
+	resp.Diagnostics.Append(diags...)
 	diags = resp.State.Set(ctx, state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -283,30 +321,32 @@ func (r *policyRuleResource) Delete(
 		return
 	}
 
-	if state.OrganizationalUnitID.ValueString() != "" {
-		r.client.ClumioConfig.OrganizationalUnitContext =
-			state.OrganizationalUnitID.ValueString()
-		defer r.clearOUContext()
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, r.client.Timeouts().Delete)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	client := r.client.WithOrganizationalUnit(state.OrganizationalUnitID.ValueString())
 
-	pr := policyRules.NewPolicyRulesV1(r.client.ClumioConfig)
+	pr := policyRules.NewPolicyRulesV1(client.WithContext(ctx).ClumioConfig())
 	res, apiErr := pr.DeletePolicyRule(state.ID.ValueString())
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
 			fmt.Sprintf("Error starting task to delete policy rule %v.",
-				state.Name.ValueString()),
-			fmt.Sprintf(errorFmt, apiErr.Response))
+				state.Name.ValueString()), apiErr, nil)...)
 		return
 	}
-	err := common.PollTask(ctx, r.client, *res.TaskId, timeoutInSec, intervalInSec)
+	if !state.WaitForCompletion.ValueBool() {
+		return
+	}
+	err := common.PollTask(ctx, client, *res.TaskId, intervalInSec)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			fmt.Sprintf("Error deleting policy rule %v.", state.Name.ValueString()),
-			fmt.Sprintf(errorFmt, apiErr.Response))
+			fmt.Sprintf(errorFmt, err))
 		return
 	}
 }
-
-func (r *policyRuleResource) clearOUContext() {
-	r.client.ClumioConfig.OrganizationalUnitContext = ""
-}
@@ -4,11 +4,13 @@
 package clumio_policy_rule_test
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"regexp"
 	"testing"
 
+	policyRules "github.com/clumio-code/clumio-go-sdk/controllers/policy_rules"
 	clumio_pf "github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
 
@@ -33,6 +35,14 @@ func TestAccResourceClumioPolicyRule(t *testing.T) {
 					resource.TestMatchResourceAttr(
 						"clumio_policy_rule.test_policy_rule_2", "name",
 						regexp.MustCompile(policyRuleTwoName)),
+					resource.TestCheckResourceAttr(
+						"clumio_policy_rule.test_policy_rule_2", "timeouts.create", "20m"),
+					resource.TestCheckResourceAttrSet(
+						"clumio_policy_rule.test_policy_rule", "last_task_id"),
+					resource.TestCheckResourceAttr(
+						"clumio_policy_rule.test_policy_rule_2", "wait_for_completion", "true"),
+					resource.TestCheckResourceAttrSet(
+						"clumio_policy_rule.test_policy_rule_2", "last_task_id"),
 				),
 			},
 			{
@@ -46,10 +56,59 @@ func TestAccResourceClumioPolicyRule(t *testing.T) {
 						regexp.MustCompile(policyRuleTwoName)),
 				),
 			},
+			{
+				ResourceName:            "clumio_policy_rule.test_policy_rule",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"last_task_id"},
+			},
+			{
+				Config: getTestAccResourceClumioPolicyRule(policyTwoName, policyRuleName, policyRuleTwoName),
+				Check: clumio_pf.UtilTestCheckResourceDisappears(
+					"clumio_policy_rule.test_policy_rule", testAccDeletePolicyRule),
+				ExpectNonEmptyPlan: true,
+			},
 		},
 	})
 }
 
+// TestAccResourceClumioPolicyRuleNoWait tests that a policy rule created without waiting for
+// its task records the task, and waits for the deletion of the rule once wait_for_completion is
+// set again, so that the policy is only deleted after the rule.
+func TestAccResourceClumioPolicyRuleNoWait(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { clumio_pf.UtilTestAccPreCheckClumio(t) },
+		ProtoV6ProviderFactories: clumio_pf.TestAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: getTestAccResourceClumioPolicyRuleNoWait(false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"clumio_policy_rule.test_policy_rule", "wait_for_completion", "false"),
+					resource.TestCheckResourceAttrSet(
+						"clumio_policy_rule.test_policy_rule", "last_task_id"),
+				),
+			},
+			{
+				Config: getTestAccResourceClumioPolicyRuleNoWait(true),
+				Check: resource.TestCheckResourceAttr(
+					"clumio_policy_rule.test_policy_rule", "wait_for_completion", "true"),
+			},
+		},
+	})
+}
+
+// testAccDeletePolicyRule deletes the policy rule outside of Terraform and waits for the
+// deletion task to complete.
+func testAccDeletePolicyRule(ctx context.Context, client *common.ApiClient, id string) error {
+	pr := policyRules.NewPolicyRulesV1(client.ClumioConfig())
+	res, apiErr := pr.DeletePolicyRule(id)
+	if apiErr != nil {
+		return common.NewAPIError(apiErr)
+	}
+	return common.PollTask(ctx, client, *res.TaskId, clumio_pf.TestAccTaskIntervalInSec)
+}
+
 func getTestAccResourceClumioPolicyRule(policyName string,
 	policyRuleName string, policyRuleTwoName string) string {
 	baseUrl := os.Getenv(common.ClumioApiBaseUrl)
@@ -57,9 +116,17 @@ func getTestAccResourceClumioPolicyRule(policyName string,
 		policyRuleName, policyName, policyRuleTwoName, policyName)
 }
 
+func getTestAccResourceClumioPolicyRuleNoWait(waitForCompletion bool) string {
+	baseUrl := os.Getenv(common.ClumioApiBaseUrl)
+	return fmt.Sprintf(testAccResourceClumioPolicyRuleNoWait, baseUrl, waitForCompletion)
+}
+
 const testAccResourceClumioPolicyRule = `
 provider clumio{
    clumio_api_base_url = "%s"
+   default_timeouts {
+     delete = "30m"
+   }
 }
 
 resource "clumio_policy" "%s" {
@@ -97,6 +164,41 @@ resource "clumio_policy_rule" "test_policy_rule_2" {
   policy_id = clumio_policy.%s.id
   before_rule_id = clumio_policy_rule.test_policy_rule.id
   condition = "{\"entity_type\":{\"$eq\":\"aws_ebs_volume\"}, \"aws_tag\":{\"$eq\":{\"key\":\"Foo\", \"value\":\"Bar\"}}}"
+  timeouts {
+    create = "20m"
+    update = "20m"
+  }
 }
 
 `
+const testAccResourceClumioPolicyRuleNoWait = `
+provider clumio{
+   clumio_api_base_url = "%s"
+}
+
+resource "clumio_policy" "test_policy" {
+ name = "acceptance-test-policy-no-wait"
+ operations {
+	action_setting = "immediate"
+	type = "aws_ebs_volume_backup"
+	slas {
+		retention_duration {
+			unit = "days"
+			value = 1
+		}
+		rpo_frequency {
+			unit = "days"
+			value = 1
+		}
+	}
+ }
+}
+
+resource "clumio_policy_rule" "test_policy_rule" {
+  name = "acceptance-test-policy-rule-no-wait"
+  policy_id = clumio_policy.test_policy.id
+  before_rule_id = ""
+  condition = "{\"entity_type\":{\"$eq\":\"aws_ebs_volume\"}, \"aws_tag\":{\"$eq\":{\"key\":\"Foo\", \"value\":\"Bar\"}}}"
+  wait_for_completion = %t
+}
+`
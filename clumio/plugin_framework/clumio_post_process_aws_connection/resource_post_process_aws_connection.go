@@ -288,9 +288,9 @@ func (r *postProcessAWSConnectionResource) Delete(
 // clumioPostProcessAWSConnectionCommon contains the common logic for all CRUD operations
 // of PostProcessAWSConnection resource.
 func (r *postProcessAWSConnectionResource) clumioPostProcessAWSConnectionCommon(
-	_ context.Context, state postProcessAWSConnectionResourceModel, eventType string) diag.Diagnostics {
+	ctx context.Context, state postProcessAWSConnectionResourceModel, eventType string) diag.Diagnostics {
 	postProcessAwsConnection := aws_connections.NewPostProcessAwsConnectionV1(
-		r.client.ClumioConfig)
+		r.client.WithContext(ctx).ClumioConfig())
 	accountId := state.AccountID.ValueString()
 	awsRegion := state.Region.ValueString()
 	roleArn := state.RoleArn.ValueString()
@@ -197,9 +197,9 @@ func (r *clumioPostProcessKmsResource) Delete(
 }
 
 func (r *clumioPostProcessKmsResource) clumioPostProcessKmsCommon(
-	_ context.Context, state clumioPostProcessKmsResourceModel, eventType string) diag.Diagnostics {
+	ctx context.Context, state clumioPostProcessKmsResourceModel, eventType string) diag.Diagnostics {
 
-	postProcessAwsKMS := kms.NewPostProcessKmsV1(r.client.ClumioConfig)
+	postProcessAwsKMS := kms.NewPostProcessKmsV1(r.client.WithContext(ctx).ClumioConfig())
 	accountId := state.AccountId.ValueString()
 	awsRegion := state.Region.ValueString()
 	multiRegionCMKKeyId := state.MultiRegionCMKKeyId.ValueString()
@@ -83,15 +83,15 @@ provider clumio{
   clumio_api_base_url = "%s"
 }
 
-resource "clumio_wallet" "test_wallet {
-  account_native_id = %s
+resource "clumio_wallet" "test_wallet" {
+  account_native_id = "%s"
 }
 
-resource "clumio_post_process_aws_connection" "test" {
+resource "clumio_post_process_kms" "test" {
   token = clumio_wallet.test_wallet.token
-  account_native_id = clumio_wallet.test_wallet.account_native_id
+  account_id = clumio_wallet.test_wallet.account_native_id
   region = "%s"
-  multi_region_cmk_key_id = "test_multi_region_cmk_key_id"
+  multi_region_cmk_key_id = "test_multi_region_cmk_key_id_updated"
   role_external_id = "test_role_external_id"
   role_arn = "test_role_arn"
   role_id = "test_role_id"
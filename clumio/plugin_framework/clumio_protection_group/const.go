@@ -4,6 +4,7 @@ package clumio_protection_group
 
 const (
 	schemaId                   = "id"
+	schemaTimeouts             = "timeouts"
 	schemaBucketRule           = "bucket_rule"
 	schemaDescription          = "description"
 	schemaName                 = "name"
@@ -20,7 +21,6 @@ const (
 	schemaInheritingEntityType = "inheriting_entity_type"
 	schemaProtectionStatus     = "protection_status"
 
-	timeoutInSec  = 3600
 	intervalInSec = 5
 
 	errorFmt                    = "Error: %v"
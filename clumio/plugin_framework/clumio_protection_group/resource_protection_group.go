@@ -7,7 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
+	"strconv"
 	"time"
 
 	"github.com/clumio-code/clumio-go-sdk/config"
@@ -15,6 +15,7 @@ import (
 	"github.com/clumio-code/clumio-go-sdk/models"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
@@ -38,6 +39,9 @@ type protectionGroupResource struct {
 	client *common.ApiClient
 }
 
+// apiFieldPaths maps the fields of the API requests to the attributes of the resource.
+var apiFieldPaths = common.AttributePaths(schemaName, schemaDescription, schemaBucketRule)
+
 // NewProtectionGroupResource is a helper function to simplify the provider implementation.
 func NewProtectionGroupResource() resource.Resource {
 	return &protectionGroupResource{}
@@ -63,11 +67,12 @@ type protectionGroupResourceModel struct {
 	ProtectionStatus     types.String         `tfsdk:"protection_status"`
 	ProtectionInfo       types.List           `tfsdk:"protection_info"`
 	OrganizationalUnitID types.String         `tfsdk:"organizational_unit_id"`
+	Timeouts             timeouts.Value       `tfsdk:"timeouts"`
 }
 
 // Schema defines the schema for the data source.
 func (r *protectionGroupResource) Schema(
-	_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	prefixFilterSchemaAttributes := map[string]schema.Attribute{
 		schemaExcludedSubPrefixes: schema.SetAttribute{
 			Description: "List of subprefixes to exclude from the prefix.",
@@ -173,6 +178,11 @@ func (r *protectionGroupResource) Schema(
 			},
 		},
 		Blocks: map[string]schema.Block{
+			schemaTimeouts: timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Update: true,
+				Delete: true,
+			}),
 			schemaObjectFilter: schema.SetNestedBlock{
 				NestedObject: schema.NestedBlockObject{
 					Attributes: objectFilterSchemaAttributes,
@@ -218,12 +228,16 @@ func (r *protectionGroupResource) Create(
 		return
 	}
 
-	if plan.OrganizationalUnitID.ValueString() != "" {
-		r.client.ClumioConfig.OrganizationalUnitContext =
-			plan.OrganizationalUnitID.ValueString()
-		defer r.clearOUContext()
+	createTimeout, diags := plan.Timeouts.Create(ctx, r.client.Timeouts().Create)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	protectionGroup := protectionGroups.NewProtectionGroupsV1(r.client.ClumioConfig)
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	client := r.client.WithOrganizationalUnit(plan.OrganizationalUnitID.ValueString())
+	protectionGroup := protectionGroups.NewProtectionGroupsV1(client.WithContext(ctx).ClumioConfig())
 	name := plan.Name.ValueString()
 	description := plan.Description.ValueString()
 	bucketRule := plan.BucketRule.ValueString()
@@ -236,25 +250,24 @@ func (r *protectionGroupResource) Create(
 			ObjectFilter: objectFilter,
 		})
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
-			fmt.Sprintf("Error creating Protection Group %v.", name),
-			fmt.Sprintf(errorFmt, apiErr.Response))
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			fmt.Sprintf("Error creating Protection Group %v.", name), apiErr, apiFieldPaths)...)
 		return
 	}
-	err := pollForProtectionGroup(ctx, *response.Id, r.client.ClumioConfig)
+	err := pollForProtectionGroup(ctx, *response.Id, client.WithContext(ctx).ClumioConfig(),
+		client.PollInterval(intervalInSec))
 	if err != nil {
 		resp.Diagnostics.AddError(
 			fmt.Sprintf("Error reading the created Protection Group: %v", name),
-			fmt.Sprintf(errorFmt, apiErr.Response))
+			fmt.Sprintf(errorFmt, err))
 		return
 	}
 
 	plan.ID = types.StringValue(*response.Id)
 	readResponse, apiErr := protectionGroup.ReadProtectionGroup(plan.ID.ValueString())
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
-			fmt.Sprintf(errorProtectionGroupReadFmt, plan.Name.ValueString()),
-			fmt.Sprintf(errorFmt, apiErr.Response))
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			fmt.Sprintf(errorProtectionGroupReadFmt, plan.Name.ValueString()), apiErr, nil)...)
 		return
 	}
 	if !plan.Description.IsNull() || *readResponse.Description != "" {
@@ -271,6 +284,8 @@ func (r *protectionGroupResource) Create(
 	plan.ProtectionInfo, diags = mapClumioProtectionInfoToSchemaProtectionInfo(
 		readResponse.ProtectionInfo)
 	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(common.SetPrivateVersion(
+		ctx, resp.Private, protectionGroupVersion(readResponse))...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -290,16 +305,27 @@ func (r *protectionGroupResource) Update(
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	if plan.OrganizationalUnitID.ValueString() != "" {
-		r.client.ClumioConfig.OrganizationalUnitContext =
-			plan.OrganizationalUnitID.ValueString()
-		defer r.clearOUContext()
+
+	updateTimeout, diags := plan.Timeouts.Update(ctx, r.client.Timeouts().Update)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	protectionGroup := protectionGroups.NewProtectionGroupsV1(r.client.ClumioConfig)
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	client := r.client.WithOrganizationalUnit(plan.OrganizationalUnitID.ValueString())
+	protectionGroup := protectionGroups.NewProtectionGroupsV1(client.WithContext(ctx).ClumioConfig())
 	name := plan.Name.ValueString()
 	description := plan.Description.ValueString()
 	bucketRule := plan.BucketRule.ValueString()
 	objectFilter := mapSchemaObjectFilterToClumioObjectFilter(plan.ObjectFilter)
+	resp.Diagnostics.Append(checkProtectionGroupUnchanged(ctx, protectionGroup,
+		plan.ID.ValueString(), req.Private,
+		fmt.Sprintf("Error updating Protection Group %v.", name))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 	response, apiErr := protectionGroup.UpdateProtectionGroup(plan.ID.ValueString(),
 		&models.UpdateProtectionGroupV1Request{
 			BucketRule:   &bucketRule,
@@ -308,23 +334,22 @@ func (r *protectionGroupResource) Update(
 			ObjectFilter: objectFilter,
 		})
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
-			fmt.Sprintf("Error updating Protection Group %v.", name),
-			fmt.Sprintf(errorFmt, apiErr.Response))
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			fmt.Sprintf("Error updating Protection Group %v.", name), apiErr, apiFieldPaths)...)
 		return
 	}
-	err := pollForProtectionGroup(ctx, *response.Id, r.client.ClumioConfig)
+	err := pollForProtectionGroup(ctx, *response.Id, client.WithContext(ctx).ClumioConfig(),
+		client.PollInterval(intervalInSec))
 	if err != nil {
 		resp.Diagnostics.AddError(
 			fmt.Sprintf("Error reading the updated Protection Group: %v", name),
-			fmt.Sprintf(errorFmt, apiErr.Response))
+			fmt.Sprintf(errorFmt, err))
 		return
 	}
 	readResponse, apiErr := protectionGroup.ReadProtectionGroup(plan.ID.ValueString())
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
-			fmt.Sprintf(errorProtectionGroupReadFmt, plan.Name.ValueString()),
-			fmt.Sprintf(errorFmt, apiErr.Response))
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			fmt.Sprintf(errorProtectionGroupReadFmt, plan.Name.ValueString()), apiErr, nil)...)
 		return
 	}
 	if !plan.Description.IsNull() || *readResponse.Description != "" {
@@ -341,6 +366,8 @@ func (r *protectionGroupResource) Update(
 	plan.ProtectionInfo, diags = mapClumioProtectionInfoToSchemaProtectionInfo(
 		readResponse.ProtectionInfo)
 	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(common.SetPrivateVersion(
+		ctx, resp.Private, protectionGroupVersion(readResponse))...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -360,17 +387,17 @@ func (r *protectionGroupResource) Read(
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	if state.OrganizationalUnitID.ValueString() != "" {
-		r.client.ClumioConfig.OrganizationalUnitContext =
-			state.OrganizationalUnitID.ValueString()
-		defer r.clearOUContext()
-	}
-	protectionGroup := protectionGroups.NewProtectionGroupsV1(r.client.ClumioConfig)
+	client := r.client.WithOrganizationalUnit(state.OrganizationalUnitID.ValueString())
+	protectionGroup := protectionGroups.NewProtectionGroupsV1(client.WithContext(ctx).ClumioConfig())
 	readResponse, apiErr := protectionGroup.ReadProtectionGroup(state.ID.ValueString())
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
-			fmt.Sprintf(errorProtectionGroupReadFmt, state.Name.ValueString()),
-			fmt.Sprintf(errorFmt, apiErr.Response))
+		if common.NewAPIError(apiErr).IsNotFound() {
+			common.RemoveMissingResource(ctx, resp,
+				fmt.Sprintf("Clumio protection group %q", state.Name.ValueString()))
+			return
+		}
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			fmt.Sprintf(errorProtectionGroupReadFmt, state.Name.ValueString()), apiErr, nil)...)
 		return
 	}
 	if !state.Description.IsNull() || *readResponse.Description != "" {
@@ -387,6 +414,8 @@ func (r *protectionGroupResource) Read(
 	state.ProtectionInfo, diags = mapClumioProtectionInfoToSchemaProtectionInfo(
 		readResponse.ProtectionInfo)
 	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(common.SetPrivateVersion(
+		ctx, resp.Private, protectionGroupVersion(readResponse))...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -406,21 +435,59 @@ func (r *protectionGroupResource) Delete(
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	if state.OrganizationalUnitID.ValueString() != "" {
-		r.client.ClumioConfig.OrganizationalUnitContext =
-			state.OrganizationalUnitID.ValueString()
-		defer r.clearOUContext()
+
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, r.client.Timeouts().Delete)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	client := r.client.WithOrganizationalUnit(state.OrganizationalUnitID.ValueString())
+	protectionGroup := protectionGroups.NewProtectionGroupsV1(client.WithContext(ctx).ClumioConfig())
+	resp.Diagnostics.Append(checkProtectionGroupUnchanged(ctx, protectionGroup,
+		state.ID.ValueString(), req.Private,
+		fmt.Sprintf("Error deleting Protection Group %v.", state.Name.ValueString()))...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	protectionGroup := protectionGroups.NewProtectionGroupsV1(r.client.ClumioConfig)
 	_, apiErr := protectionGroup.DeleteProtectionGroup(state.ID.ValueString())
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
 			fmt.Sprintf("Error deleting Protection Group %v.", state.Name.ValueString()),
-			fmt.Sprintf(errorFmt, apiErr.Response))
+			apiErr, nil)...)
 		return
 	}
 }
 
+// checkProtectionGroupUnchanged reads the protection group right before it is updated or
+// deleted, and reports an error with the given summary if it was changed outside Terraform
+// since the last refresh. The protection groups API returns no ETag, so the version of the
+// protection group is compared instead.
+func checkProtectionGroupUnchanged(ctx context.Context,
+	protectionGroup protectionGroups.ProtectionGroupsV1Client, id string,
+	private common.PrivateState, summary string) diag.Diagnostics {
+	readResponse, apiErr := protectionGroup.ReadProtectionGroup(id)
+	if apiErr != nil {
+		// The update or deletion reports a protection group deleted in the meantime.
+		if common.NewAPIError(apiErr).IsNotFound() {
+			return nil
+		}
+		return common.APIErrorDiagnostics(summary, apiErr, nil)
+	}
+	return common.CheckPrivateVersion(ctx, private, protectionGroupVersion(readResponse), summary)
+}
+
+// protectionGroupVersion returns the version of the protection group recorded in the private
+// state, or an empty string if the API did not return it.
+func protectionGroupVersion(readResponse *models.ReadProtectionGroupResponse) string {
+	if readResponse.Version == nil {
+		return ""
+	}
+	return strconv.FormatInt(*readResponse.Version, 10)
+}
+
 // mapSchemaObjectFilterToClumioObjectFilter converts the schema object_filter
 // to the model Object Filter
 func mapSchemaObjectFilterToClumioObjectFilter(objectFilterSlice []*objectFilterModel) *models.ObjectFilter {
@@ -457,32 +524,31 @@ func mapSchemaObjectFilterToClumioObjectFilter(objectFilterSlice []*objectFilter
 	}
 }
 
-// pollForProtectionGroup polls till the protection group becomes available after create
-// or update protection group as they are asynchronous operations.
-func pollForProtectionGroup(ctx context.Context, id string, config config.Config) error {
+// pollForProtectionGroup polls every interval till the protection group becomes available
+// after create or update protection group as they are asynchronous operations. Polling stops
+// when the deadline of ctx, derived from the timeouts of the operation, is reached.
+func pollForProtectionGroup(ctx context.Context, id string, config config.Config,
+	interval time.Duration) error {
 	protectionGroup := protectionGroups.NewProtectionGroupsV1(config)
-	interval := time.Duration(intervalInSec) * time.Second
 	ticker := time.NewTicker(interval)
-	timeout := time.After(time.Duration(timeoutInSec) * time.Second)
 	defer ticker.Stop()
 	for {
 		select {
 		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("timed out waiting for protection group %s to be available", id)
+			}
 			return errors.New("context done")
 		case <-ticker.C:
 			_, err := protectionGroup.ReadProtectionGroup(id)
 			if err != nil {
-				errResponseStr := string(err.Response)
-				if !strings.Contains(errResponseStr,
-					"A resource with the requested ID could not be found.") {
+				if !common.NewAPIError(err).IsNotFound() {
 					return errors.New(
 						"error reading protection-group which was created")
 				}
 				continue
 			}
 			return nil
-		case <-timeout:
-			return errors.New("polling timeout")
 		}
 	}
 }
@@ -565,7 +631,3 @@ func mapClumioProtectionInfoToSchemaProtectionInfo(
 	listdiag.Append(diags...)
 	return listobj, listdiag
 }
-
-func (r *protectionGroupResource) clearOUContext() {
-	r.client.ClumioConfig.OrganizationalUnitContext = ""
-}
@@ -4,11 +4,13 @@
 package clumio_protection_group_test
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"regexp"
 	"testing"
 
+	protectionGroups "github.com/clumio-code/clumio-go-sdk/controllers/protection_groups"
 	clumio_pf "github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
 
@@ -34,10 +36,30 @@ func TestAccResourceClumioProtectionGroup(t *testing.T) {
 						"clumio_protection_group.test_pg", "description",
 						regexp.MustCompile("test_pg_1_updated"))),
 			},
+			{
+				ResourceName:      "clumio_protection_group.test_pg",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: getTestAccResourceClumioProtectionGroup(true),
+				Check: clumio_pf.UtilTestCheckResourceDisappears(
+					"clumio_protection_group.test_pg", testAccDeleteProtectionGroup),
+				ExpectNonEmptyPlan: true,
+			},
 		},
 	})
 }
 
+// testAccDeleteProtectionGroup deletes the protection group outside of Terraform.
+func testAccDeleteProtectionGroup(_ context.Context, client *common.ApiClient, id string) error {
+	protectionGroup := protectionGroups.NewProtectionGroupsV1(client.ClumioConfig())
+	if _, apiErr := protectionGroup.DeleteProtectionGroup(id); apiErr != nil {
+		return common.NewAPIError(apiErr)
+	}
+	return nil
+}
+
 func getTestAccResourceClumioProtectionGroup(update bool) string {
 	baseUrl := os.Getenv(common.ClumioApiBaseUrl)
 	description := "test_pg_1"
@@ -6,7 +6,6 @@ package clumio_role
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/clumio-code/clumio-go-sdk/controllers/roles"
 	"github.com/clumio-code/clumio-go-sdk/models"
@@ -125,12 +124,11 @@ func (r *clumioRoleDataSource) Read(
 		return
 	}
 
-	rolesApi := roles.NewRolesV1(r.client.ClumioConfig)
-	res, apiErr := rolesApi.ListRoles()
+	rolesApi := roles.NewRolesV1(r.client.WithContext(ctx).ClumioConfig())
+	res, apiErr := common.Lookup(ctx, r.client, common.LookupRoles, "", rolesApi.ListRoles)
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
-			"Error listing Clumio roles.",
-			fmt.Sprintf("Error: %v", string(apiErr.Response)))
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			"Error listing Clumio roles.", apiErr, nil)...)
 		return
 	}
 	var expectedRole *models.RoleWithETag
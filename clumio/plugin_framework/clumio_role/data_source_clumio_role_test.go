@@ -6,38 +6,105 @@ package clumio_role_test
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"testing"
 
 	clumio_pf "github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework"
 
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
+	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/mock_api"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccDataSourceClumioRoles(t *testing.T) {
 	baseUrl := os.Getenv(common.ClumioApiBaseUrl)
+	var listings roleListings
 	resource.Test(t, resource.TestCase{
 		PreCheck:                 func() { clumio_pf.UtilTestAccPreCheckClumio(t) },
 		ProtoV6ProviderFactories: clumio_pf.TestAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			{
-				Config: getTestDataSourceClumioCallbackClumioRole(baseUrl),
+				PreConfig: listings.reset,
+				Config:    getTestDataSourceClumioCallbackClumioRole(baseUrl),
 				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttr(
 						"data.clumio_role.test_role", "id", "30000000-0000-0000-0000-000000000000"),
 					resource.TestCheckResourceAttr(
 						"data.clumio_role.test_role", "name", "Backup Admin"),
+					resource.TestCheckResourceAttrPair(
+						"data.clumio_role.test_role_cached", "id",
+						"data.clumio_role.test_role", "id"),
+					// The data sources are read when planning and again when applying,
+					// both times from a single listing of the roles.
+					listings.check(2),
+				),
+			},
+			{
+				PreConfig: listings.reset,
+				Config:    getTestDataSourceClumioCallbackClumioRoleNoCache(baseUrl),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.clumio_role.test_role", "id", "30000000-0000-0000-0000-000000000000"),
+					resource.TestCheckResourceAttrPair(
+						"data.clumio_role.test_role_uncached", "id",
+						"data.clumio_role.test_role", "id"),
+					// Without the cache, every data source lists the roles.
+					listings.check(4),
 				),
 			},
 		},
 	})
 }
 
+// roleListings counts the listings of the roles received by the mock Clumio API since the last
+// reset. The counts are only checked when the test runs against the mock Clumio API.
+type roleListings struct {
+	start int
+}
+
+// reset starts counting from the requests received so far.
+func (l *roleListings) reset() {
+	l.start = len(roleListingRequests())
+}
+
+// check returns a check that the roles were listed the given number of times since the last
+// reset.
+func (l *roleListings) check(want int) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		if clumio_pf.TestAccMockApiServer == nil {
+			return nil
+		}
+		if got := len(roleListingRequests()) - l.start; got != want {
+			return fmt.Errorf("got the roles listed %d times, want %d", got, want)
+		}
+		return nil
+	}
+}
+
+// roleListingRequests returns the listings of the roles received by the mock Clumio API.
+func roleListingRequests() []mock_api.Request {
+	if clumio_pf.TestAccMockApiServer == nil {
+		return nil
+	}
+	var listings []mock_api.Request
+	for _, req := range clumio_pf.TestAccMockApiServer.Requests() {
+		if req.Method == http.MethodGet && req.Path == "/roles" {
+			listings = append(listings, req)
+		}
+	}
+	return listings
+}
+
 func getTestDataSourceClumioCallbackClumioRole(baseUrl string) string {
 	return fmt.Sprintf(testAccDataSourceClumioRoles, baseUrl)
 }
 
+func getTestDataSourceClumioCallbackClumioRoleNoCache(baseUrl string) string {
+	return fmt.Sprintf(testAccDataSourceClumioRolesNoCache, baseUrl)
+}
+
 const testAccDataSourceClumioRoles = `
 provider clumio{
    clumio_api_base_url = "%s"
@@ -46,4 +113,23 @@ provider clumio{
 data "clumio_role" "test_role" {
 	name = "Backup Admin"
 }
+
+data "clumio_role" "test_role_cached" {
+	name = "Backup Admin"
+}
+`
+
+const testAccDataSourceClumioRolesNoCache = `
+provider clumio{
+   clumio_api_base_url = "%s"
+   disable_lookup_cache = true
+}
+
+data "clumio_role" "test_role" {
+	name = "Backup Admin"
+}
+
+data "clumio_role" "test_role_uncached" {
+	name = "Backup Admin"
+}
 `
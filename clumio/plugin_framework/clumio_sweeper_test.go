@@ -4,10 +4,8 @@ package clumio_pf
 
 import (
 	"testing"
-
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
 func TestMain(m *testing.M) {
-	resource.TestMain(m)
+	UtilTestMain(m)
 }
@@ -0,0 +1,24 @@
+// Copyright 2024. Clumio, Inc.
+
+package clumio_task
+
+const (
+	schemaId                 = "id"
+	schemaWaitUntilTerminal  = "wait_until_terminal"
+	schemaTimeouts           = "timeouts"
+	schemaStatus             = "status"
+	schemaType               = "type"
+	schemaCategory           = "category"
+	schemaProgressPercentage = "progress_percentage"
+	schemaCreatedTimestamp   = "created_timestamp"
+	schemaStartTimestamp     = "start_timestamp"
+	schemaEndTimestamp       = "end_timestamp"
+	schemaParentEntity       = "parent_entity"
+	schemaPrimaryEntity      = "primary_entity"
+	schemaValue              = "value"
+	schemaErrorMessages      = "error_messages"
+
+	intervalInSec = 5
+
+	errorFmt = "Error: %v"
+)
@@ -0,0 +1,238 @@
+// Copyright 2024. Clumio, Inc.
+//
+// clumio_task definition and read implementation.
+
+package clumio_task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/datasource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &clumioTaskDataSource{}
+	_ datasource.DataSourceWithConfigure = &clumioTaskDataSource{}
+)
+
+// NewClumioTaskDataSource is a helper function to simplify the provider implementation.
+func NewClumioTaskDataSource() datasource.DataSource {
+	return &clumioTaskDataSource{}
+}
+
+// clumioTaskDataSource is the data source implementation.
+type clumioTaskDataSource struct {
+	client *common.ApiClient
+}
+
+// taskEntityModel is the model of the entities associated with the task.
+type taskEntityModel struct {
+	Id    types.String `tfsdk:"id"`
+	Type  types.String `tfsdk:"type"`
+	Value types.String `tfsdk:"value"`
+}
+
+// clumioTaskDataSourceModel model
+type clumioTaskDataSourceModel struct {
+	Id                 types.String     `tfsdk:"id"`
+	WaitUntilTerminal  types.Bool       `tfsdk:"wait_until_terminal"`
+	Timeouts           timeouts.Value   `tfsdk:"timeouts"`
+	Status             types.String     `tfsdk:"status"`
+	Type               types.String     `tfsdk:"type"`
+	Category           types.String     `tfsdk:"category"`
+	ProgressPercentage types.Int64      `tfsdk:"progress_percentage"`
+	CreatedTimestamp   types.String     `tfsdk:"created_timestamp"`
+	StartTimestamp     types.String     `tfsdk:"start_timestamp"`
+	EndTimestamp       types.String     `tfsdk:"end_timestamp"`
+	ParentEntity       *taskEntityModel `tfsdk:"parent_entity"`
+	PrimaryEntity      *taskEntityModel `tfsdk:"primary_entity"`
+	ErrorMessages      types.List       `tfsdk:"error_messages"`
+}
+
+// Metadata returns the data source type name.
+func (r *clumioTaskDataSource) Metadata(
+	_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_task"
+}
+
+// Schema defines the schema for the data source.
+func (r *clumioTaskDataSource) Schema(
+	ctx context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+
+	entityAttributes := map[string]schema.Attribute{
+		schemaId: schema.StringAttribute{
+			Description: "The Clumio-assigned ID of the entity.",
+			Computed:    true,
+		},
+		schemaType: schema.StringAttribute{
+			Description: "The type of the entity, such as policy or aws_connection.",
+			Computed:    true,
+		},
+		schemaValue: schema.StringAttribute{
+			Description: "The value of the entity, such as the name of a policy.",
+			Computed:    true,
+		},
+	}
+
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			schemaId: schema.StringAttribute{
+				Description: "The Clumio-assigned ID of the task.",
+				Required:    true,
+			},
+			schemaWaitUntilTerminal: schema.BoolAttribute{
+				Description: "Set to true to wait for the task to complete, fail or be" +
+					" aborted before reading it, for up to the read timeout. A task that" +
+					" fails or is aborted is not an error: its status and error messages are" +
+					" returned. Defaults to false.",
+				Optional: true,
+			},
+			schemaStatus: schema.StringAttribute{
+				Description: "The status of the task, such as queued, in_progress," +
+					" completed, failed or aborted.",
+				Computed: true,
+			},
+			schemaType: schema.StringAttribute{
+				Description: "The type of the task, such as policy_update.",
+				Computed:    true,
+			},
+			schemaCategory: schema.StringAttribute{
+				Description: "The category of the task, such as management.",
+				Computed:    true,
+			},
+			schemaProgressPercentage: schema.Int64Attribute{
+				Description: "The percentage of the task completed.",
+				Computed:    true,
+			},
+			schemaCreatedTimestamp: schema.StringAttribute{
+				Description: "The timestamp of when the task was created, in RFC-3339 format.",
+				Computed:    true,
+			},
+			schemaStartTimestamp: schema.StringAttribute{
+				Description: "The timestamp of when the task started, in RFC-3339 format." +
+					" Null if the task has not started yet.",
+				Computed: true,
+			},
+			schemaEndTimestamp: schema.StringAttribute{
+				Description: "The timestamp of when the task ended, in RFC-3339 format." +
+					" Null if the task has not ended yet.",
+				Computed: true,
+			},
+			schemaParentEntity: schema.SingleNestedAttribute{
+				Description: "The parent entity associated with the task.",
+				Attributes:  entityAttributes,
+				Computed:    true,
+			},
+			schemaPrimaryEntity: schema.SingleNestedAttribute{
+				Description: "The primary entity associated with the task.",
+				Attributes:  entityAttributes,
+				Computed:    true,
+			},
+			schemaErrorMessages: schema.ListAttribute{
+				Description: "The error messages reported for the task, if it failed.",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			schemaTimeouts: timeouts.Block(ctx),
+		},
+		Description: "Clumio Task Data Source used to read a Clumio task, such as the task" +
+			" started by the last operation of a resource.",
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (r *clumioTaskDataSource) Configure(
+	_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+
+	if req.ProviderData == nil {
+		return
+	}
+
+	r.client = req.ProviderData.(*common.ApiClient)
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *clumioTaskDataSource) Read(
+	ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+
+	var state clumioTaskDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	taskId := state.Id.ValueString()
+
+	if state.WaitUntilTerminal.ValueBool() {
+		readTimeout, diags := state.Timeouts.Read(ctx, common.DefaultOperationTimeout)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		pollCtx, cancel := context.WithTimeout(ctx, readTimeout)
+		defer cancel()
+		err := common.PollTask(pollCtx, r.client, taskId, intervalInSec)
+		var taskErr *common.TaskError
+		if err != nil && !errors.As(err, &taskErr) {
+			summary := fmt.Sprintf("Error waiting for Clumio task %v.", taskId)
+			detail := fmt.Sprintf(errorFmt, err)
+			resp.Diagnostics.AddError(summary, detail)
+			return
+		}
+	}
+
+	task, errorMessages, err := common.ReadTask(r.client.WithContext(ctx), taskId)
+	if err != nil {
+		summary := fmt.Sprintf("Error reading Clumio task %v.", taskId)
+		detail := fmt.Sprintf(errorFmt, err)
+		resp.Diagnostics.AddError(summary, detail)
+		return
+	}
+
+	state.Status = types.StringPointerValue(task.Status)
+	state.Type = types.StringPointerValue(task.ClumioType)
+	state.Category = types.StringPointerValue(task.Category)
+	state.ProgressPercentage = types.Int64PointerValue(task.ProgressPercentage)
+	state.CreatedTimestamp = types.StringPointerValue(task.CreatedTimestamp)
+	state.StartTimestamp = types.StringPointerValue(task.StartTimestamp)
+	state.EndTimestamp = types.StringPointerValue(task.EndTimestamp)
+	state.ParentEntity = nil
+	if task.ParentEntity != nil {
+		state.ParentEntity = taskEntity(task.ParentEntity.Id, task.ParentEntity.ClumioType,
+			task.ParentEntity.Value)
+	}
+	state.PrimaryEntity = nil
+	if task.PrimaryEntity != nil {
+		state.PrimaryEntity = taskEntity(task.PrimaryEntity.Id, task.PrimaryEntity.ClumioType,
+			task.PrimaryEntity.Value)
+	}
+	if errorMessages == nil {
+		errorMessages = []string{}
+	}
+	messages, conversionDiags := types.ListValueFrom(ctx, types.StringType, errorMessages)
+	resp.Diagnostics.Append(conversionDiags...)
+	state.ErrorMessages = messages
+
+	// Set refreshed state.
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// taskEntity converts an entity of the task to its model.
+func taskEntity(id, entityType, value *string) *taskEntityModel {
+	return &taskEntityModel{
+		Id:    types.StringPointerValue(id),
+		Type:  types.StringPointerValue(entityType),
+		Value: types.StringPointerValue(value),
+	}
+}
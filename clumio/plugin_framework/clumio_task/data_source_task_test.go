@@ -0,0 +1,89 @@
+// Copyright 2024. Clumio, Inc.
+//
+// Acceptance test for data_source_task.
+
+package clumio_task_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	clumio_pf "github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework"
+
+	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceClumioTask(t *testing.T) {
+	baseUrl := os.Getenv(common.ClumioApiBaseUrl)
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { clumio_pf.UtilTestAccPreCheckClumio(t) },
+		ProtoV6ProviderFactories: clumio_pf.TestAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: getTestAccDataSourceClumioTask(baseUrl),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(
+						"data.clumio_task.test_task", "id",
+						"clumio_policy_rule.test_policy_rule", "last_task_id"),
+					resource.TestCheckResourceAttr(
+						"data.clumio_task.test_task", "status", common.TaskSuccess),
+					resource.TestCheckResourceAttr(
+						"data.clumio_task.test_task", "progress_percentage", "100"),
+					resource.TestCheckResourceAttrSet(
+						"data.clumio_task.test_task", "type"),
+					resource.TestCheckResourceAttrSet(
+						"data.clumio_task.test_task", "end_timestamp"),
+					resource.TestCheckResourceAttr(
+						"data.clumio_task.test_task", "error_messages.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func getTestAccDataSourceClumioTask(baseUrl string) string {
+	return fmt.Sprintf(testAccDataSourceClumioTask, baseUrl)
+}
+
+const testAccDataSourceClumioTask = `
+provider clumio{
+   clumio_api_base_url = "%s"
+}
+
+resource "clumio_policy" "test_policy" {
+ name = "acceptance-test-task-policy"
+ activation_status = "activated"
+ operations {
+	action_setting = "immediate"
+	type = "aws_ebs_volume_backup"
+	slas {
+		retention_duration {
+			unit = "days"
+			value = 1
+		}
+		rpo_frequency {
+			unit = "days"
+			value = 1
+		}
+	}
+ }
+}
+
+resource "clumio_policy_rule" "test_policy_rule" {
+  name = "acceptance-test-task-policy-rule"
+  policy_id = clumio_policy.test_policy.id
+  before_rule_id = ""
+  condition = "{\"entity_type\":{\"$eq\":\"aws_ebs_volume\"}, \"aws_tag\":{\"$eq\":{\"key\":\"Foo\", \"value\":\"Bar\"}}}"
+  wait_for_completion = false
+}
+
+data "clumio_task" "test_task" {
+  id = clumio_policy_rule.test_policy_rule.last_task_id
+  wait_until_terminal = true
+  timeouts {
+    read = "20m"
+  }
+}
+`
@@ -8,7 +8,6 @@ import (
 	"context"
 	"fmt"
 	"strconv"
-	"strings"
 
 	"github.com/clumio-code/clumio-go-sdk/controllers/users"
 	"github.com/clumio-code/clumio-go-sdk/models"
@@ -25,6 +24,9 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 )
 
+// apiFieldPaths maps the fields of the API requests to the attributes of the resource.
+var apiFieldPaths = common.AttributePaths(schemaEmail, schemaFullName, schemaAssignedRole)
+
 // Ensure the implementation satisfies the expected interfaces.
 var (
 	_ resource.Resource                = &clumioUserResource{}
@@ -198,7 +200,7 @@ func (r *clumioUserResource) Create(
 	fullName := plan.FullName.ValueString()
 
 	if !plan.AssignedRole.IsUnknown() || !plan.OrganizationalUnitIds.IsUnknown() {
-		usersAPI := users.NewUsersV1(r.client.ClumioConfig)
+		usersAPI := users.NewUsersV1(r.client.WithContext(ctx).ClumioConfig())
 		assignedRole := plan.AssignedRole.ValueString()
 		organizationalUnitElements := plan.OrganizationalUnitIds.Elements()
 		organizationalUnitIds := make([]*string, len(organizationalUnitElements))
@@ -214,8 +216,8 @@ func (r *clumioUserResource) Create(
 			OrganizationalUnitIds: organizationalUnitIds,
 		})
 		if apiErr != nil {
-			resp.Diagnostics.AddError("Error creating Clumio User. Error: %v",
-				fmt.Sprintf("Error: %v", string(apiErr.Response)))
+			resp.Diagnostics.Append(common.APIErrorDiagnostics(
+				"Error creating Clumio User.", apiErr, apiFieldPaths)...)
 			return
 		}
 
@@ -262,7 +264,7 @@ func (r *clumioUserResource) Create(
 		return
 	}
 
-	usersAPI := users.NewUsersV2(r.client.ClumioConfig)
+	usersAPI := users.NewUsersV2(r.client.WithContext(ctx).ClumioConfig())
 
 	accessControlConfiguration := make([]*models.RoleForOrganizationalUnits, 0)
 	if !plan.AccessControlConfiguration.IsNull() {
@@ -288,9 +290,8 @@ func (r *clumioUserResource) Create(
 		FullName:                   &fullName,
 	})
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
-			"Error creating Clumio User. Error: %v",
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			"Error creating Clumio User. Error: %v", apiErr, apiFieldPaths)...)
 		return
 	}
 
@@ -308,6 +309,7 @@ func (r *clumioUserResource) Create(
 	plan.AccessControlConfiguration = accessControlCfg
 	plan.AssignedRole = assignedRole
 	plan.OrganizationalUnitIds = ouIds
+	resp.Diagnostics.Append(common.SetPrivateETag(ctx, resp.Private, res.Etag)...)
 
 	// Set the state.
 	diags = resp.State.Set(ctx, plan)
@@ -328,23 +330,25 @@ func (r *clumioUserResource) Read(
 		return
 	}
 
-	usersAPI := users.NewUsersV2(r.client.ClumioConfig)
+	usersAPI := users.NewUsersV2(r.client.WithContext(ctx).ClumioConfig())
 	userId, perr := strconv.ParseInt(state.Id.ValueString(), 10, 64)
 	if perr != nil {
 		resp.Diagnostics.AddError(
 			invalidUserMsg,
 			fmt.Sprintf(invalidUserFmt, state.Id.ValueString()),
 		)
+		return
 	}
 
 	res, apiErr := usersAPI.ReadUser(userId)
 	if apiErr != nil {
-		if strings.Contains(apiErr.Error(), "The resource is not found.") {
-			state.Id = types.StringValue("")
+		if common.NewAPIError(apiErr).IsNotFound() {
+			common.RemoveMissingResource(ctx, resp,
+				fmt.Sprintf("Clumio User %q", state.Email.ValueString()))
+			return
 		}
-		resp.Diagnostics.AddError(
-			"Error retrieving Clumio User.",
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			"Error retrieving Clumio User.", apiErr, nil)...)
 		return
 	}
 
@@ -363,6 +367,9 @@ func (r *clumioUserResource) Read(
 	state.AssignedRole = assignedRole
 	state.OrganizationalUnitIds = ouIds
 
+	// Record the ETag sent as a precondition of the next update or deletion.
+	resp.Diagnostics.Append(common.SetPrivateETag(ctx, resp.Private, res.Etag)...)
+
 	// Set refreshed state
 	diags = resp.State.Set(ctx, state)
 	resp.Diagnostics.Append(diags...)
@@ -396,6 +403,13 @@ func (r *clumioUserResource) Update(
 		return
 	}
 
+	etag, diags := common.GetPrivateETag(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	client := r.client.WithIfMatch(etag)
+
 	if (!plan.AssignedRole.IsUnknown() || !plan.OrganizationalUnitIds.IsUnknown()) &&
 		!plan.AccessControlConfiguration.IsUnknown() {
 		resp.Diagnostics.AddError(
@@ -406,7 +420,7 @@ func (r *clumioUserResource) Update(
 	}
 
 	if !plan.AssignedRole.IsUnknown() || !plan.OrganizationalUnitIds.IsUnknown() {
-		usersAPI := users.NewUsersV1(r.client.ClumioConfig)
+		usersAPI := users.NewUsersV1(client.WithContext(ctx).ClumioConfig())
 		updateRequest := &models.UpdateUserV1Request{}
 
 		if !plan.AssignedRole.IsUnknown() &&
@@ -443,9 +457,9 @@ func (r *clumioUserResource) Update(
 
 		res, apiErr := usersAPI.UpdateUser(userId, updateRequest)
 		if apiErr != nil {
-			resp.Diagnostics.AddError(
+			resp.Diagnostics.Append(common.APIErrorDiagnostics(
 				fmt.Sprintf("Error updating Clumio User id: %v.", plan.Id.ValueString()),
-				fmt.Sprintf("Error: %v", string(apiErr.Response)))
+				apiErr, nil)...)
 			return
 		}
 
@@ -483,6 +497,9 @@ func (r *clumioUserResource) Update(
 		resp.Diagnostics.Append(conversionDiags...)
 		plan.AccessControlConfiguration = accessControlList
 
+		// The response does not carry the new ETag, which the next refresh records.
+		resp.Diagnostics.Append(common.SetPrivateETag(ctx, resp.Private, nil)...)
+
 		// Set state to fully populated data.
 		diags = resp.State.Set(ctx, plan)
 		resp.Diagnostics.Append(diags...)
@@ -493,7 +510,7 @@ func (r *clumioUserResource) Update(
 		return
 	}
 
-	usersAPI := users.NewUsersV2(r.client.ClumioConfig)
+	usersAPI := users.NewUsersV2(client.WithContext(ctx).ClumioConfig())
 	updateRequest := &models.UpdateUserV2Request{}
 	if !plan.FullName.IsUnknown() &&
 		state.FullName != plan.FullName {
@@ -518,9 +535,9 @@ func (r *clumioUserResource) Update(
 
 	res, apiErr := usersAPI.UpdateUser(userId, updateRequest)
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
 			fmt.Sprintf("Error updating Clumio User id: %v.", plan.Id.ValueString()),
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+			apiErr, nil)...)
 		return
 	}
 
@@ -537,6 +554,7 @@ func (r *clumioUserResource) Update(
 	plan.AccessControlConfiguration = accessControlCfg
 	plan.AssignedRole = assignedRole
 	plan.OrganizationalUnitIds = ouIds
+	resp.Diagnostics.Append(common.SetPrivateETag(ctx, resp.Private, res.Etag)...)
 
 	// Set state to fully populated data.
 	diags = resp.State.Set(ctx, plan)
@@ -558,7 +576,13 @@ func (r *clumioUserResource) Delete(
 		return
 	}
 
-	usersAPI := users.NewUsersV2(r.client.ClumioConfig)
+	etag, diags := common.GetPrivateETag(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	usersAPI := users.NewUsersV2(r.client.WithIfMatch(etag).WithContext(ctx).ClumioConfig())
 	userId, perr := strconv.ParseInt(state.Id.ValueString(), 10, 64)
 	if perr != nil {
 		resp.Diagnostics.AddError(
@@ -569,10 +593,9 @@ func (r *clumioUserResource) Delete(
 
 	_, apiErr := usersAPI.DeleteUser(userId)
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
 			fmt.Sprintf(
-				"Error deleting Clumio User %v.", userId),
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+				"Error deleting Clumio User %v.", userId), apiErr, nil)...)
 	}
 }
 
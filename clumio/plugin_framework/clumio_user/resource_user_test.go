@@ -5,11 +5,14 @@
 package clumio_user_test
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"regexp"
+	"strconv"
 	"testing"
 
+	"github.com/clumio-code/clumio-go-sdk/controllers/users"
 	clumio_pf "github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
 
@@ -102,10 +105,34 @@ func TestAccResourceClumioUser(t *testing.T) {
 						regexp.MustCompile(assignedRoleAfter)),
 				),
 			},
+			{
+				ResourceName:      "clumio_user.test_user",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: getTestAccResourceClumioUser(baseUrl, true),
+				Check: clumio_pf.UtilTestCheckResourceDisappears(
+					"clumio_user.test_user", testAccDeleteUser),
+				ExpectNonEmptyPlan: true,
+			},
 		},
 	})
 }
 
+// testAccDeleteUser deletes the user outside of Terraform.
+func testAccDeleteUser(_ context.Context, client *common.ApiClient, id string) error {
+	userId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return err
+	}
+	usersAPI := users.NewUsersV2(client.ClumioConfig())
+	if _, apiErr := usersAPI.DeleteUser(userId); apiErr != nil {
+		return common.NewAPIError(apiErr)
+	}
+	return nil
+}
+
 func getTestAccResourceClumioUser(baseUrl string, update bool) string {
 	orgUnitId := "clumio_organizational_unit.test_ou1.id"
 	assignedRole := assignedRoleBefore
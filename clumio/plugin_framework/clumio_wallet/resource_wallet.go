@@ -20,6 +20,9 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
+// apiFieldPaths maps the fields of the API requests to the attributes of the resource.
+var apiFieldPaths = common.AttributePaths(schemaAccountNativeId)
+
 // Ensure the implementation satisfies the expected interfaces.
 var (
 	_ resource.Resource                = &clumioWalletResource{}
@@ -120,15 +123,14 @@ func (r *clumioWalletResource) Create(
 		return
 	}
 
-	walletsAPI := wallets.NewWalletsV1(r.client.ClumioConfig)
+	walletsAPI := wallets.NewWalletsV1(r.client.WithContext(ctx).ClumioConfig())
 	accountNativeId := plan.AccountNativeId.ValueString()
 	res, apiErr := walletsAPI.CreateWallet(&models.CreateWalletV1Request{
 		AccountNativeId: &accountNativeId,
 	})
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
-			"Error creating Clumio wallet.",
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			"Error creating Clumio wallet.", apiErr, apiFieldPaths)...)
 		return
 	}
 
@@ -156,12 +158,16 @@ func (r *clumioWalletResource) Read(
 		return
 	}
 
-	walletsAPI := wallets.NewWalletsV1(r.client.ClumioConfig)
+	walletsAPI := wallets.NewWalletsV1(r.client.WithContext(ctx).ClumioConfig())
 	res, apiErr := walletsAPI.ReadWallet(state.Id.ValueString())
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
-			"Error reading Clumio wallet.",
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+		if common.NewAPIError(apiErr).IsNotFound() {
+			common.RemoveMissingResource(ctx, resp, fmt.Sprintf(
+				"Clumio wallet for AWS account %s", state.AccountNativeId.ValueString()))
+			return
+		}
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			"Error reading Clumio wallet.", apiErr, nil)...)
 		return
 	}
 
@@ -194,12 +200,11 @@ func (r *clumioWalletResource) Delete(
 		return
 	}
 
-	walletsAPI := wallets.NewWalletsV1(r.client.ClumioConfig)
+	walletsAPI := wallets.NewWalletsV1(r.client.WithContext(ctx).ClumioConfig())
 	_, apiErr := walletsAPI.DeleteWallet(state.Id.ValueString())
 	if apiErr != nil {
-		resp.Diagnostics.AddError(
-			"Error reading Clumio wallet.",
-			fmt.Sprintf(errorFmt, string(apiErr.Response)))
+		resp.Diagnostics.Append(common.APIErrorDiagnostics(
+			"Error reading Clumio wallet.", apiErr, nil)...)
 	}
 }
 
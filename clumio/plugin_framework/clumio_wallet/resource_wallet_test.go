@@ -5,13 +5,17 @@
 package clumio_wallet_test
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"regexp"
 	"testing"
 
+	"github.com/clumio-code/clumio-go-sdk/controllers/wallets"
 	clumio_pf "github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
+	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/mock_api"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
@@ -31,10 +35,66 @@ func TestAccResourceWallet(t *testing.T) {
 						regexp.MustCompile(accountNativeId)),
 				),
 			},
+			{
+				ResourceName:      "clumio_wallet.test_wallet",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: getTestAccResourceWallet(baseUrl, accountNativeId),
+				Check: clumio_pf.UtilTestCheckResourceDisappears(
+					"clumio_wallet.test_wallet", testAccDeleteWallet),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+// TestAccResourceWalletCreateError tests that an error of the API when creating the wallet is
+// reported and that nothing is left in the state. It requires the mock Clumio API.
+func TestAccResourceWalletCreateError(t *testing.T) {
+	accountNativeId := os.Getenv(common.ClumioTestAwsAccountId)
+	baseUrl := os.Getenv(common.ClumioApiBaseUrl)
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			clumio_pf.UtilTestAccPreCheckMockApi(t)
+			clumio_pf.UtilTestAccPreCheckClumio(t)
+		},
+		ProtoV6ProviderFactories: clumio_pf.TestAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() {
+					clumio_pf.TestAccMockApiServer.InjectFault(mock_api.Fault{
+						Method:     http.MethodPost,
+						Path:       "/wallets",
+						StatusCode: http.StatusBadRequest,
+						Message:    "The wallet cannot be created.",
+						Times:      1,
+					})
+				},
+				Config:      getTestAccResourceWallet(baseUrl, accountNativeId),
+				ExpectError: regexp.MustCompile("The wallet cannot be created."),
+			},
+			{
+				Config: getTestAccResourceWallet(baseUrl, accountNativeId),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"clumio_wallet.test_wallet", "account_native_id", accountNativeId),
+				),
+			},
 		},
 	})
 }
 
+// testAccDeleteWallet deletes the wallet outside of Terraform.
+func testAccDeleteWallet(_ context.Context, client *common.ApiClient, id string) error {
+	walletsAPI := wallets.NewWalletsV1(client.ClumioConfig())
+	if _, apiErr := walletsAPI.DeleteWallet(id); apiErr != nil {
+		return common.NewAPIError(apiErr)
+	}
+	return nil
+}
+
 func getTestAccResourceWallet(baseUrl string, accountId string) string {
 	return fmt.Sprintf(testAccResourcePostWallet, baseUrl, accountId)
 }
@@ -0,0 +1,210 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the decoding of the errors returned by the Clumio API.
+
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	apiutils "github.com/clumio-code/clumio-go-sdk/api_utils"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// APIError is an error returned by the Clumio API, decoded from the error envelope of the
+// response body:
+//
+//	{
+//	  "errors": [
+//	    {"error_code": 400, "error_message": "...", "field": "name"}
+//	  ],
+//	  "request_id": "..."
+//	}
+//
+// The field key is only set on validation errors that concern a single field of the
+// request.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Code is the error code of the first error of the envelope.
+	Code string
+	// Message joins the messages of the errors of the envelope. If the body is not an
+	// error envelope, Message is the body itself.
+	Message string
+	// FieldErrors are the errors that concern a single field of the request.
+	FieldErrors []FieldError
+	// RequestId is the ID assigned to the request by the Clumio API, if any.
+	RequestId string
+	// Body is the raw response body.
+	Body []byte
+}
+
+// FieldError is a validation error that concerns a single field of the request.
+type FieldError struct {
+	// Field is the name of the field in the request body.
+	Field string
+	// Message describes why the value of the field was rejected.
+	Message string
+}
+
+// errorEnvelope is the wire format of the Clumio API errors.
+type errorEnvelope struct {
+	Errors []struct {
+		ErrorCode    json.RawMessage `json:"error_code"`
+		ErrorMessage string          `json:"error_message"`
+		Field        string          `json:"field"`
+	} `json:"errors"`
+	ErrorCode    json.RawMessage `json:"error_code"`
+	ErrorMessage string          `json:"error_message"`
+	RequestId    string          `json:"request_id"`
+}
+
+// NewAPIError decodes the error returned by the SDK. It returns nil if apiErr is nil.
+func NewAPIError(apiErr *apiutils.APIError) *APIError {
+	if apiErr == nil {
+		return nil
+	}
+	e := &APIError{
+		StatusCode: apiErr.ResponseCode,
+		Body:       apiErr.Response,
+	}
+	var envelope errorEnvelope
+	if err := json.Unmarshal(apiErr.Response, &envelope); err != nil {
+		e.Message = strings.TrimSpace(string(apiErr.Response))
+		if e.Message == "" {
+			e.Message = apiErr.Reason
+		}
+		return e
+	}
+	e.RequestId = envelope.RequestId
+	messages := make([]string, 0, len(envelope.Errors)+1)
+	if envelope.ErrorMessage != "" {
+		messages = append(messages, envelope.ErrorMessage)
+		e.Code = decodeErrorCode(envelope.ErrorCode)
+	}
+	for _, item := range envelope.Errors {
+		if e.Code == "" {
+			e.Code = decodeErrorCode(item.ErrorCode)
+		}
+		if item.Field != "" {
+			e.FieldErrors = append(e.FieldErrors, FieldError{
+				Field:   item.Field,
+				Message: item.ErrorMessage,
+			})
+			continue
+		}
+		if item.ErrorMessage != "" {
+			messages = append(messages, item.ErrorMessage)
+		}
+	}
+	e.Message = strings.Join(messages, " ")
+	if e.Message == "" && len(e.FieldErrors) == 0 {
+		e.Message = strings.TrimSpace(string(apiErr.Response))
+	}
+	return e
+}
+
+// decodeErrorCode returns the error code, which the API sends either as a number or as a
+// string.
+func decodeErrorCode(raw json.RawMessage) string {
+	code := strings.Trim(strings.TrimSpace(string(raw)), `"`)
+	if code == "null" {
+		return ""
+	}
+	return code
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Message)
+	for _, fieldErr := range e.FieldErrors {
+		if b.Len() > 0 {
+			b.WriteString(" ")
+		}
+		fmt.Fprintf(&b, "%s: %s", fieldErr.Field, fieldErr.Message)
+	}
+	details := []string{fmt.Sprintf("HTTP status %d", e.StatusCode)}
+	if e.Code != "" {
+		details = append(details, fmt.Sprintf("error code %s", e.Code))
+	}
+	if e.RequestId != "" {
+		details = append(details, fmt.Sprintf("request ID %s", e.RequestId))
+	}
+	fmt.Fprintf(&b, " (%s)", strings.Join(details, ", "))
+	return b.String()
+}
+
+// IsNotFound reports whether the requested object does not exist.
+func (e *APIError) IsNotFound() bool {
+	return e != nil && e.StatusCode == http.StatusNotFound
+}
+
+// IsConflict reports whether the request conflicts with the current state of the object,
+// such as when the object is locked by another operation or already exists.
+func (e *APIError) IsConflict() bool {
+	return e != nil && e.StatusCode == http.StatusConflict
+}
+
+// IsPreconditionFailed reports whether the request was rejected because the object no
+// longer has the ETag sent in the If-Match header, as it was changed in the meantime.
+func (e *APIError) IsPreconditionFailed() bool {
+	return e != nil && e.StatusCode == http.StatusPreconditionFailed
+}
+
+// IsThrottled reports whether the request was rejected because of rate limiting.
+func (e *APIError) IsThrottled() bool {
+	return e != nil && e.StatusCode == http.StatusTooManyRequests
+}
+
+// IsValidation reports whether the request was rejected because of invalid input.
+func (e *APIError) IsValidation() bool {
+	return e != nil && (e.StatusCode == http.StatusBadRequest ||
+		e.StatusCode == http.StatusUnprocessableEntity)
+}
+
+// APIErrorDiagnostics returns the diagnostics describing the error returned by the SDK.
+// Field errors whose field is a key of fieldPaths are reported against the corresponding
+// attribute so that Terraform points at the offending configuration. The other errors are
+// reported as a single diagnostic with the given summary.
+func APIErrorDiagnostics(summary string, apiErr *apiutils.APIError,
+	fieldPaths map[string]path.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+	e := NewAPIError(apiErr)
+	if e == nil {
+		return diags
+	}
+	if e.IsPreconditionFailed() {
+		diags.AddError(summary, fmt.Sprintf("%s\n\nError: %v", preconditionFailedDetail, e))
+		return diags
+	}
+	var unmapped []FieldError
+	for _, fieldErr := range e.FieldErrors {
+		attrPath, ok := fieldPaths[fieldErr.Field]
+		if !ok {
+			unmapped = append(unmapped, fieldErr)
+			continue
+		}
+		diags.AddAttributeError(attrPath, summary, fmt.Sprintf("Error: %s", fieldErr.Message))
+	}
+	if e.Message != "" || len(unmapped) > 0 || !diags.HasError() {
+		general := *e
+		general.FieldErrors = unmapped
+		diags.AddError(summary, fmt.Sprintf("Error: %v", &general))
+	}
+	return diags
+}
+
+// AttributePaths returns the field paths of APIErrorDiagnostics for the fields of a request
+// body that share their name with a root attribute of the schema.
+func AttributePaths(names ...string) map[string]path.Path {
+	paths := make(map[string]path.Path, len(names))
+	for _, name := range names {
+		paths[name] = path.Root(name)
+	}
+	return paths
+}
@@ -0,0 +1,217 @@
+// Copyright 2024. Clumio, Inc.
+
+// Unit tests of the decoding of the errors returned by the Clumio API.
+package common
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	apiutils "github.com/clumio-code/clumio-go-sdk/api_utils"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+func TestNewAPIError(t *testing.T) {
+	for name, test := range map[string]struct {
+		status int
+		body   string
+		reason string
+		want   APIError
+	}{
+		"envelope": {
+			status: http.StatusBadRequest,
+			body: `{"errors": [{"error_code": 400, "error_message": "Invalid request."},` +
+				` {"error_code": 400, "error_message": "Too long.", "field": "name"}],` +
+				` "request_id": "request-1"}`,
+			want: APIError{
+				StatusCode:  http.StatusBadRequest,
+				Code:        "400",
+				Message:     "Invalid request.",
+				FieldErrors: []FieldError{{Field: "name", Message: "Too long."}},
+				RequestId:   "request-1",
+			},
+		},
+		"top-level error with a string code": {
+			status: http.StatusConflict,
+			body:   `{"error_code": "policy_locked", "error_message": "The policy is locked."}`,
+			want: APIError{
+				StatusCode: http.StatusConflict,
+				Code:       "policy_locked",
+				Message:    "The policy is locked.",
+			},
+		},
+		"null error code": {
+			status: http.StatusNotFound,
+			body:   `{"errors": [{"error_code": null, "error_message": "Not found."}]}`,
+			want: APIError{
+				StatusCode: http.StatusNotFound,
+				Message:    "Not found.",
+			},
+		},
+		"unknown fields": {
+			status: http.StatusInternalServerError,
+			body:   `{"status": "error", "detail": {"reason": "unexpected"}}`,
+			want: APIError{
+				StatusCode: http.StatusInternalServerError,
+				Message:    `{"status": "error", "detail": {"reason": "unexpected"}}`,
+			},
+		},
+		"malformed body": {
+			status: http.StatusBadGateway,
+			body:   "  <html>Bad Gateway</html>\n",
+			want: APIError{
+				StatusCode: http.StatusBadGateway,
+				Message:    "<html>Bad Gateway</html>",
+			},
+		},
+		"empty body": {
+			status: http.StatusServiceUnavailable,
+			reason: "503 Service Unavailable",
+			want: APIError{
+				StatusCode: http.StatusServiceUnavailable,
+				Message:    "503 Service Unavailable",
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got := NewAPIError(&apiutils.APIError{
+				ResponseCode: test.status,
+				Reason:       test.reason,
+				Response:     []byte(test.body),
+			})
+			test.want.Body = []byte(test.body)
+			if !reflect.DeepEqual(*got, test.want) {
+				t.Errorf("got %+v, want %+v", *got, test.want)
+			}
+		})
+	}
+
+	if got := NewAPIError(nil); got != nil {
+		t.Errorf("got %v for a nil error, want nil", got)
+	}
+}
+
+func TestAPIErrorError(t *testing.T) {
+	e := &APIError{
+		StatusCode:  http.StatusBadRequest,
+		Code:        "400",
+		Message:     "Invalid request.",
+		FieldErrors: []FieldError{{Field: "name", Message: "Too long."}},
+		RequestId:   "request-1",
+	}
+	want := "Invalid request. name: Too long. (HTTP status 400, error code 400," +
+		" request ID request-1)"
+	if got := e.Error(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestAPIErrorPredicates(t *testing.T) {
+	predicates := map[string]func(*APIError) bool{
+		"IsNotFound":           (*APIError).IsNotFound,
+		"IsConflict":           (*APIError).IsConflict,
+		"IsPreconditionFailed": (*APIError).IsPreconditionFailed,
+		"IsThrottled":          (*APIError).IsThrottled,
+		"IsValidation":         (*APIError).IsValidation,
+	}
+	for status, want := range map[int]string{
+		http.StatusNotFound:            "IsNotFound",
+		http.StatusConflict:            "IsConflict",
+		http.StatusPreconditionFailed:  "IsPreconditionFailed",
+		http.StatusTooManyRequests:     "IsThrottled",
+		http.StatusBadRequest:          "IsValidation",
+		http.StatusUnprocessableEntity: "IsValidation",
+		http.StatusInternalServerError: "",
+	} {
+		e := &APIError{StatusCode: status}
+		for name, predicate := range predicates {
+			if got := predicate(e); got != (name == want) {
+				t.Errorf("got %s %t for the status %d", name, got, status)
+			}
+		}
+	}
+
+	var nilErr *APIError
+	for name, predicate := range predicates {
+		if predicate(nilErr) {
+			t.Errorf("got %s true for a nil error", name)
+		}
+	}
+}
+
+func TestAPIErrorDiagnostics(t *testing.T) {
+	fieldPaths := AttributePaths("name")
+	for name, test := range map[string]struct {
+		status int
+		body   string
+		// wantPaths are the attribute paths of the diagnostics, the empty path standing for
+		// a diagnostic without a path.
+		wantPaths  []path.Path
+		wantDetail string
+	}{
+		"mapped field": {
+			status:     http.StatusBadRequest,
+			body:       `{"errors": [{"error_message": "Too long.", "field": "name"}]}`,
+			wantPaths:  []path.Path{path.Root("name")},
+			wantDetail: "Too long.",
+		},
+		"mapped field and message": {
+			status: http.StatusBadRequest,
+			body: `{"errors": [{"error_message": "Invalid request."},` +
+				` {"error_message": "Too long.", "field": "name"}]}`,
+			wantPaths:  []path.Path{path.Root("name"), path.Empty()},
+			wantDetail: "Invalid request.",
+		},
+		"unknown field": {
+			status:     http.StatusBadRequest,
+			body:       `{"errors": [{"error_message": "Unknown.", "field": "color"}]}`,
+			wantPaths:  []path.Path{path.Empty()},
+			wantDetail: "color: Unknown.",
+		},
+		"malformed body": {
+			status:     http.StatusInternalServerError,
+			body:       "internal error",
+			wantPaths:  []path.Path{path.Empty()},
+			wantDetail: "internal error (HTTP status 500)",
+		},
+		"precondition failed": {
+			status:     http.StatusPreconditionFailed,
+			body:       `{"errors": [{"error_message": "ETag mismatch.", "field": "name"}]}`,
+			wantPaths:  []path.Path{path.Empty()},
+			wantDetail: preconditionFailedDetail,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			diags := APIErrorDiagnostics("Error updating.", &apiutils.APIError{
+				ResponseCode: test.status,
+				Response:     []byte(test.body),
+			}, fieldPaths)
+			if len(diags) != len(test.wantPaths) {
+				t.Fatalf("got the diagnostics %v, want %d", diags, len(test.wantPaths))
+			}
+			for i, d := range diags {
+				gotPath := path.Empty()
+				if withPath, ok := d.(diag.DiagnosticWithPath); ok {
+					gotPath = withPath.Path()
+				}
+				if !gotPath.Equal(test.wantPaths[i]) {
+					t.Errorf("got the path %q, want %q", gotPath, test.wantPaths[i])
+				}
+				if d.Summary() != "Error updating." {
+					t.Errorf("got the summary %q", d.Summary())
+				}
+			}
+			if !strings.Contains(diags[len(diags)-1].Detail(), test.wantDetail) {
+				t.Errorf("got the detail %q, want it to contain %q",
+					diags[len(diags)-1].Detail(), test.wantDetail)
+			}
+		})
+	}
+
+	if diags := APIErrorDiagnostics("Error updating.", nil, fieldPaths); diags.HasError() {
+		t.Errorf("got the diagnostics %v for a nil error", diags)
+	}
+}
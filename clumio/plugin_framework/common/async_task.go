@@ -0,0 +1,160 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the attributes and checks shared by the resources that can return without
+// waiting for the Clumio tasks they start.
+
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// WaitForCompletionAttribute returns the wait_for_completion attribute of the resources
+// that can return without waiting for the Clumio tasks they start.
+func WaitForCompletionAttribute() schema.BoolAttribute {
+	return schema.BoolAttribute{
+		Description: "Whether to wait for the Clumio tasks started by the resource to" +
+			" complete. When set to false, the operations return as soon as the task is" +
+			" started and its ID is recorded in last_task_id. A failure of the task is then" +
+			" reported by the next refresh. Defaults to true.",
+		Optional: true,
+		Computed: true,
+		Default:  booldefault.StaticBool(true),
+	}
+}
+
+// LastTaskIdAttribute returns the last_task_id attribute of the resources that can return
+// without waiting for the Clumio tasks they start.
+func LastTaskIdAttribute() schema.StringAttribute {
+	return schema.StringAttribute{
+		Description: "The Clumio-assigned ID of the task started by the last create or" +
+			" update of the resource, if any.",
+		Computed: true,
+	}
+}
+
+// FailOnTaskErrorAttribute returns the fail_on_task_error attribute of the resources that
+// can return without waiting for the Clumio tasks they start.
+func FailOnTaskErrorAttribute() schema.BoolAttribute {
+	return schema.BoolAttribute{
+		Description: "Set to true to report a failure of the task recorded in" +
+			" last_task_id as an error instead of a warning when the resource is" +
+			" refreshed. Only used when wait_for_completion is false. Defaults to false.",
+		Optional: true,
+		Computed: true,
+		Default:  booldefault.StaticBool(false),
+	}
+}
+
+// SetTaskAttributeDefaults sets the defaults of wait_for_completion and fail_on_task_error
+// when they are missing from the state, such as after an import.
+func SetTaskAttributeDefaults(waitForCompletion *types.Bool, failOnTaskError *types.Bool) {
+	if waitForCompletion.IsNull() {
+		*waitForCompletion = types.BoolValue(true)
+	}
+	if failOnTaskError.IsNull() {
+		*failOnTaskError = types.BoolValue(false)
+	}
+}
+
+// privateStateLastTaskKey is the private state key of the outcome of the task recorded in
+// last_task_id, once the task has ended.
+const privateStateLastTaskKey = "last_task"
+
+// lastTaskOutcome is the outcome of an ended task, recorded in the private state so that the
+// task is not read again by the next refreshes.
+type lastTaskOutcome struct {
+	TaskId string `json:"task_id"`
+	// Error describes the failure of the task, and is empty if the task completed or no
+	// longer exists.
+	Error string `json:"error,omitempty"`
+}
+
+// LastTaskDiagnostics checks the task recorded in last_task_id when the resource does not
+// wait for its tasks. A failure of the task is reported as a warning, or as an error if
+// failOnTaskError is true. The returned pending flag reports whether the task is still
+// running, in which case the object may not reflect the configuration yet. Tasks that can
+// no longer be read are ignored. Once the task has ended, its outcome is recorded in the
+// private state and reported again by the next refreshes without reading the task.
+func LastTaskDiagnostics(ctx context.Context, apiClient *ApiClient, private PrivateState,
+	waitForCompletion types.Bool, lastTaskId types.String, failOnTaskError types.Bool,
+	object string) (pending bool, diags diag.Diagnostics) {
+	if waitForCompletion.ValueBool() || lastTaskId.ValueString() == "" {
+		return false, diags
+	}
+	taskId := lastTaskId.ValueString()
+	outcome, diags := getLastTaskOutcome(ctx, private)
+	if diags.HasError() {
+		return false, diags
+	}
+	if outcome == nil || outcome.TaskId != taskId {
+		status, err := ReadTaskStatus(apiClient.WithContext(ctx), taskId)
+		var taskErr *TaskError
+		var apiErr *APIError
+		switch {
+		case errors.As(err, &taskErr):
+			outcome = &lastTaskOutcome{TaskId: taskId, Error: err.Error()}
+		case errors.As(err, &apiErr) && apiErr.IsNotFound():
+			tflog.Debug(ctx, "The last task of the resource no longer exists", map[string]any{
+				"task_id": taskId,
+			})
+			outcome = &lastTaskOutcome{TaskId: taskId}
+		case err != nil:
+			diags.AddWarning(fmt.Sprintf("Unable to check the last task of %s.", object),
+				fmt.Sprintf("Error: %v", err))
+			return false, diags
+		case status != TaskSuccess:
+			return true, diags
+		default:
+			outcome = &lastTaskOutcome{TaskId: taskId}
+		}
+		diags.Append(setLastTaskOutcome(ctx, private, outcome)...)
+	}
+	if outcome.Error != "" {
+		summary := fmt.Sprintf("The last task of %s did not complete.", object)
+		detail := fmt.Sprintf("Error: %s", outcome.Error)
+		if failOnTaskError.ValueBool() {
+			diags.AddError(summary, detail)
+		} else {
+			diags.AddWarning(summary, detail)
+		}
+	}
+	return false, diags
+}
+
+// getLastTaskOutcome returns the outcome of the ended task recorded in the private state, or
+// nil if there is none.
+func getLastTaskOutcome(ctx context.Context, private PrivateState) (
+	*lastTaskOutcome, diag.Diagnostics) {
+	encoded, diags := private.GetKey(ctx, privateStateLastTaskKey)
+	if diags.HasError() || len(encoded) == 0 {
+		return nil, diags
+	}
+	outcome := &lastTaskOutcome{}
+	if err := json.Unmarshal(encoded, outcome); err != nil {
+		diags.AddError("Unable to read the outcome of the last task.", err.Error())
+		return nil, diags
+	}
+	return outcome, diags
+}
+
+// setLastTaskOutcome records the outcome of the ended task in the private state.
+func setLastTaskOutcome(ctx context.Context, private PrivateState,
+	outcome *lastTaskOutcome) diag.Diagnostics {
+	encoded, err := json.Marshal(outcome)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Unable to record the outcome of the last task.", err.Error())
+		return diags
+	}
+	return private.SetKey(ctx, privateStateLastTaskKey, encoded)
+}
@@ -0,0 +1,78 @@
+// Copyright 2024. Clumio, Inc.
+
+// Unit tests of the checks of the tasks the resources do not wait for.
+package common
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	clumioConfig "github.com/clumio-code/clumio-go-sdk/config"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestLastTaskDiagnostics(t *testing.T) {
+	for name, test := range map[string]struct {
+		status      string
+		wantReads   int32
+		wantPending bool
+		wantWarning bool
+	}{
+		"completed":   {status: TaskSuccess, wantReads: 1},
+		"failed":      {status: TaskFailed, wantReads: 1, wantWarning: true},
+		"in progress": {status: "in_progress", wantReads: 2, wantPending: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var reads atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(
+				func(w http.ResponseWriter, _ *http.Request) {
+					reads.Add(1)
+					w.Header().Set("Content-Type", "application/json")
+					fmt.Fprintf(w, `{"id": "task-1", "status": %q}`, test.status)
+				}))
+			defer server.Close()
+			client, err := NewApiClient(context.Background(),
+				clumioConfig.Config{BaseUrl: server.URL}, ApiClientOptions{
+					Retry: RetryConfig{
+						MaxRetries: DefaultMaxRetries, MaxBackoff: DefaultRetryMaxBackoff,
+					},
+				})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+
+			// The second refresh reads the task again only if it was still pending.
+			private := mapPrivateState{}
+			for i := 0; i < 2; i++ {
+				pending, diags := LastTaskDiagnostics(context.Background(), client, private,
+					types.BoolValue(false), types.StringValue("task-1"),
+					types.BoolValue(false), "policy")
+				if diags.HasError() {
+					t.Fatalf("got errors %v", diags)
+				}
+				if pending != test.wantPending {
+					t.Errorf("got pending %t, want %t", pending, test.wantPending)
+				}
+				if got := diags.WarningsCount() > 0; got != test.wantWarning {
+					t.Errorf("got warnings %v, want a warning: %t", diags, test.wantWarning)
+				}
+			}
+			if got := reads.Load(); got != test.wantReads {
+				t.Errorf("got %d reads of the task, want %d", got, test.wantReads)
+			}
+
+			// A new task is read even though the outcome of the previous one is recorded.
+			reads.Store(0)
+			LastTaskDiagnostics(context.Background(), client, private, types.BoolValue(false),
+				types.StringValue("task-2"), types.BoolValue(false), "policy")
+			if got := reads.Load(); got != 1 {
+				t.Errorf("got %d reads of a new task, want 1", got)
+			}
+		})
+	}
+}
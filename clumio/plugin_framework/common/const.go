@@ -6,10 +6,14 @@ const (
 	ClumioApiToken                  = "CLUMIO_API_TOKEN"
 	ClumioApiBaseUrl                = "CLUMIO_API_BASE_URL"
 	ClumioOrganizationalUnitContext = "CLUMIO_ORGANIZATIONAL_UNIT_CONTEXT"
+	ClumioProfile                   = "CLUMIO_PROFILE"
+	ClumioCredentialsFile           = "CLUMIO_CREDENTIALS_FILE"
 	AwsAccessKeyId                  = "AWS_ACCESS_KEY_ID"
 	AwsSecretAccessKey              = "AWS_SECRET_ACCESS_KEY"
 	AwsRegion                       = "AWS_REGION"
 	ClumioTestAwsAccountId          = "CLUMIO_TEST_AWS_ACCOUNT_ID"
+	ClumioTestMockApi               = "CLUMIO_TEST_MOCK_API"
+	ClumioTestCassetteMode          = "CLUMIO_TEST_CASSETTE_MODE"
 
 	TaskSuccess = "completed"
 	TaskAborted = "aborted"
@@ -0,0 +1,138 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the functions used to read named profiles from the Clumio credentials file.
+
+package common
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// DefaultProfile is the profile used when neither the profile provider attribute nor
+	// the CLUMIO_PROFILE environment variable is set.
+	DefaultProfile = "default"
+
+	// credentialsDir and credentialsFile form the default location of the credentials
+	// file, relative to the home directory of the user.
+	credentialsDir  = ".clumio"
+	credentialsFile = "credentials"
+
+	// Keys recognized in a profile of the credentials file.
+	profileKeyApiToken                  = "clumio_api_token"
+	profileKeyApiBaseUrl                = "clumio_api_base_url"
+	profileKeyOrganizationalUnitContext = "clumio_organizational_unit_context"
+	profileKeyTokenCommand              = "token_command"
+)
+
+// ErrProfileNotFound is returned by LoadCredentialsProfile when the credentials file does
+// not contain the requested profile.
+var ErrProfileNotFound = errors.New("profile not found")
+
+// CredentialsProfile holds the settings of a named profile of the credentials file.
+type CredentialsProfile struct {
+	Name                      string
+	ApiToken                  string
+	ApiBaseUrl                string
+	OrganizationalUnitContext string
+	TokenCommand              string
+}
+
+// CredentialsFilePath returns the path of the credentials file. The CLUMIO_CREDENTIALS_FILE
+// environment variable overrides the default of ~/.clumio/credentials.
+func CredentialsFilePath() (string, error) {
+	if path := os.Getenv(ClumioCredentialsFile); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to locate the Clumio credentials file: %w", err)
+	}
+	return filepath.Join(home, credentialsDir, credentialsFile), nil
+}
+
+// LoadCredentialsProfile reads the named profile from the credentials file at path. The
+// file uses the INI format, with one section per profile:
+//
+//	[default]
+//	clumio_api_token = ...
+//	clumio_api_base_url = https://us-west-2.api.clumio.com
+//	clumio_organizational_unit_context = ...
+//	token_command = ...
+//
+// If the file does not exist, the returned error wraps fs.ErrNotExist.
+func LoadCredentialsProfile(path string, name string) (*CredentialsProfile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the Clumio credentials file: %w", err)
+	}
+	defer file.Close()
+
+	var profile *CredentialsProfile
+	section := ""
+	scanner := bufio.NewScanner(file)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("%s:%d: invalid profile header %q", path, lineNum, line)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if section == name && profile == nil {
+				profile = &CredentialsProfile{Name: name}
+			}
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("%s:%d: expected a key = value pair", path, lineNum)
+		}
+		if section != name {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		// Only quotes enclosing the whole value are removed so that the quoted arguments
+		// of token_command are preserved.
+		if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) &&
+			!strings.Contains(value[1:len(value)-1], `"`) {
+			value = value[1 : len(value)-1]
+		}
+		switch key {
+		case profileKeyApiToken:
+			profile.ApiToken = value
+		case profileKeyApiBaseUrl:
+			profile.ApiBaseUrl = value
+		case profileKeyOrganizationalUnitContext:
+			profile.OrganizationalUnitContext = value
+		case profileKeyTokenCommand:
+			profile.TokenCommand = value
+		default:
+			return nil, fmt.Errorf("%s:%d: unknown key %q in profile %q", path, lineNum, key,
+				name)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read the Clumio credentials file: %w", err)
+	}
+	if profile == nil {
+		return nil, fmt.Errorf("%w: %q in the Clumio credentials file %s", ErrProfileNotFound,
+			name, path)
+	}
+	return profile, nil
+}
+
+// IsProfileNotFound reports whether the error returned by LoadCredentialsProfile is due to
+// the credentials file or the profile not existing.
+func IsProfileNotFound(err error) bool {
+	return errors.Is(err, fs.ErrNotExist) || errors.Is(err, ErrProfileNotFound)
+}
@@ -0,0 +1,146 @@
+// Copyright 2024. Clumio, Inc.
+
+// Unit tests of the profiles of the Clumio credentials file.
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testCredentialsFile is a credentials file with comments, quoted values and several
+// profiles. The second section of the default profile overrides its token.
+const testCredentialsFile = `# Clumio credentials
+; written by hand
+
+[default]
+clumio_api_token = default-token
+clumio_api_base_url=https://us-west-2.api.clumio.com
+
+[ci]
+clumio_api_base_url = "https://eu-central-1.api.clumio.com"
+clumio_organizational_unit_context = ou-1
+token_command = secrets-broker mint clumio --scope "backup admin"
+
+[ default ]
+clumio_api_token = overriding-token
+`
+
+// writeCredentialsFile writes the content to a credentials file and returns its path.
+func writeCredentialsFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "credentials")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadCredentialsProfile(t *testing.T) {
+	for name, test := range map[string]struct {
+		content string
+		profile string
+		want    *CredentialsProfile
+		// wantErr is a part of the expected error, if any.
+		wantErr string
+	}{
+		"default profile": {
+			content: testCredentialsFile,
+			profile: DefaultProfile,
+			want: &CredentialsProfile{
+				Name:       DefaultProfile,
+				ApiToken:   "overriding-token",
+				ApiBaseUrl: "https://us-west-2.api.clumio.com",
+			},
+		},
+		"quoted values": {
+			content: testCredentialsFile,
+			profile: "ci",
+			want: &CredentialsProfile{
+				Name:                      "ci",
+				ApiBaseUrl:                "https://eu-central-1.api.clumio.com",
+				OrganizationalUnitContext: "ou-1",
+				TokenCommand:              `secrets-broker mint clumio --scope "backup admin"`,
+			},
+		},
+		"missing profile": {
+			content: testCredentialsFile,
+			profile: "prod",
+			wantErr: `profile not found: "prod"`,
+		},
+		"empty file": {
+			profile: DefaultProfile,
+			wantErr: `profile not found: "default"`,
+		},
+		"invalid header": {
+			content: "[default\nclumio_api_token = token\n",
+			profile: DefaultProfile,
+			wantErr: `:1: invalid profile header "[default"`,
+		},
+		"missing value": {
+			content: "[other]\nclumio_api_token\n",
+			profile: DefaultProfile,
+			wantErr: ":2: expected a key = value pair",
+		},
+		"unknown key": {
+			content: "[default]\n# comment\nclumio_region = us-west-2\n",
+			profile: DefaultProfile,
+			wantErr: `:3: unknown key "clumio_region" in profile "default"`,
+		},
+		"unknown key of another profile": {
+			content: "[other]\nclumio_region = us-west-2\n[default]\n",
+			profile: DefaultProfile,
+			want:    &CredentialsProfile{Name: DefaultProfile},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := writeCredentialsFile(t, test.content)
+			got, err := LoadCredentialsProfile(path, test.profile)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("got the error %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestIsProfileNotFound(t *testing.T) {
+	path := writeCredentialsFile(t, "[default]\nclumio_api_token = token\n")
+	_, err := LoadCredentialsProfile(path, "prod")
+	if !IsProfileNotFound(err) {
+		t.Errorf("got %v for a missing profile, want a profile not found error", err)
+	}
+	_, err = LoadCredentialsProfile(filepath.Join(t.TempDir(), "credentials"), DefaultProfile)
+	if !IsProfileNotFound(err) {
+		t.Errorf("got %v for a missing file, want a profile not found error", err)
+	}
+	_, err = LoadCredentialsProfile(writeCredentialsFile(t, "[default"), DefaultProfile)
+	if err == nil || IsProfileNotFound(err) {
+		t.Errorf("got %v for an invalid file, want another error", err)
+	}
+}
+
+func TestCredentialsFilePath(t *testing.T) {
+	t.Setenv(ClumioCredentialsFile, "/etc/clumio/credentials")
+	if got, err := CredentialsFilePath(); err != nil || got != "/etc/clumio/credentials" {
+		t.Errorf("got %q and the error %v, want the path of %s", got, err,
+			ClumioCredentialsFile)
+	}
+	t.Setenv(ClumioCredentialsFile, "")
+	t.Setenv("HOME", "/home/clumio")
+	want := filepath.Join("/home/clumio", ".clumio", "credentials")
+	if got, err := CredentialsFilePath(); err != nil || got != want {
+		t.Errorf("got %q and the error %v, want %q", got, err, want)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the optimistic concurrency control of the updates and deletions, based on the
+// ETags of the Clumio objects. The ETag read by the last refresh of a resource is kept in
+// its private state and sent in the If-Match header of the next update or deletion, so that
+// the API rejects it if the object was changed outside Terraform in the meantime.
+//
+// The objects whose API responses carry no ETag, such as policies and protection groups, are
+// protected by their version instead, such as the updated time of a policy. The version read
+// by the last refresh is kept in the private state, and compared with the version read again
+// right before the update or deletion is sent. Unlike an If-Match precondition, this leaves a
+// short window during which a change made outside Terraform can still be overwritten.
+
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+const (
+	// privateStateETagKey is the private state key of the ETag of the object.
+	privateStateETagKey = "etag"
+
+	// privateStateVersionKey is the private state key of the version of the object, for the
+	// objects without an ETag.
+	privateStateVersionKey = "version"
+
+	// ifMatchHeader is the header holding the ETag the object must still have for the
+	// request to be applied.
+	ifMatchHeader = "If-Match"
+
+	// preconditionFailedDetail describes the errors of the requests rejected because the
+	// object no longer has the ETag sent in the If-Match header.
+	preconditionFailedDetail = "The object was changed outside Terraform since the plan" +
+		" was made, so the change was not applied to avoid overwriting it. Re-run" +
+		" terraform plan to review the current state of the object, then apply again."
+)
+
+// PrivateState is the private state of a resource, such as resource.ReadResponse.Private.
+type PrivateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// SetPrivateETag records the ETag of the object in the private state of the resource. A nil
+// or empty ETag clears the one previously recorded, so that the next update is not
+// conditional.
+func SetPrivateETag(ctx context.Context, private PrivateState, etag *string) diag.Diagnostics {
+	value := ""
+	if etag != nil {
+		value = *etag
+	}
+	return setPrivateString(ctx, private, privateStateETagKey, value, "ETag")
+}
+
+// GetPrivateETag returns the ETag recorded in the private state of the resource, or an empty
+// string if there is none.
+func GetPrivateETag(ctx context.Context, private PrivateState) (string, diag.Diagnostics) {
+	return getPrivateString(ctx, private, privateStateETagKey, "ETag")
+}
+
+// SetPrivateVersion records the version of an object without an ETag in the private state of
+// the resource. An empty version clears the one previously recorded, so that the next update
+// is not checked.
+func SetPrivateVersion(ctx context.Context, private PrivateState, version string) diag.Diagnostics {
+	return setPrivateString(ctx, private, privateStateVersionKey, version, "version")
+}
+
+// CheckPrivateVersion reports an error with the given summary if the current version of the
+// object, read right before it is updated or deleted, differs from the version recorded by
+// the last refresh, as the object was then changed outside Terraform since the plan was made.
+// Nothing is checked if either version is empty.
+func CheckPrivateVersion(ctx context.Context, private PrivateState, current string,
+	summary string) diag.Diagnostics {
+	recorded, diags := getPrivateString(ctx, private, privateStateVersionKey, "version")
+	if diags.HasError() || recorded == "" || current == "" || recorded == current {
+		return diags
+	}
+	diags.AddError(summary, fmt.Sprintf("%s\n\nError: the object has version %s, expected %s.",
+		preconditionFailedDetail, current, recorded))
+	return diags
+}
+
+// setPrivateString records the string value under the key of the private state. The name
+// describes the value in the errors.
+func setPrivateString(ctx context.Context, private PrivateState, key string, value string,
+	name string) diag.Diagnostics {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError(fmt.Sprintf("Unable to record the %s of the object.", name), err.Error())
+		return diags
+	}
+	return private.SetKey(ctx, key, encoded)
+}
+
+// getPrivateString returns the string value recorded under the key of the private state, or
+// an empty string if there is none. The name describes the value in the errors.
+func getPrivateString(ctx context.Context, private PrivateState, key string, name string) (
+	string, diag.Diagnostics) {
+	encoded, diags := private.GetKey(ctx, key)
+	if diags.HasError() || len(encoded) == 0 {
+		return "", diags
+	}
+	var value string
+	if err := json.Unmarshal(encoded, &value); err != nil {
+		diags.AddError(fmt.Sprintf("Unable to read the %s of the object.", name), err.Error())
+		return "", diags
+	}
+	return value, diags
+}
+
+// WithIfMatch returns a client whose API calls are only applied if the object still has the
+// given ETag. Otherwise the API rejects them with a 412 status, which APIErrorDiagnostics
+// reports as a change made outside Terraform. If etag is empty, the receiver is returned.
+func (c *ApiClient) WithIfMatch(etag string) *ApiClient {
+	if etag == "" {
+		return c
+	}
+	scoped := *c
+	scoped.config = c.ClumioConfig()
+	if scoped.config.CustomHeaders == nil {
+		scoped.config.CustomHeaders = map[string]string{}
+	}
+	scoped.config.CustomHeaders[ifMatchHeader] = etag
+	return &scoped
+}
@@ -0,0 +1,65 @@
+// Copyright 2024. Clumio, Inc.
+
+// Unit tests of the optimistic concurrency control of the updates and deletions.
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// mapPrivateState is a PrivateState backed by a map.
+type mapPrivateState map[string][]byte
+
+// GetKey implements PrivateState.
+func (m mapPrivateState) GetKey(_ context.Context, key string) ([]byte, diag.Diagnostics) {
+	return m[key], nil
+}
+
+// SetKey implements PrivateState.
+func (m mapPrivateState) SetKey(_ context.Context, key string, value []byte) diag.Diagnostics {
+	m[key] = value
+	return nil
+}
+
+func TestCheckPrivateVersion(t *testing.T) {
+	ctx := context.Background()
+	for name, test := range map[string]struct {
+		recorded  string
+		current   string
+		wantError bool
+	}{
+		"unchanged":          {recorded: "1700000000", current: "1700000000"},
+		"changed":            {recorded: "1700000000", current: "1700000060", wantError: true},
+		"never recorded":     {current: "1700000000"},
+		"not returned":       {recorded: "1700000000"},
+		"cleared and unread": {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			private := mapPrivateState{}
+			if diags := SetPrivateVersion(ctx, private, test.recorded); diags.HasError() {
+				t.Fatal(diags)
+			}
+			diags := CheckPrivateVersion(ctx, private, test.current, "Error updating.")
+			if diags.HasError() != test.wantError {
+				t.Errorf("got errors %v, want an error: %t", diags, test.wantError)
+			}
+		})
+	}
+}
+
+func TestPrivateETagAndVersionAreKeptApart(t *testing.T) {
+	ctx := context.Background()
+	private := mapPrivateState{}
+	etag := "etag-1"
+	SetPrivateETag(ctx, private, &etag)
+	SetPrivateVersion(ctx, private, "2")
+	if got, _ := GetPrivateETag(ctx, private); got != etag {
+		t.Errorf("got the ETag %q, want %q", got, etag)
+	}
+	if diags := CheckPrivateVersion(ctx, private, "2", "Error updating."); diags.HasError() {
+		t.Errorf("got errors %v for the recorded version", diags)
+	}
+}
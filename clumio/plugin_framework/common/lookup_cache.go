@@ -0,0 +1,154 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the cache of the reference data looked up by the resources and data sources.
+
+package common
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	apiutils "github.com/clumio-code/clumio-go-sdk/api_utils"
+	sdkCommon "github.com/clumio-code/clumio-go-sdk/common"
+)
+
+// DefaultLookupCacheTTL is how long a lookup stays cached. The cache lives in the memory of
+// the provider process, so nothing outlives a Terraform run.
+const DefaultLookupCacheTTL = 5 * time.Minute
+
+// LookupKind identifies a type of reference data held in the lookup cache. All the entries
+// of a kind are invalidated together when the provider changes an object of that type.
+type LookupKind string
+
+const (
+	// LookupRoles are the Clumio roles.
+	LookupRoles LookupKind = "roles"
+	// LookupOrganizationalUnits are the Clumio organizational units.
+	LookupOrganizationalUnits LookupKind = "organizational_units"
+	// LookupAwsEnvironments are the AWS environments of the Clumio AWS connections.
+	LookupAwsEnvironments LookupKind = "aws_environments"
+	// LookupPolicies are the Clumio policies.
+	LookupPolicies LookupKind = "policies"
+)
+
+// lookupKey identifies an entry of the lookup cache. The organizational unit context is part
+// of the key as it scopes what the API returns.
+type lookupKey struct {
+	kind      LookupKind
+	ouContext string
+	key       string
+}
+
+// lookupEntry is an entry of the lookup cache. ready is closed once the lookup completes,
+// so that concurrent lookups of the same key wait for the first one instead of calling the
+// API again.
+type lookupEntry struct {
+	ready   chan struct{}
+	value   any
+	apiErr  *apiutils.APIError
+	expires time.Time
+}
+
+// lookupCache caches the results of the lookups made through Lookup. Failed lookups are not
+// cached.
+type lookupCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[lookupKey]*lookupEntry
+	// generations counts the invalidations of each kind, so that a lookup that started
+	// before an invalidation does not cache its result.
+	generations map[LookupKind]uint64
+}
+
+// newLookupCache returns an empty lookup cache whose entries expire after ttl.
+func newLookupCache(ttl time.Duration) *lookupCache {
+	return &lookupCache{
+		ttl:         ttl,
+		entries:     map[lookupKey]*lookupEntry{},
+		generations: map[LookupKind]uint64{},
+	}
+}
+
+// Lookup returns the result of load for the given kind and key, from the lookup cache of the
+// client when available. The result is shared by all callers, which must not modify it. If
+// the cache is disabled, load is called every time. A caller waiting for the same lookup made
+// by another caller stops waiting once ctx is done.
+func Lookup[T any](ctx context.Context, c *ApiClient, kind LookupKind, key string,
+	load func() (T, *apiutils.APIError)) (T, *apiutils.APIError) {
+	cache := c.lookups
+	if cache == nil {
+		return load()
+	}
+	k := lookupKey{kind: kind, ouContext: c.config.OrganizationalUnitContext, key: key}
+
+	cache.mu.Lock()
+	entry, ok := cache.entries[k]
+	if ok && entry.expires.IsZero() {
+		// Another lookup of the key is in flight.
+		cache.mu.Unlock()
+		var zero T
+		select {
+		case <-entry.ready:
+		case <-ctx.Done():
+			return zero, contextAPIError(ctx)
+		}
+		if entry.apiErr != nil {
+			return zero, entry.apiErr
+		}
+		return entry.value.(T), nil
+	}
+	if ok && time.Now().Before(entry.expires) {
+		cache.mu.Unlock()
+		return entry.value.(T), nil
+	}
+	entry = &lookupEntry{ready: make(chan struct{})}
+	cache.entries[k] = entry
+	generation := cache.generations[kind]
+	cache.mu.Unlock()
+
+	value, apiErr := load()
+
+	cache.mu.Lock()
+	entry.value, entry.apiErr = value, apiErr
+	if apiErr != nil || cache.generations[kind] != generation {
+		if cache.entries[k] == entry {
+			delete(cache.entries, k)
+		}
+	} else {
+		entry.expires = time.Now().Add(cache.ttl)
+	}
+	cache.mu.Unlock()
+	close(entry.ready)
+	return value, apiErr
+}
+
+// contextAPIError returns the error of a lookup abandoned because ctx is done, in the form
+// the SDK returns the requests which fail before reaching the API.
+func contextAPIError(ctx context.Context) *apiutils.APIError {
+	return &apiutils.APIError{
+		ResponseCode: http.StatusInternalServerError,
+		Reason:       sdkCommon.InternalServerError,
+		Response:     []byte(ctx.Err().Error()),
+	}
+}
+
+// InvalidateLookups drops the cached lookups of the given kinds. Resources call it after
+// changing an object of one of those types.
+func (c *ApiClient) InvalidateLookups(kinds ...LookupKind) {
+	cache := c.lookups
+	if cache == nil {
+		return
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	for _, kind := range kinds {
+		cache.generations[kind]++
+		for k := range cache.entries {
+			if k.kind == kind {
+				delete(cache.entries, k)
+			}
+		}
+	}
+}
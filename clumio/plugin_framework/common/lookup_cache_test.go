@@ -0,0 +1,190 @@
+// Copyright 2024. Clumio, Inc.
+
+// Unit tests of the cache of the reference data looked up by the resources and data sources.
+package common
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	apiutils "github.com/clumio-code/clumio-go-sdk/api_utils"
+	clumioConfig "github.com/clumio-code/clumio-go-sdk/config"
+)
+
+// countingLoad returns a load function for Lookup returning value and counting its calls.
+func countingLoad(value string, calls *atomic.Int32) func() (string, *apiutils.APIError) {
+	return func() (string, *apiutils.APIError) {
+		calls.Add(1)
+		return value, nil
+	}
+}
+
+// newTestLookupClient returns a client whose lookups expire after ttl.
+func newTestLookupClient(ttl time.Duration) *ApiClient {
+	return &ApiClient{lookups: newLookupCache(ttl)}
+}
+
+func TestLookupCaches(t *testing.T) {
+	client := newTestLookupClient(time.Hour)
+	var calls atomic.Int32
+	for i := 0; i < 3; i++ {
+		value, apiErr := Lookup(context.Background(), client, LookupRoles, "", countingLoad("roles", &calls))
+		if apiErr != nil || value != "roles" {
+			t.Fatalf("got %q, %v, want the loaded value", value, apiErr)
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("got %d loads, want 1", got)
+	}
+
+	// The kind, the key and the organizational unit context are part of the cache key.
+	_, _ = Lookup(context.Background(), client, LookupPolicies, "", countingLoad("policies", &calls))
+	_, _ = Lookup(context.Background(), client, LookupRoles, "other", countingLoad("roles", &calls))
+	scoped := &ApiClient{
+		config:  clumioConfig.Config{OrganizationalUnitContext: "ou-1"},
+		lookups: client.lookups,
+	}
+	_, _ = Lookup(context.Background(), scoped, LookupRoles, "", countingLoad("roles", &calls))
+	if got := calls.Load(); got != 4 {
+		t.Errorf("got %d loads, want 4", got)
+	}
+}
+
+func TestLookupExpires(t *testing.T) {
+	client := newTestLookupClient(time.Millisecond)
+	var calls atomic.Int32
+	_, _ = Lookup(context.Background(), client, LookupRoles, "", countingLoad("roles", &calls))
+	time.Sleep(5 * time.Millisecond)
+	_, _ = Lookup(context.Background(), client, LookupRoles, "", countingLoad("roles", &calls))
+	if got := calls.Load(); got != 2 {
+		t.Errorf("got %d loads, want the expired lookup loaded again", got)
+	}
+}
+
+func TestLookupDisabled(t *testing.T) {
+	client := &ApiClient{}
+	var calls atomic.Int32
+	_, _ = Lookup(context.Background(), client, LookupRoles, "", countingLoad("roles", &calls))
+	_, _ = Lookup(context.Background(), client, LookupRoles, "", countingLoad("roles", &calls))
+	if got := calls.Load(); got != 2 {
+		t.Errorf("got %d loads, want every lookup loaded", got)
+	}
+	// Invalidating the lookups of a client without cache is a no-op.
+	client.InvalidateLookups(LookupRoles)
+}
+
+func TestLookupErrorsAreNotCached(t *testing.T) {
+	client := newTestLookupClient(time.Hour)
+	var calls atomic.Int32
+	failing := func() (string, *apiutils.APIError) {
+		calls.Add(1)
+		return "", &apiutils.APIError{ResponseCode: 500}
+	}
+	for i := 0; i < 2; i++ {
+		if _, apiErr := Lookup(context.Background(), client, LookupRoles, "", failing); apiErr == nil {
+			t.Fatal("got no error, want the error of the load")
+		}
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("got %d loads, want the failed lookup loaded again", got)
+	}
+}
+
+func TestLookupConcurrent(t *testing.T) {
+	client := newTestLookupClient(time.Hour)
+	var calls atomic.Int32
+	release := make(chan struct{})
+	load := func() (string, *apiutils.APIError) {
+		calls.Add(1)
+		<-release
+		return "roles", nil
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, _ := Lookup(context.Background(), client, LookupRoles, "", load)
+			if value != "roles" {
+				t.Errorf("got %q, want the value of the shared load", value)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if got := calls.Load(); got != 1 {
+		t.Errorf("got %d loads, want the concurrent lookups to share one", got)
+	}
+}
+
+func TestLookupWaitStopsWithContext(t *testing.T) {
+	client := newTestLookupClient(time.Hour)
+	release := make(chan struct{})
+	defer close(release)
+	go Lookup(context.Background(), client, LookupRoles, "", func() (string, *apiutils.APIError) {
+		<-release
+		return "roles", nil
+	})
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	var calls atomic.Int32
+	done := make(chan *apiutils.APIError)
+	go func() {
+		_, apiErr := Lookup(ctx, client, LookupRoles, "", countingLoad("roles", &calls))
+		done <- apiErr
+	}()
+	select {
+	case apiErr := <-done:
+		if apiErr == nil {
+			t.Error("got no error, want the lookup abandoned with its context")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("got the lookup still waiting for the other one once its context is done")
+	}
+	if got := calls.Load(); got != 0 {
+		t.Errorf("got %d loads, want the lookup to wait for the one in flight", got)
+	}
+}
+
+func TestInvalidateLookups(t *testing.T) {
+	client := newTestLookupClient(time.Hour)
+	var roleCalls, policyCalls atomic.Int32
+	_, _ = Lookup(context.Background(), client, LookupRoles, "", countingLoad("roles", &roleCalls))
+	_, _ = Lookup(context.Background(), client, LookupRoles, "role-1", countingLoad("role", &roleCalls))
+	_, _ = Lookup(context.Background(), client, LookupPolicies, "", countingLoad("policies", &policyCalls))
+
+	client.InvalidateLookups(LookupRoles)
+	_, _ = Lookup(context.Background(), client, LookupRoles, "", countingLoad("roles", &roleCalls))
+	_, _ = Lookup(context.Background(), client, LookupRoles, "role-1", countingLoad("role", &roleCalls))
+	_, _ = Lookup(context.Background(), client, LookupPolicies, "", countingLoad("policies", &policyCalls))
+	if got := roleCalls.Load(); got != 4 {
+		t.Errorf("got %d loads of the roles, want the invalidated lookups loaded again", got)
+	}
+	if got := policyCalls.Load(); got != 1 {
+		t.Errorf("got %d loads of the policies, want them still cached", got)
+	}
+}
+
+func TestInvalidateLookupsDuringLoad(t *testing.T) {
+	client := newTestLookupClient(time.Hour)
+	var calls atomic.Int32
+	// The lookup started before the invalidation returns its value without caching it.
+	value, _ := Lookup(context.Background(), client, LookupRoles, "", func() (string, *apiutils.APIError) {
+		calls.Add(1)
+		client.InvalidateLookups(LookupRoles)
+		return "stale", nil
+	})
+	if value != "stale" {
+		t.Errorf("got %q, want the value of the load", value)
+	}
+	value, _ = Lookup(context.Background(), client, LookupRoles, "", countingLoad("fresh", &calls))
+	if value != "fresh" || calls.Load() != 2 {
+		t.Errorf("got %q after %d loads, want the lookup loaded again", value, calls.Load())
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the client side rate limiting applied to every request sent to the Clumio API.
+
+package common
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/time/rate"
+)
+
+// RateLimitConfig controls how many requests the provider sends to the Clumio API. A zero
+// value disables the corresponding limit.
+type RateLimitConfig struct {
+	// RequestsPerSecond is the sustained rate of requests. Short bursts of up to one second
+	// worth of requests are allowed.
+	RequestsPerSecond float64
+	// MaxConcurrentRequests is the maximum number of requests in flight at the same time.
+	MaxConcurrentRequests int
+}
+
+// Validate returns an error if the rate limit configuration cannot be used.
+func (c RateLimitConfig) Validate() error {
+	if c.RequestsPerSecond < 0 || math.IsNaN(c.RequestsPerSecond) ||
+		math.IsInf(c.RequestsPerSecond, 0) {
+		return fmt.Errorf("requests_per_second must be a non-negative number, got %v",
+			c.RequestsPerSecond)
+	}
+	if c.MaxConcurrentRequests < 0 {
+		return fmt.Errorf("max_concurrent_requests must not be negative, got %d",
+			c.MaxConcurrentRequests)
+	}
+	return nil
+}
+
+// rateLimitTransport is an http.RoundTripper that delays requests so that they stay within
+// a token bucket rate and a cap on the number of requests in flight. It is shared by all
+// the resources, data sources and task polls of the provider.
+type rateLimitTransport struct {
+	next http.RoundTripper
+	// limiter is nil when the rate is not limited.
+	limiter *rate.Limiter
+	// slots is nil when the concurrency is not limited. A request holds a slot until its
+	// response body is closed.
+	slots chan struct{}
+}
+
+// newRateLimitTransport returns a transport that applies the given limits before passing
+// the requests to next.
+func newRateLimitTransport(next http.RoundTripper, config RateLimitConfig) *rateLimitTransport {
+	t := &rateLimitTransport{next: next}
+	if config.RequestsPerSecond > 0 {
+		burst := int(math.Ceil(config.RequestsPerSecond))
+		t.limiter = rate.NewLimiter(rate.Limit(config.RequestsPerSecond), burst)
+	}
+	if config.MaxConcurrentRequests > 0 {
+		t.slots = make(chan struct{}, config.MaxConcurrentRequests)
+	}
+	return t
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	start := time.Now()
+	if t.slots != nil {
+		select {
+		case t.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	release := t.releaseFunc()
+	if t.limiter != nil {
+		if err := t.limiter.Wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+	tflog.Debug(ctx, "Sending Clumio API request", map[string]any{
+		"method":     req.Method,
+		"path":       req.URL.Path,
+		"queue_time": time.Since(start).String(),
+	})
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releaseFunc returns a function that frees the slot taken by a request. It is safe to
+// call the returned function more than once.
+func (t *rateLimitTransport) releaseFunc() func() {
+	if t.slots == nil {
+		return func() {}
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-t.slots })
+	}
+}
+
+// releasingBody frees the slot of its request once the response body is closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+// Close implements io.Closer.
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
@@ -0,0 +1,227 @@
+// Copyright 2024. Clumio, Inc.
+
+// Unit tests of the client side rate limiting of the requests sent to the Clumio API.
+package common
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingTransport holds every request until release is closed, and records the highest
+// number of requests in flight. A request stays in flight until its response body is
+// closed.
+type blockingTransport struct {
+	release     chan struct{}
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+	err         error
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *blockingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	inFlight := t.inFlight.Add(1)
+	for {
+		highest := t.maxInFlight.Load()
+		if inFlight <= highest || t.maxInFlight.CompareAndSwap(highest, inFlight) {
+			break
+		}
+	}
+	<-t.release
+	if t.err != nil {
+		t.inFlight.Add(-1)
+		return nil, t.err
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       &closeFuncBody{Reader: strings.NewReader("{}"), close: t.done},
+		Request:    req,
+	}, nil
+}
+
+// done records the end of a request.
+func (t *blockingTransport) done() {
+	t.inFlight.Add(-1)
+}
+
+// closeFuncBody is a response body that calls close when it is closed.
+type closeFuncBody struct {
+	io.Reader
+	close func()
+}
+
+// Close implements io.Closer.
+func (b *closeFuncBody) Close() error {
+	b.close()
+	return nil
+}
+
+// sendTestRequest sends a request through the transport and closes its response body.
+func sendTestRequest(ctx context.Context, transport http.RoundTripper) error {
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.example.com/tasks",
+		nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func TestRateLimitConfigValidate(t *testing.T) {
+	for name, test := range map[string]struct {
+		config  RateLimitConfig
+		wantErr bool
+	}{
+		"no limits": {},
+		"limits": {
+			config: RateLimitConfig{RequestsPerSecond: 2.5, MaxConcurrentRequests: 4},
+		},
+		"negative rate": {
+			config:  RateLimitConfig{RequestsPerSecond: -1},
+			wantErr: true,
+		},
+		"infinite rate": {
+			config:  RateLimitConfig{RequestsPerSecond: math.Inf(1)},
+			wantErr: true,
+		},
+		"NaN rate": {
+			config:  RateLimitConfig{RequestsPerSecond: math.NaN()},
+			wantErr: true,
+		},
+		"negative concurrency": {
+			config:  RateLimitConfig{MaxConcurrentRequests: -1},
+			wantErr: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := test.config.Validate(); (err != nil) != test.wantErr {
+				t.Errorf("got the error %v, want an error %t", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestRateLimitTransportConcurrency(t *testing.T) {
+	next := &blockingTransport{release: make(chan struct{})}
+	transport := newRateLimitTransport(next, RateLimitConfig{MaxConcurrentRequests: 2})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- sendTestRequest(context.Background(), transport)
+		}()
+	}
+	// Let the requests queue up before releasing them.
+	time.Sleep(50 * time.Millisecond)
+	if got := next.inFlight.Load(); got != 2 {
+		t.Errorf("got %d requests in flight, want 2", got)
+	}
+	close(next.release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if got := next.maxInFlight.Load(); got != 2 {
+		t.Errorf("got at most %d requests in flight, want 2", got)
+	}
+	if got := len(transport.slots); got != 0 {
+		t.Errorf("got %d slots still taken, want none", got)
+	}
+}
+
+func TestRateLimitTransportCancelWaiting(t *testing.T) {
+	next := &blockingTransport{release: make(chan struct{})}
+	close(next.release)
+	transport := newRateLimitTransport(next, RateLimitConfig{MaxConcurrentRequests: 1})
+
+	// The first request holds the only slot until its body is closed.
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/tasks", nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	waiting := make(chan error, 1)
+	go func() {
+		waiting <- sendTestRequest(ctx, transport)
+	}()
+	select {
+	case err := <-waiting:
+		t.Fatalf("got the error %v before the slot was freed, want the request to wait",
+			err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	cancel()
+	select {
+	case err := <-waiting:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got the error %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("got the waiting request still blocked after its context was canceled")
+	}
+
+	// The canceled request took no slot, so the next one is sent once the first is done.
+	resp.Body.Close()
+	if err := sendTestRequest(context.Background(), transport); err != nil {
+		t.Error(err)
+	}
+	if got := next.maxInFlight.Load(); got != 1 {
+		t.Errorf("got at most %d requests in flight, want 1", got)
+	}
+}
+
+func TestRateLimitTransportCancelRate(t *testing.T) {
+	next := &blockingTransport{release: make(chan struct{})}
+	close(next.release)
+	// The burst of one request is spent by the first request, and the next token is only
+	// available after 100 seconds.
+	transport := newRateLimitTransport(next, RateLimitConfig{
+		RequestsPerSecond:     0.01,
+		MaxConcurrentRequests: 1,
+	})
+	if err := sendTestRequest(context.Background(), transport); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := sendTestRequest(ctx, transport); err == nil {
+		t.Error("got no error for a request over the rate, want the wait to be canceled")
+	}
+	if got := len(transport.slots); got != 0 {
+		t.Errorf("got %d slots still taken after the canceled wait, want none", got)
+	}
+}
+
+func TestRateLimitTransportReleasesOnError(t *testing.T) {
+	next := &blockingTransport{
+		release: make(chan struct{}),
+		err:     errors.New("connection reset"),
+	}
+	close(next.release)
+	transport := newRateLimitTransport(next, RateLimitConfig{MaxConcurrentRequests: 1})
+	for i := 0; i < 2; i++ {
+		if err := sendTestRequest(context.Background(), transport); err != next.err {
+			t.Errorf("got the error %v, want %v", err, next.err)
+		}
+	}
+	if got := len(transport.slots); got != 0 {
+		t.Errorf("got %d slots still taken after the failures, want none", got)
+	}
+}
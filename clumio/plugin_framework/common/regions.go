@@ -0,0 +1,73 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the known Clumio regions and the helpers used to validate API base URLs.
+
+package common
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// regionBaseUrls maps the Clumio regions to the base URL of their API endpoint.
+var regionBaseUrls = map[string]string{
+	"us-west-2":    "https://us-west-2.api.clumio.com",
+	"us-east-1":    "https://us-east-1.api.clumio.com",
+	"ca-central-1": "https://ca-central-1.ca.api.clumio.com",
+	"eu-central-1": "https://eu-central-1.de.api.clumio.com",
+}
+
+// Regions returns the sorted names of the known Clumio regions.
+func Regions() []string {
+	regions := make([]string, 0, len(regionBaseUrls))
+	for region := range regionBaseUrls {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// RegionBaseUrl returns the API base URL of the given Clumio region.
+func RegionBaseUrl(region string) (string, bool) {
+	baseUrl, ok := regionBaseUrls[region]
+	return baseUrl, ok
+}
+
+// NormalizeBaseUrl returns the given API base URL with a scheme, a lower case host and no
+// trailing slash. A URL without a scheme is assumed to use https.
+func NormalizeBaseUrl(rawUrl string) (string, error) {
+	rawUrl = strings.TrimSpace(rawUrl)
+	if !strings.Contains(rawUrl, "://") {
+		rawUrl = "https://" + rawUrl
+	}
+	parsed, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", fmt.Errorf("invalid Clumio API base URL %q: %w", rawUrl, err)
+	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("invalid Clumio API base URL %q: missing host", rawUrl)
+	}
+	if parsed.RawQuery != "" || parsed.Fragment != "" {
+		return "", fmt.Errorf(
+			"invalid Clumio API base URL %q: query strings and fragments are not allowed",
+			rawUrl)
+	}
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = strings.ToLower(parsed.Host)
+	parsed.Path = strings.TrimRight(parsed.Path, "/")
+	parsed.RawPath = ""
+	return parsed.String(), nil
+}
+
+// IsKnownBaseUrl reports whether the normalized API base URL is the endpoint of one of the
+// known Clumio regions.
+func IsKnownBaseUrl(baseUrl string) bool {
+	for _, known := range regionBaseUrls {
+		if baseUrl == known {
+			return true
+		}
+	}
+	return false
+}
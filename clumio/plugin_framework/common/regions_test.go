@@ -0,0 +1,105 @@
+// Copyright 2024. Clumio, Inc.
+
+// Unit tests of the Clumio regions and the validation of the API base URLs.
+package common
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRegionBaseUrl(t *testing.T) {
+	for region, want := range map[string]string{
+		"us-west-2":    "https://us-west-2.api.clumio.com",
+		"us-east-1":    "https://us-east-1.api.clumio.com",
+		"ca-central-1": "https://ca-central-1.ca.api.clumio.com",
+		"eu-central-1": "https://eu-central-1.de.api.clumio.com",
+	} {
+		got, ok := RegionBaseUrl(region)
+		if !ok || got != want {
+			t.Errorf("got %q for the region %s, want %q", got, region, want)
+		}
+		if !IsKnownBaseUrl(got) {
+			t.Errorf("got the base URL %s of the region %s unknown", got, region)
+		}
+	}
+	if got, ok := RegionBaseUrl("eu-west-1"); ok {
+		t.Errorf("got %q for an unknown region", got)
+	}
+	want := []string{"ca-central-1", "eu-central-1", "us-east-1", "us-west-2"}
+	if got := Regions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got the regions %v, want %v", got, want)
+	}
+}
+
+func TestNormalizeBaseUrl(t *testing.T) {
+	for name, test := range map[string]struct {
+		baseUrl string
+		want    string
+		// wantErr is a part of the expected error, if any.
+		wantErr   string
+		wantKnown bool
+	}{
+		"known": {
+			baseUrl:   "https://us-west-2.api.clumio.com",
+			want:      "https://us-west-2.api.clumio.com",
+			wantKnown: true,
+		},
+		"no scheme": {
+			baseUrl:   "us-east-1.api.clumio.com",
+			want:      "https://us-east-1.api.clumio.com",
+			wantKnown: true,
+		},
+		"upper case and trailing slashes": {
+			baseUrl:   " HTTPS://EU-Central-1.DE.api.clumio.com// ",
+			want:      "https://eu-central-1.de.api.clumio.com",
+			wantKnown: true,
+		},
+		"custom endpoint": {
+			baseUrl: "http://localhost:8080/api/",
+			want:    "http://localhost:8080/api",
+		},
+		"other region of the same host": {
+			baseUrl: "https://ca-central-1.api.clumio.com",
+			want:    "https://ca-central-1.api.clumio.com",
+		},
+		"missing host": {
+			baseUrl: "https:///v1",
+			wantErr: "missing host",
+		},
+		"query string": {
+			baseUrl: "https://us-west-2.api.clumio.com?region=us-west-2",
+			wantErr: "query strings and fragments are not allowed",
+		},
+		"fragment": {
+			baseUrl: "https://us-west-2.api.clumio.com#api",
+			wantErr: "query strings and fragments are not allowed",
+		},
+		"invalid": {
+			baseUrl: "https://us-west-2.api.clumio.com:port",
+			wantErr: "invalid Clumio API base URL",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := NormalizeBaseUrl(test.baseUrl)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("got %q and the error %v, want the error %q", got, err,
+						test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+			if known := IsKnownBaseUrl(got); known != test.wantKnown {
+				t.Errorf("got %t for the known base URL %s, want %t", known, got,
+					test.wantKnown)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the loopback relay through which the Clumio SDK reaches the Clumio API.
+
+package common
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// relayContextHeader carries the ID of the operation context registered with the relay
+// through WithContext. The relay removes it before forwarding the request.
+const relayContextHeader = "X-Clumio-Provider-Context"
+
+// relaySecretHeader carries the secret of the relay, without which the relay rejects the
+// request. Other local processes can reach the loopback listener, so the secret keeps them
+// from sending requests that the relay would authenticate with the token of the token
+// command. The relay removes it before forwarding the request.
+const relaySecretHeader = "X-Clumio-Provider-Relay-Secret"
+
+// relay is a loopback HTTP server that stands in for the Clumio API base URL. The
+// clumio-go-sdk creates a new HTTP client for every call and offers no way to supply a
+// transport, so the SDK is pointed at the relay and the relay forwards every request to
+// the Clumio API through the transport built by the provider. The relay of the provider
+// lives for the lifetime of the provider process, other relays are stopped with close.
+type relay struct {
+	// url is the base URL handed to the SDK in place of the Clumio API base URL.
+	url string
+	// secret is the random value of the relaySecretHeader expected on every request.
+	secret string
+	// server serves the relay on its loopback listener.
+	server *http.Server
+	// target is the Clumio API base URL the requests are forwarded to.
+	target *url.URL
+	// setupContext sets up the context of the requests, such as the wire log subsystem of
+	// their logger.
+	setupContext func(context.Context) context.Context
+
+	// contexts holds the operation contexts registered through WithContext, keyed by the
+	// value of their relayContextHeader, until they are done.
+	contexts sync.Map
+	// lastContextId is the ID of the last registered operation context.
+	lastContextId atomic.Uint64
+}
+
+// newRelay starts a relay that forwards requests to target using the given transport.
+// The requests which are not linked to an operation context run with the values of ctx,
+// such as its tflog logger, and its cancellation does not stop the relay. setupContext is
+// applied to ctx and to the operation contexts before they reach the transport.
+func newRelay(ctx context.Context, target *url.URL, transport http.RoundTripper,
+	setupContext func(context.Context) context.Context) (*relay, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("unable to generate the secret of the Clumio API relay: %w", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("unable to start the Clumio API relay: %w", err)
+	}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	director := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		director(req)
+		// The Clumio API routes on the Host header, so it must match the target.
+		req.Host = target.Host
+		// A nil value keeps the reverse proxy from adding the loopback address.
+		req.Header["X-Forwarded-For"] = nil
+	}
+	proxy.Transport = transport
+	r := &relay{target: target, secret: hex.EncodeToString(secret), setupContext: setupContext}
+	proxy.ErrorHandler = func(w http.ResponseWriter, req *http.Request, err error) {
+		tflog.Error(req.Context(), "Error forwarding request to the Clumio API",
+			map[string]any{"method": req.Method, "path": req.URL.Path, "error": err.Error()})
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = fmt.Fprintf(w, "Error reaching the Clumio API at %s: %v", target.Host, err)
+	}
+	server := &http.Server{
+		Handler:           r.authenticate(r.withOperationContext(proxy)),
+		ReadHeaderTimeout: 30 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return detachedContext{setupContext(ctx)}
+		},
+	}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	r.url = "http://" + listener.Addr().String()
+	r.server = server
+	return r, nil
+}
+
+// close stops the relay, closing its listener and its connections.
+func (r *relay) close() error {
+	return r.server.Close()
+}
+
+// registerContext registers the operation context and returns the value of the
+// relayContextHeader which links the requests to it. The context is forgotten once done.
+func (r *relay) registerContext(ctx context.Context) string {
+	id := strconv.FormatUint(r.lastContextId.Add(1), 10)
+	r.contexts.Store(id, ctx)
+	context.AfterFunc(ctx, func() {
+		r.contexts.Delete(id)
+	})
+	return id
+}
+
+// authenticate rejects the requests which do not carry the secret of the relay.
+func (r *relay) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		secret := req.Header.Get(relaySecretHeader)
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(r.secret)) != 1 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		req.Header.Del(relaySecretHeader)
+		next.ServeHTTP(w, req)
+	})
+}
+
+// withOperationContext runs the requests linked to an operation context with the values of
+// that context, so that the logs of their retries, rate limits and wire traffic carry the
+// logger and fields of the operation, and cancels them once that context is done, so that
+// an interrupted or timed out operation stops waiting on their retries and rate limits.
+func (r *relay) withOperationContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get(relayContextHeader)
+		if id == "" {
+			next.ServeHTTP(w, req)
+			return
+		}
+		req.Header.Del(relayContextHeader)
+		value, ok := r.contexts.Load(id)
+		if !ok {
+			// The operation is already over.
+			ctx, cancel := context.WithCancel(req.Context())
+			cancel()
+			next.ServeHTTP(w, req.WithContext(ctx))
+			return
+		}
+		opCtx := value.(context.Context)
+		ctx, cancel := context.WithCancel(detachedContext{r.setupContext(opCtx)})
+		defer cancel()
+		// The request also ends if the SDK goes away.
+		stopClient := context.AfterFunc(req.Context(), cancel)
+		defer stopClient()
+		stopOperation := context.AfterFunc(opCtx, cancel)
+		defer stopOperation()
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// detachedContext keeps the values of the wrapped context without inheriting its deadline
+// or cancellation.
+type detachedContext struct {
+	context.Context
+}
+
+// Deadline implements context.Context.
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// Done implements context.Context.
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+// Err implements context.Context.
+func (detachedContext) Err() error {
+	return nil
+}
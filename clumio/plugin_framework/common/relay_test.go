@@ -0,0 +1,183 @@
+// Copyright 2024. Clumio, Inc.
+
+// Unit tests of the relay through which the Clumio SDK reaches the Clumio API.
+package common
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	clumioConfig "github.com/clumio-code/clumio-go-sdk/config"
+)
+
+func TestRelayCancelsRequestsWithTheirOperation(t *testing.T) {
+	var sawContextHeader atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(relayContextHeader) != "" {
+			sawContextHeader.Store(true)
+		}
+		w.Header().Set(retryAfterHeader, "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+	transport, _ := newTestRetryTransport()
+	r, err := newRelay(context.Background(), target, transport, identityContext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	req, _ := http.NewRequest(http.MethodGet, r.url, nil)
+	req.Header.Set(relaySecretHeader, r.secret)
+	req.Header.Set(relayContextHeader, r.registerContext(ctx))
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusBadGateway)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the request ended after %s, want it to end with its operation", elapsed)
+	}
+	if sawContextHeader.Load() {
+		t.Errorf("got the %s header forwarded to the API", relayContextHeader)
+	}
+	// The context is forgotten asynchronously once done.
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if _, ok := r.contexts.Load("1"); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("got the operation context still registered once done")
+		}
+	}
+}
+
+func TestRelayCarriesTheValuesOfTheOperationContext(t *testing.T) {
+	type contextKey struct{}
+	target, _ := url.Parse("https://api.clumio.test")
+	transport := &stubTransport{}
+	providerCtx := context.WithValue(context.Background(), contextKey{}, "provider")
+	r, err := newRelay(providerCtx, target, transport, identityContext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.close()
+
+	opCtx, cancel := context.WithCancel(
+		context.WithValue(context.Background(), contextKey{}, "operation"))
+	defer cancel()
+	for _, want := range []string{"operation", "provider"} {
+		req, _ := http.NewRequest(http.MethodGet, r.url, nil)
+		req.Header.Set(relaySecretHeader, r.secret)
+		if want == "operation" {
+			req.Header.Set(relayContextHeader, r.registerContext(opCtx))
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if got := transport.last.Context().Value(contextKey{}); got != want {
+			t.Errorf("got the request forwarded with the %v context value, want %s", got, want)
+		}
+	}
+}
+
+func TestRelayRejectsRequestsWithoutItsSecret(t *testing.T) {
+	var forwarded atomic.Int32
+	var sawSecretHeader atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded.Add(1)
+		if r.Header.Get(relaySecretHeader) != "" {
+			sawSecretHeader.Store(true)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	client, err := NewApiClient(context.Background(), clumioConfig.Config{BaseUrl: server.URL},
+		ApiClientOptions{
+			Retry: RetryConfig{MaxRetries: DefaultMaxRetries, MaxBackoff: DefaultRetryMaxBackoff},
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	config := client.ClumioConfig()
+
+	tests := []struct {
+		name   string
+		secret string
+		want   int
+	}{
+		{name: "missing", want: http.StatusForbidden},
+		{name: "wrong", secret: "wrong", want: http.StatusForbidden},
+		{name: "valid", secret: config.CustomHeaders[relaySecretHeader], want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, config.BaseUrl, nil)
+			if tt.secret != "" {
+				req.Header.Set(relaySecretHeader, tt.secret)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+	if got := forwarded.Load(); got != 1 {
+		t.Errorf("got %d requests forwarded to the API, want 1", got)
+	}
+	if sawSecretHeader.Load() {
+		t.Errorf("got the %s header forwarded to the API", relaySecretHeader)
+	}
+}
+
+func TestApiClientClose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	client, err := NewApiClient(context.Background(), clumioConfig.Config{BaseUrl: server.URL},
+		ApiClientOptions{
+			Retry: RetryConfig{MaxRetries: DefaultMaxRetries, MaxBackoff: DefaultRetryMaxBackoff},
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	relayUrl, _ := url.Parse(client.ClumioConfig().BaseUrl)
+	resp, err := http.Get(relayUrl.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if conn, err := net.Dial("tcp", relayUrl.Host); err == nil {
+		conn.Close()
+		t.Errorf("got the relay still listening on %s once the client is closed", relayUrl.Host)
+	}
+}
+
+// identityContext returns the given context unchanged.
+func identityContext(ctx context.Context) context.Context {
+	return ctx
+}
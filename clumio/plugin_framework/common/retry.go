@@ -0,0 +1,206 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the retry logic applied to every request sent to the Clumio API.
+
+package common
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// DefaultMaxRetries is the number of times a failed request is retried when the
+	// provider configuration does not set max_retries.
+	DefaultMaxRetries = 5
+	// DefaultRetryMaxBackoff is the upper bound of the wait between two attempts when
+	// the provider configuration does not set retry_max_backoff.
+	DefaultRetryMaxBackoff = 30 * time.Second
+
+	// retryBaseBackoff is the wait before the first retry. It doubles on every attempt.
+	retryBaseBackoff = time.Second
+	// retryAfterHeader is the header used by the Clumio API to ask clients to slow down.
+	retryAfterHeader = "Retry-After"
+)
+
+// RetryConfig controls how transient Clumio API failures are retried.
+type RetryConfig struct {
+	// MaxRetries is the number of retries after the initial attempt.
+	MaxRetries int
+	// MaxBackoff is the upper bound of the computed wait between two attempts.
+	MaxBackoff time.Duration
+}
+
+// Validate returns an error if the retry configuration cannot be used.
+func (c RetryConfig) Validate() error {
+	if c.MaxRetries < 0 {
+		return fmt.Errorf("max_retries must not be negative, got %d", c.MaxRetries)
+	}
+	if c.MaxBackoff <= 0 {
+		return fmt.Errorf("retry_max_backoff must be positive, got %s", c.MaxBackoff)
+	}
+	return nil
+}
+
+// retryTransport is an http.RoundTripper that retries throttled requests, 5xx responses
+// and broken connections with jittered exponential backoff. Requests that are not
+// idempotent are only retried when the failure proves that the API did not accept them.
+type retryTransport struct {
+	next   http.RoundTripper
+	config RetryConfig
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	idempotent := isIdempotentMethod(req.Method)
+	for attempt := 0; ; attempt++ {
+		attemptReq := req.Clone(ctx)
+		if body != nil {
+			attemptReq.Body = io.NopCloser(bytes.NewReader(body))
+			attemptReq.ContentLength = int64(len(body))
+		}
+		resp, err := t.next.RoundTrip(attemptReq)
+		if attempt >= t.config.MaxRetries || !isRetryable(resp, err, idempotent) {
+			return resp, err
+		}
+		wait := t.backoff(attempt, resp)
+		fields := map[string]any{
+			"method":  req.Method,
+			"path":    req.URL.Path,
+			"attempt": attempt + 1,
+			"wait":    wait.String(),
+		}
+		if err != nil {
+			fields["error"] = err.Error()
+		} else {
+			fields["status"] = resp.StatusCode
+			// Drain the body so that the connection can be reused for the next attempt.
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		tflog.Debug(ctx, "Retrying Clumio API request", fields)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the wait before the next attempt. A Retry-After header sent by the API
+// always takes precedence over the computed backoff.
+func (t *retryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if wait, ok := parseRetryAfter(resp.Header.Get(retryAfterHeader)); ok {
+			return wait
+		}
+	}
+	backoff := t.config.MaxBackoff
+	// Guard the shift against overflowing for large attempt counts.
+	if attempt < 32 {
+		if exp := retryBaseBackoff << uint(attempt); exp > 0 && exp < backoff {
+			backoff = exp
+		}
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	// Full jitter spreads the retries of parallel resources over the whole window.
+	return time.Duration(rand.Int63n(int64(backoff))) + 1
+}
+
+// isRetryable reports whether a request that ended with the given response or error
+// should be sent again.
+func isRetryable(resp *http.Response, err error, idempotent bool) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		// A request that could not be dialed never reached the API, so it is always
+		// safe to send it again.
+		if isDialError(err) {
+			return true
+		}
+		return idempotent && isConnectionError(err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		// Throttled requests are rejected before they are processed.
+		return true
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return idempotent
+	}
+	return false
+}
+
+// isIdempotentMethod reports whether sending a request with the given method twice has
+// the same effect as sending it once.
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut,
+		http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isDialError reports whether the error happened while establishing the connection.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// isConnectionError reports whether the error is a broken or timed out connection.
+func isConnectionError(err error) bool {
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either a number of
+// seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
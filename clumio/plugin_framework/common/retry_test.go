@@ -0,0 +1,207 @@
+// Copyright 2024. Clumio, Inc.
+
+// Unit tests of the retries of the requests sent to the Clumio API.
+package common
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingTransport counts the attempts sent through it.
+type countingTransport struct {
+	next     http.RoundTripper
+	attempts atomic.Int32
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.attempts.Add(1)
+	return t.next.RoundTrip(req)
+}
+
+// newTestRetryTransport returns a retry transport which retries twice without waiting
+// noticeably, and the transport counting its attempts.
+func newTestRetryTransport() (*retryTransport, *countingTransport) {
+	counter := &countingTransport{next: http.DefaultTransport}
+	return &retryTransport{
+		next:   counter,
+		config: RetryConfig{MaxRetries: 2, MaxBackoff: time.Millisecond},
+	}, counter
+}
+
+func TestRetryTransportStatuses(t *testing.T) {
+	for name, test := range map[string]struct {
+		method       string
+		status       int
+		wantAttempts int32
+	}{
+		"GET throttled":      {http.MethodGet, http.StatusTooManyRequests, 3},
+		"POST throttled":     {http.MethodPost, http.StatusTooManyRequests, 3},
+		"GET server error":   {http.MethodGet, http.StatusInternalServerError, 3},
+		"PUT unavailable":    {http.MethodPut, http.StatusServiceUnavailable, 3},
+		"DELETE bad gateway": {http.MethodDelete, http.StatusBadGateway, 3},
+		"POST server error":  {http.MethodPost, http.StatusInternalServerError, 1},
+		"PATCH unavailable":  {http.MethodPatch, http.StatusServiceUnavailable, 1},
+		"GET bad request":    {http.MethodGet, http.StatusBadRequest, 1},
+		"GET not found":      {http.MethodGet, http.StatusNotFound, 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var bodies []string
+			server := httptest.NewServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					body, _ := io.ReadAll(r.Body)
+					bodies = append(bodies, string(body))
+					w.WriteHeader(test.status)
+				}))
+			defer server.Close()
+
+			transport, counter := newTestRetryTransport()
+			req, _ := http.NewRequest(test.method, server.URL, strings.NewReader("body"))
+			resp, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != test.status {
+				t.Errorf("got status %d, want %d", resp.StatusCode, test.status)
+			}
+			if got := counter.attempts.Load(); got != test.wantAttempts {
+				t.Errorf("got %d attempts, want %d", got, test.wantAttempts)
+			}
+			for _, body := range bodies {
+				if body != "body" {
+					t.Errorf("got body %q, want the request body on every attempt", body)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryTransportRecovers(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	transport, _ := newTestRetryTransport()
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || attempts.Load() != 2 {
+		t.Errorf("got status %d after %d attempts, want 200 after 2", resp.StatusCode,
+			attempts.Load())
+	}
+}
+
+func TestRetryTransportDialError(t *testing.T) {
+	// The address of a closed listener refuses connections.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	// A request that could not be dialed is retried even if it is not idempotent.
+	transport, counter := newTestRetryTransport()
+	req, _ := http.NewRequest(http.MethodPost, "http://"+address, strings.NewReader("body"))
+	if _, err := transport.RoundTrip(req); !isDialError(err) {
+		t.Errorf("got error %v, want a dial error", err)
+	}
+	if got := counter.attempts.Load(); got != 3 {
+		t.Errorf("got %d attempts, want 3", got)
+	}
+}
+
+func TestRetryTransportHonorsContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set(retryAfterHeader, "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	transport, counter := newTestRetryTransport()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	start := time.Now()
+	if _, err := transport.RoundTrip(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the retries stopped after %s, want them to stop with the context", elapsed)
+	}
+	if got := counter.attempts.Load(); got != 1 {
+		t.Errorf("got %d attempts, want 1", got)
+	}
+}
+
+func TestRetryTransportBackoff(t *testing.T) {
+	transport := &retryTransport{config: RetryConfig{MaxRetries: 5, MaxBackoff: 4 * time.Second}}
+	for attempt := 0; attempt < 70; attempt++ {
+		limit := transport.config.MaxBackoff
+		if attempt < 2 {
+			limit = retryBaseBackoff << uint(attempt)
+		}
+		for i := 0; i < 20; i++ {
+			if wait := transport.backoff(attempt, nil); wait <= 0 || wait > limit {
+				t.Fatalf("got a backoff of %s for attempt %d, want it within (0, %s]",
+					wait, attempt, limit)
+			}
+		}
+	}
+
+	// Retry-After takes precedence over the computed backoff, even above its cap.
+	resp := &http.Response{Header: http.Header{retryAfterHeader: []string{"10"}}}
+	if wait := transport.backoff(0, resp); wait != 10*time.Second {
+		t.Errorf("got a backoff of %s, want the 10s of Retry-After", wait)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	for name, test := range map[string]struct {
+		value    string
+		wantOk   bool
+		wantMin  time.Duration
+		wantWait time.Duration
+	}{
+		"empty":          {value: ""},
+		"seconds":        {value: "3", wantOk: true, wantMin: 3 * time.Second, wantWait: 3 * time.Second},
+		"zero":           {value: "0", wantOk: true},
+		"negative":       {value: "-1"},
+		"not a number":   {value: "soon"},
+		"fractional":     {value: "1.5"},
+		"past HTTP date": {value: "Wed, 21 Oct 2015 07:28:00 GMT", wantOk: true},
+		"future HTTP date": {
+			value:    time.Now().Add(time.Minute).UTC().Format(http.TimeFormat),
+			wantOk:   true,
+			wantMin:  58 * time.Second,
+			wantWait: time.Minute,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			wait, ok := parseRetryAfter(test.value)
+			if ok != test.wantOk || wait < test.wantMin || wait > test.wantWait {
+				t.Errorf("got %s, %t, want [%s, %s], %t", wait, ok, test.wantMin,
+					test.wantWait, test.wantOk)
+			}
+		})
+	}
+}
@@ -1,8 +1,188 @@
 package common
 
-import clumioConfig "github.com/clumio-code/clumio-go-sdk/config"
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
 
-// ApiClient defines the APIs/connections required by the resources.
+	clumioConfig "github.com/clumio-code/clumio-go-sdk/config"
+)
+
+// ApiClient defines the APIs/connections required by the resources. An ApiClient is shared
+// by all the resources and data sources and must not be modified after the provider is
+// configured. Use WithOrganizationalUnit to obtain a client scoped to a different
+// Organizational Unit.
 type ApiClient struct {
-	ClumioConfig clumioConfig.Config
+	// config is the SDK configuration. It is only handed out by value.
+	config clumioConfig.Config
+
+	// relay forwards the SDK traffic to the Clumio API.
+	relay *relay
+
+	// timeouts are the default timeouts of the resource operations.
+	timeouts OperationTimeouts
+
+	// lookups caches the reference data looked up by the resources and data sources. It is
+	// shared with the clients returned by WithOrganizationalUnit and nil if disabled.
+	lookups *lookupCache
+
+	// pollInterval, when positive, replaces the waits between two polls of the asynchronous
+	// operations of the API.
+	pollInterval time.Duration
+}
+
+// ApiClientOptions holds the provider level settings used to build an ApiClient.
+type ApiClientOptions struct {
+	Retry     RetryConfig
+	RateLimit RateLimitConfig
+	Transport TransportConfig
+	// TokenCommand is the command run to obtain the API token, and run again whenever the
+	// token is about to expire. It replaces the token of the SDK configuration if set.
+	TokenCommand string
+	// Timeouts are the default timeouts of the resource operations. The zero value stands
+	// for DefaultOperationTimeouts.
+	Timeouts OperationTimeouts
+	// DisableLookupCache disables the cache of the reference data looked up by the
+	// resources and data sources, so that every lookup calls the API.
+	DisableLookupCache bool
+	// PollInterval, when positive, replaces the waits between two polls of the asynchronous
+	// operations of the API. It is set by the acceptance tests replaying recorded API
+	// interactions, where there is nothing to wait for.
+	PollInterval time.Duration
+}
+
+// NewApiClient returns an ApiClient whose SDK configuration sends every request through
+// a relay that applies the given options before forwarding it to config.BaseUrl.
+func NewApiClient(ctx context.Context, config clumioConfig.Config, opts ApiClientOptions) (
+	*ApiClient, error) {
+	if err := opts.Retry.Validate(); err != nil {
+		return nil, err
+	}
+	if err := opts.RateLimit.Validate(); err != nil {
+		return nil, err
+	}
+	if err := opts.Transport.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Timeouts == (OperationTimeouts{}) {
+		opts.Timeouts = DefaultOperationTimeouts()
+	}
+	if err := opts.Timeouts.Validate(); err != nil {
+		return nil, err
+	}
+	target, err := url.Parse(config.BaseUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Clumio API base URL %q: %w", config.BaseUrl, err)
+	}
+	if target.Scheme == "" || target.Host == "" {
+		return nil, fmt.Errorf(
+			"invalid Clumio API base URL %q: expected a URL such as https://us-west-2.api.clumio.com",
+			config.BaseUrl)
+	}
+	baseTransport, err := newBaseTransport(opts.Transport)
+	if err != nil {
+		return nil, err
+	}
+	var transport http.RoundTripper = baseTransport
+	if opts.Transport.RequestTimeout > 0 {
+		transport = &timeoutTransport{next: transport, timeout: opts.Transport.RequestTimeout}
+	}
+	transport = &wireLogTransport{next: transport, trace: wireLogTraceEnabled()}
+	if opts.TokenCommand != "" {
+		source, err := newCommandTokenSource(ctx, opts.TokenCommand)
+		if err != nil {
+			return nil, err
+		}
+		config.Token = source.token
+		transport = &tokenTransport{next: transport, source: source}
+	}
+	// Every attempt of a retried request goes through the rate limits again.
+	transport = newRateLimitTransport(transport, opts.RateLimit)
+	transport = &retryTransport{next: transport, config: opts.Retry}
+	relay, err := newRelay(ctx, target, transport, func(ctx context.Context) context.Context {
+		return newWireLogContext(ctx, config.Token)
+	})
+	if err != nil {
+		return nil, err
+	}
+	config.BaseUrl = relay.url
+	customHeaders := make(map[string]string, len(config.CustomHeaders)+1)
+	for key, value := range config.CustomHeaders {
+		customHeaders[key] = value
+	}
+	customHeaders[relaySecretHeader] = relay.secret
+	config.CustomHeaders = customHeaders
+	client := &ApiClient{
+		config:       config,
+		relay:        relay,
+		timeouts:     opts.Timeouts,
+		pollInterval: opts.PollInterval,
+	}
+	if !opts.DisableLookupCache {
+		client.lookups = newLookupCache(DefaultLookupCacheTTL)
+	}
+	return client, nil
+}
+
+// Close stops the relay of the client. Neither the client nor the clients derived from it
+// can be used afterwards. The client of the provider is never closed, as it lives as long as
+// the provider process, but the clients built for a single use must be.
+func (c *ApiClient) Close() error {
+	if c.relay == nil {
+		return nil
+	}
+	return c.relay.close()
+}
+
+// ClumioConfig returns a copy of the SDK configuration used to build the Clumio API
+// controllers.
+func (c *ApiClient) ClumioConfig() clumioConfig.Config {
+	config := c.config
+	if c.config.CustomHeaders != nil {
+		config.CustomHeaders = make(map[string]string, len(c.config.CustomHeaders))
+		for key, value := range c.config.CustomHeaders {
+			config.CustomHeaders[key] = value
+		}
+	}
+	return config
+}
+
+// PollInterval returns the wait between two polls of an asynchronous operation that is polled
+// every intervalInSec seconds.
+func (c *ApiClient) PollInterval(intervalInSec int64) time.Duration {
+	if c.pollInterval > 0 {
+		return c.pollInterval
+	}
+	return time.Duration(intervalInSec) * time.Second
+}
+
+// WithContext returns a client whose API calls are canceled once ctx is done, such as when
+// the operation times out or Terraform is interrupted. The SDK calls take no context, so
+// without it a request keeps being retried after the operation is over.
+func (c *ApiClient) WithContext(ctx context.Context) *ApiClient {
+	if c.relay == nil || ctx.Done() == nil {
+		return c
+	}
+	scoped := *c
+	scoped.config = c.ClumioConfig()
+	if scoped.config.CustomHeaders == nil {
+		scoped.config.CustomHeaders = map[string]string{}
+	}
+	scoped.config.CustomHeaders[relayContextHeader] = c.relay.registerContext(ctx)
+	return &scoped
+}
+
+// WithOrganizationalUnit returns a client whose API calls are made in the context of the
+// given Organizational Unit. The receiver is left untouched so that concurrent operations
+// of other resources keep their own context. If ouId is empty, the receiver is returned
+// and the calls are made in the Organizational Unit context set on the provider.
+func (c *ApiClient) WithOrganizationalUnit(ouId string) *ApiClient {
+	if ouId == "" || ouId == c.config.OrganizationalUnitContext {
+		return c
+	}
+	scoped := *c
+	scoped.config.OrganizationalUnitContext = ouId
+	return &scoped
 }
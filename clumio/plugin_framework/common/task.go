@@ -0,0 +1,287 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the polling of the Clumio tasks backing the asynchronous operations.
+
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	sdkCommon "github.com/clumio-code/clumio-go-sdk/common"
+	"github.com/clumio-code/clumio-go-sdk/models"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// maxTaskPollInterval bounds the wait between two polls of a task. The wait doubles
+	// after every poll, starting from the interval given to PollTask.
+	maxTaskPollInterval = 30 * time.Second
+
+	// taskAcceptHeader is the Accept header of the tasks API.
+	taskAcceptHeader = "application/api.clumio.tasks=v1+json"
+)
+
+// TaskError is returned by PollTask when the task fails or is aborted.
+type TaskError struct {
+	// TaskId is the Clumio-assigned ID of the task.
+	TaskId string
+	// Type is the type of the task, such as policy_update.
+	Type string
+	// Status is the final status of the task.
+	Status string
+	// Reason describes why the task failed, as reported by the tasks API. It is empty if
+	// the API did not report a reason.
+	Reason string
+	// ParentEntity and PrimaryEntity describe the entities the task operated on.
+	ParentEntity  string
+	PrimaryEntity string
+	// Progress is the percentage of the task completed before it ended, or -1 if unknown.
+	Progress int64
+}
+
+// Error implements the error interface.
+func (e *TaskError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "task %s", e.TaskId)
+	if e.Type != "" {
+		fmt.Fprintf(&b, " (%s)", e.Type)
+	}
+	fmt.Fprintf(&b, " %s", e.Status)
+	if e.Reason != "" {
+		fmt.Fprintf(&b, ": %s", e.Reason)
+	}
+	var details []string
+	if e.PrimaryEntity != "" {
+		details = append(details, fmt.Sprintf("entity %s", e.PrimaryEntity))
+	}
+	if e.ParentEntity != "" {
+		details = append(details, fmt.Sprintf("parent entity %s", e.ParentEntity))
+	}
+	if e.Progress >= 0 {
+		details = append(details, fmt.Sprintf("progress %d%%", e.Progress))
+	}
+	if len(details) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(details, ", "))
+	}
+	return b.String()
+}
+
+// taskDetails is the representation of a task returned by the tasks API. On top of the
+// fields modelled by the SDK, the failure reason of a task is reported in the same error
+// envelope as the errors of the API.
+type taskDetails struct {
+	models.ReadTaskResponse
+	errorEnvelope
+}
+
+// errorMessages returns the error messages reported for the task, if any.
+func (t *taskDetails) errorMessages() []string {
+	var messages []string
+	for _, taskErr := range t.Errors {
+		if taskErr.ErrorMessage != "" {
+			messages = append(messages, taskErr.ErrorMessage)
+		}
+	}
+	if len(messages) == 0 && t.ErrorMessage != "" {
+		messages = append(messages, t.ErrorMessage)
+	}
+	return messages
+}
+
+// reason returns the failure reason of the task, or an empty string if there is none.
+func (t *taskDetails) reason() string {
+	return strings.Join(t.errorMessages(), " ")
+}
+
+// PollTask polls the task until it completes. The wait between two polls starts at
+// intervalInSec and doubles after every poll, up to maxTaskPollInterval. Every change of the
+// status or progress of the task is logged. If the task fails or is aborted, the returned
+// error is a *TaskError describing why. Polling stops when the deadline of ctx, derived from
+// the timeouts of the operation, is reached.
+func PollTask(ctx context.Context, apiClient *ApiClient,
+	taskId string, intervalInSec int64) error {
+	interval, maxInterval := taskPollIntervals(apiClient, intervalInSec)
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	lastStatus, lastProgress := "", int64(-1)
+	for {
+		select {
+		case <-ctx.Done():
+			return pollTaskContextError(ctx, taskId, lastStatus)
+		case <-timer.C:
+		}
+
+		task, err := readTask(apiClient.WithContext(ctx), taskId)
+		if err != nil {
+			// A read interrupted by the end of the operation fails with the error of the
+			// relayed request instead.
+			if ctx.Err() != nil {
+				return pollTaskContextError(ctx, taskId, lastStatus)
+			}
+			return err
+		}
+		status := stringValue(task.Status)
+		progress := int64(-1)
+		if task.ProgressPercentage != nil {
+			progress = *task.ProgressPercentage
+		}
+		if status != lastStatus || progress != lastProgress {
+			fields := map[string]any{
+				"task_id":             taskId,
+				"task_type":           stringValue(task.ClumioType),
+				"status":              status,
+				"progress_percentage": progress,
+			}
+			if task.ParentEntity != nil {
+				fields["parent_entity"] = taskEntity(task.ParentEntity.ClumioType,
+					task.ParentEntity.Id, task.ParentEntity.Value)
+			}
+			if status != lastStatus {
+				tflog.Info(ctx, "Clumio task status changed", fields)
+			} else {
+				tflog.Debug(ctx, "Clumio task progressed", fields)
+			}
+			lastStatus, lastProgress = status, progress
+		}
+
+		switch status {
+		case TaskSuccess:
+			return nil
+		case TaskAborted, TaskFailed:
+			return task.taskError(taskId)
+		}
+
+		interval = nextTaskPollInterval(interval, maxInterval)
+		timer.Reset(interval)
+	}
+}
+
+// pollTaskContextError returns the error of PollTask once ctx is done, describing the timeout
+// of the operation.
+func pollTaskContextError(ctx context.Context, taskId string, lastStatus string) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out waiting for task %s to complete (last status %q)",
+			taskId, lastStatus)
+	}
+	return ctx.Err()
+}
+
+// taskPollIntervals returns the wait before the first poll of a task polled every
+// intervalInSec, and the maximum wait between two polls. The waits do not grow when the
+// interval is longer than maxTaskPollInterval or replaced by the one of the client.
+func taskPollIntervals(apiClient *ApiClient, intervalInSec int64) (
+	time.Duration, time.Duration) {
+	interval := apiClient.PollInterval(intervalInSec)
+	if interval <= 0 {
+		interval = time.Second
+	}
+	maxInterval := maxTaskPollInterval
+	if interval > maxInterval || apiClient.pollInterval > 0 {
+		maxInterval = interval
+	}
+	return interval, maxInterval
+}
+
+// nextTaskPollInterval returns the wait following the given one between two polls of a task,
+// which doubles up to maxInterval.
+func nextTaskPollInterval(interval time.Duration, maxInterval time.Duration) time.Duration {
+	interval *= 2
+	if interval > maxInterval {
+		return maxInterval
+	}
+	return interval
+}
+
+// ReadTaskStatus reads the task once and returns its status, such as in_progress or
+// completed. If the task failed or was aborted, the returned error is a *TaskError
+// describing why.
+func ReadTaskStatus(apiClient *ApiClient, taskId string) (string, error) {
+	task, err := readTask(apiClient, taskId)
+	if err != nil {
+		return "", err
+	}
+	status := stringValue(task.Status)
+	if status == TaskAborted || status == TaskFailed {
+		return status, task.taskError(taskId)
+	}
+	return status, nil
+}
+
+// ReadTask reads the task once and returns it along with the error messages reported for
+// it, if any. Unlike ReadTaskStatus, a task that failed or was aborted is not an error.
+func ReadTask(apiClient *ApiClient, taskId string) (
+	*models.ReadTaskResponse, []string, error) {
+	task, err := readTask(apiClient, taskId)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &task.ReadTaskResponse, task.errorMessages(), nil
+}
+
+// readTask reads the task from the tasks API.
+func readTask(apiClient *ApiClient, taskId string) (*taskDetails, error) {
+	config := apiClient.ClumioConfig()
+	task := &taskDetails{}
+	apiErr := sdkCommon.InvokeAPI(&sdkCommon.InvokeAPIRequest{
+		Config:       config,
+		RequestUrl:   config.BaseUrl + "/tasks/{task_id}",
+		PathParams:   map[string]string{"task_id": taskId},
+		AcceptHeader: taskAcceptHeader,
+		Result200:    &task,
+		RequestType:  sdkCommon.Get,
+	})
+	if apiErr != nil {
+		return nil, fmt.Errorf("unable to read task %s: %w", taskId, NewAPIError(apiErr))
+	}
+	return task, nil
+}
+
+// taskError returns the error describing the failure of the task.
+func (t *taskDetails) taskError(taskId string) *TaskError {
+	taskErr := &TaskError{
+		TaskId:   taskId,
+		Type:     stringValue(t.ClumioType),
+		Status:   stringValue(t.Status),
+		Reason:   t.reason(),
+		Progress: -1,
+	}
+	if t.ProgressPercentage != nil {
+		taskErr.Progress = *t.ProgressPercentage
+	}
+	if t.ParentEntity != nil {
+		taskErr.ParentEntity = taskEntity(t.ParentEntity.ClumioType, t.ParentEntity.Id,
+			t.ParentEntity.Value)
+	}
+	if t.PrimaryEntity != nil {
+		taskErr.PrimaryEntity = taskEntity(t.PrimaryEntity.ClumioType, t.PrimaryEntity.Id,
+			t.PrimaryEntity.Value)
+	}
+	return taskErr
+}
+
+// taskEntity describes an entity of a task, such as `policy "gold" (id)`.
+func taskEntity(entityType, id, value *string) string {
+	parts := make([]string, 0, 3)
+	if entityType != nil && *entityType != "" {
+		parts = append(parts, *entityType)
+	}
+	if value != nil && *value != "" {
+		parts = append(parts, fmt.Sprintf("%q", *value))
+	}
+	if id != nil && *id != "" {
+		parts = append(parts, fmt.Sprintf("(%s)", *id))
+	}
+	return strings.Join(parts, " ")
+}
+
+// stringValue returns the value of the string pointer, or an empty string if it is nil.
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
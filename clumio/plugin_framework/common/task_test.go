@@ -0,0 +1,213 @@
+// Copyright 2024. Clumio, Inc.
+
+// Unit tests of the polling of the Clumio tasks.
+package common
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	clumioConfig "github.com/clumio-code/clumio-go-sdk/config"
+)
+
+// newTestTaskClient returns a client of the tasks API served by handler, whose tasks are
+// polled every pollInterval.
+func newTestTaskClient(t *testing.T, handler http.HandlerFunc,
+	pollInterval time.Duration) *ApiClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client, err := NewApiClient(context.Background(),
+		clumioConfig.Config{BaseUrl: server.URL}, ApiClientOptions{
+			Retry: RetryConfig{
+				MaxRetries: DefaultMaxRetries, MaxBackoff: DefaultRetryMaxBackoff,
+			},
+			PollInterval: pollInterval,
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// taskHandler serves the tasks with the given representations in turn, the last one being
+// served from then on, and counts the reads of the tasks.
+func taskHandler(reads *atomic.Int32, tasks ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		read := int(reads.Add(1))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(tasks[min(read, len(tasks))-1]))
+	}
+}
+
+func TestTaskPollIntervals(t *testing.T) {
+	for name, test := range map[string]struct {
+		intervalInSec int64
+		pollInterval  time.Duration
+		want          []time.Duration
+	}{
+		"backoff up to the cap": {
+			intervalInSec: 1,
+			want: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second,
+				8 * time.Second, 16 * time.Second, 30 * time.Second, 30 * time.Second},
+		},
+		"interval above the cap": {
+			intervalInSec: 45,
+			want:          []time.Duration{45 * time.Second, 45 * time.Second},
+		},
+		"no interval": {
+			want: []time.Duration{time.Second, 2 * time.Second},
+		},
+		"interval of the client": {
+			intervalInSec: 5,
+			pollInterval:  10 * time.Millisecond,
+			want:          []time.Duration{10 * time.Millisecond, 10 * time.Millisecond},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client := &ApiClient{pollInterval: test.pollInterval}
+			interval, maxInterval := taskPollIntervals(client, test.intervalInSec)
+			var got []time.Duration
+			for range test.want {
+				got = append(got, interval)
+				interval = nextTaskPollInterval(interval, maxInterval)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got the intervals %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPollTask(t *testing.T) {
+	const inProgress = `{"id": "task-1", "type": "policy_update", "status": "in_progress",` +
+		` "progress_percentage": 40}`
+	for name, test := range map[string]struct {
+		tasks     []string
+		wantReads int32
+		wantErr   *TaskError
+	}{
+		"completed": {
+			tasks: []string{inProgress, inProgress,
+				`{"id": "task-1", "status": "completed"}`},
+			wantReads: 3,
+		},
+		"failed": {
+			tasks: []string{inProgress, `{"id": "task-1", "type": "policy_update",` +
+				` "status": "failed", "progress_percentage": 60,` +
+				` "primary_entity": {"type": "policy", "id": "policy-1", "value": "gold"},` +
+				` "parent_entity": {"type": "organizational_unit", "id": "ou-1"},` +
+				` "errors": [{"error_message": "The policy is in use."}]}`},
+			wantReads: 2,
+			wantErr: &TaskError{
+				TaskId:        "task-1",
+				Type:          "policy_update",
+				Status:        TaskFailed,
+				Reason:        "The policy is in use.",
+				ParentEntity:  "organizational_unit (ou-1)",
+				PrimaryEntity: `policy "gold" (policy-1)`,
+				Progress:      60,
+			},
+		},
+		"aborted": {
+			tasks:     []string{`{"id": "task-1", "status": "aborted"}`},
+			wantReads: 1,
+			wantErr: &TaskError{
+				TaskId:   "task-1",
+				Status:   TaskAborted,
+				Progress: -1,
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var reads atomic.Int32
+			client := newTestTaskClient(t, taskHandler(&reads, test.tasks...),
+				time.Millisecond)
+			err := PollTask(context.Background(), client, "task-1", 1)
+			if got := reads.Load(); got != test.wantReads {
+				t.Errorf("got %d reads of the task, want %d", got, test.wantReads)
+			}
+			if test.wantErr == nil {
+				if err != nil {
+					t.Errorf("got the error %v, want none", err)
+				}
+				return
+			}
+			var taskErr *TaskError
+			if !errors.As(err, &taskErr) {
+				t.Fatalf("got the error %v, want a *TaskError", err)
+			}
+			if !reflect.DeepEqual(taskErr, test.wantErr) {
+				t.Errorf("got the error %+v, want %+v", taskErr, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestPollTaskDeadline(t *testing.T) {
+	var reads atomic.Int32
+	client := newTestTaskClient(t, taskHandler(&reads,
+		`{"id": "task-1", "status": "in_progress"}`), time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := PollTask(ctx, client, "task-1", 1)
+	want := `timed out waiting for task task-1 to complete (last status "in_progress")`
+	if err == nil || err.Error() != want {
+		t.Errorf("got the error %v, want %q", err, want)
+	}
+	if reads.Load() == 0 {
+		t.Error("got no reads of the task before the deadline")
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := PollTask(ctx, client, "task-1", 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("got the error %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestPollTaskReadError(t *testing.T) {
+	client := newTestTaskClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errors": [{"error_message": "Task not found."}]}`))
+	}, time.Millisecond)
+	err := PollTask(context.Background(), client, "task-1", 1)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !apiErr.IsNotFound() {
+		t.Errorf("got the error %v, want a not found API error", err)
+	}
+}
+
+func TestTaskErrorError(t *testing.T) {
+	for name, test := range map[string]struct {
+		err  *TaskError
+		want string
+	}{
+		"all details": {
+			err: &TaskError{TaskId: "task-1", Type: "policy_update", Status: TaskFailed,
+				Reason: "The policy is in use.", PrimaryEntity: `policy "gold" (policy-1)`,
+				ParentEntity: "organizational_unit (ou-1)", Progress: 60},
+			want: `task task-1 (policy_update) failed: The policy is in use. (entity` +
+				` policy "gold" (policy-1), parent entity organizational_unit (ou-1),` +
+				` progress 60%)`,
+		},
+		"no details": {
+			err:  &TaskError{TaskId: "task-1", Status: TaskAborted, Progress: -1},
+			want: "task task-1 aborted",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := test.err.Error(); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the timeouts of the operations of the resources backed by Clumio tasks.
+
+package common
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultOperationTimeout is the timeout of the create, update and delete operations of the
+// resources when neither their timeouts block nor the provider default_timeouts block sets
+// one.
+const DefaultOperationTimeout = time.Hour
+
+// OperationTimeouts holds the default timeouts of the resource operations. The timeouts
+// block of a resource takes precedence over them.
+type OperationTimeouts struct {
+	Create time.Duration
+	Update time.Duration
+	Delete time.Duration
+}
+
+// DefaultOperationTimeouts returns the timeouts used when the provider configuration does
+// not set default_timeouts.
+func DefaultOperationTimeouts() OperationTimeouts {
+	return OperationTimeouts{
+		Create: DefaultOperationTimeout,
+		Update: DefaultOperationTimeout,
+		Delete: DefaultOperationTimeout,
+	}
+}
+
+// Validate returns an error if the timeouts cannot be used.
+func (t OperationTimeouts) Validate() error {
+	for name, timeout := range map[string]time.Duration{
+		"create": t.Create,
+		"update": t.Update,
+		"delete": t.Delete,
+	} {
+		if timeout <= 0 {
+			return fmt.Errorf("the default %s timeout must be positive, got %s", name, timeout)
+		}
+	}
+	return nil
+}
+
+// Timeouts returns the default timeouts of the resource operations set on the provider.
+func (c *ApiClient) Timeouts() OperationTimeouts {
+	return c.timeouts
+}
@@ -0,0 +1,224 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the retrieval of short-lived API tokens from an external command.
+
+package common
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// tokenCommandTimeout bounds every run of the token command.
+	tokenCommandTimeout = time.Minute
+	// tokenRefreshWindow is how long before its expiry a token is replaced. Tokens with a
+	// shorter lifetime are replaced halfway through it.
+	tokenRefreshWindow = 5 * time.Minute
+	// maxTokenCommandStderr bounds the size of the standard error of the token command
+	// included in the errors.
+	maxTokenCommandStderr = 1024
+)
+
+// tokenCommandOutput is the JSON document that the token command writes to its standard
+// output:
+//
+//	{"token": "...", "expiration": "2024-01-02T15:04:05Z"}
+//
+// The expiration is optional. When it is missing, the exp claim of the token is used if the
+// token is a JWT, otherwise the token is assumed not to expire.
+type tokenCommandOutput struct {
+	Token      string     `json:"token"`
+	Expiration *time.Time `json:"expiration"`
+}
+
+// commandTokenSource hands out the API token obtained from the token command and runs the
+// command again when the token is about to expire.
+type commandTokenSource struct {
+	command string
+
+	mu    sync.Mutex
+	token string
+	// expiry is the zero time if the token does not expire.
+	expiry time.Time
+	// refreshAt is the time after which the token is replaced.
+	refreshAt time.Time
+}
+
+// newCommandTokenSource returns a token source backed by the given command. The command is
+// run once to check that it works and to obtain the initial token.
+func newCommandTokenSource(ctx context.Context, command string) (*commandTokenSource, error) {
+	s := &commandTokenSource{command: command}
+	if _, err := s.Token(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Token returns a valid API token, running the token command if the current token is
+// missing or about to expire.
+func (s *commandTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && (s.refreshAt.IsZero() || time.Now().Before(s.refreshAt)) {
+		return s.token, nil
+	}
+	return s.refreshLocked(ctx)
+}
+
+// Refresh runs the token command to replace a token that the Clumio API rejected, such as
+// a revoked token or one that expired before its announced expiry. The command is not run
+// if the rejected token was already replaced by a concurrent request.
+func (s *commandTokenSource) Refresh(ctx context.Context, rejected string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != rejected {
+		return s.token, nil
+	}
+	return s.refreshLocked(ctx)
+}
+
+// refreshLocked runs the token command and keeps its token. s.mu must be held.
+func (s *commandTokenSource) refreshLocked(ctx context.Context) (string, error) {
+	token, expiry, err := runTokenCommand(ctx, s.command)
+	if err != nil {
+		return "", err
+	}
+	s.token, s.expiry, s.refreshAt = token, expiry, time.Time{}
+	fields := map[string]any{}
+	if !expiry.IsZero() {
+		window := tokenRefreshWindow
+		if lifetime := time.Until(expiry); lifetime < 2*window {
+			window = lifetime / 2
+		}
+		s.refreshAt = expiry.Add(-window)
+		fields["expiration"] = expiry.Format(time.RFC3339)
+	}
+	tflog.Debug(ctx, "Obtained a Clumio API token from token_command", fields)
+	return s.token, nil
+}
+
+// runTokenCommand runs the token command with the shell of the platform and returns the
+// token and its expiry from its output.
+func runTokenCommand(ctx context.Context, command string) (string, time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, tokenCommandTimeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out after %s", tokenCommandTimeout)
+		}
+		message := strings.TrimSpace(stderr.String())
+		if len(message) > maxTokenCommandStderr {
+			message = message[:maxTokenCommandStderr] + "...(truncated)"
+		}
+		if message != "" {
+			return "", time.Time{}, fmt.Errorf("token_command failed: %w: %s", err, message)
+		}
+		return "", time.Time{}, fmt.Errorf("token_command failed: %w", err)
+	}
+
+	var output tokenCommandOutput
+	// The output is not included in the errors since it may hold the token.
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return "", time.Time{}, errors.New("token_command must write a JSON object such as" +
+			` {"token": "...", "expiration": "2024-01-02T15:04:05Z"} to its standard output`)
+	}
+	if output.Token == "" {
+		return "", time.Time{}, errors.New("the output of token_command has no token")
+	}
+	if output.Expiration != nil {
+		if !output.Expiration.After(time.Now()) {
+			return "", time.Time{}, fmt.Errorf("token_command returned a token that expired"+
+				" at %s", output.Expiration.Format(time.RFC3339))
+		}
+		return output.Token, *output.Expiration, nil
+	}
+	if expiry, ok := tokenExpiry(output.Token); ok {
+		return output.Token, expiry, nil
+	}
+	return output.Token, time.Time{}, nil
+}
+
+// tokenTransport is an http.RoundTripper that authenticates every attempt of a request
+// with the current token of the token command, in place of the token given to the SDK. A
+// request rejected with 401 Unauthorized is sent once more with a token from a new run of
+// the command.
+type tokenTransport struct {
+	next   http.RoundTripper
+	source *commandTokenSource
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	token, err := t.source.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to refresh the Clumio API token: %w", err)
+	}
+	resp, err := t.next.RoundTrip(authenticatedRequest(req, body, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	refreshed, err := t.source.Refresh(ctx, token)
+	if err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unable to refresh the Clumio API token rejected by the"+
+			" Clumio API: %w", err)
+	}
+	if refreshed == token {
+		// The command returned the rejected token again, which would be rejected as well.
+		return resp, nil
+	}
+	tflog.Debug(ctx, "Retrying Clumio API request with a new token from token_command",
+		map[string]any{"method": req.Method, "path": req.URL.Path})
+	// Drain the body so that the connection can be reused for the next attempt.
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return t.next.RoundTrip(authenticatedRequest(req, body, refreshed))
+}
+
+// authenticatedRequest returns a copy of the request with the given body, authenticated
+// with the token. The token is masked in the wire log of the request, as it differs from
+// the token masked by the relay once it has been refreshed.
+func authenticatedRequest(req *http.Request, body []byte, token string) *http.Request {
+	ctx := tflog.SubsystemMaskAllFieldValuesStrings(req.Context(), WireLogSubsystem, token)
+	ctx = tflog.SubsystemMaskMessageStrings(ctx, WireLogSubsystem, token)
+	authReq := req.Clone(ctx)
+	if body != nil {
+		authReq.Body = io.NopCloser(bytes.NewReader(body))
+		authReq.ContentLength = int64(len(body))
+	}
+	authReq.Header.Set("Authorization", "Bearer "+token)
+	return authReq
+}
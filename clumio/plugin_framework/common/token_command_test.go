@@ -0,0 +1,241 @@
+// Copyright 2024. Clumio, Inc.
+
+// Unit tests of the API tokens obtained from token_command.
+package common
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+// fakeTokenCommand returns a shell command that prints the token token-<n> on its n-th run,
+// and the file counting its runs. If sameToken is set, every run prints token-1.
+func fakeTokenCommand(t *testing.T, sameToken bool) (string, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake token command needs a POSIX shell")
+	}
+	runs := filepath.Join(t.TempDir(), "runs")
+	token := "token-$n"
+	if sameToken {
+		token = "token-1"
+	}
+	command := fmt.Sprintf(`n=$(($(cat %[1]q 2>/dev/null || echo 0) + 1)); echo $n > %[1]q;`+
+		` printf '{"token": "%%s"}' "%[2]s"`, runs, token)
+	return command, runs
+}
+
+// tokenCommandRuns returns the number of runs of the fake token command.
+func tokenCommandRuns(t *testing.T, runs string) string {
+	t.Helper()
+	count, err := os.ReadFile(runs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(count))
+}
+
+func TestRunTokenCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the token commands need a POSIX shell")
+	}
+	expiration := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	for name, test := range map[string]struct {
+		command    string
+		wantToken  string
+		wantExpiry time.Time
+		wantErr    string
+	}{
+		"token and expiration": {
+			command: fmt.Sprintf(`echo '{"token": "secret", "expiration": "%s"}'`,
+				expiration.Format(time.RFC3339)),
+			wantToken:  "secret",
+			wantExpiry: expiration,
+		},
+		"token without expiration": {
+			command:   `echo '{"token": "secret"}'`,
+			wantToken: "secret",
+		},
+		"expired token": {
+			command: `echo '{"token": "secret", "expiration": "2020-01-02T15:04:05Z"}'`,
+			wantErr: "token_command returned a token that expired at 2020-01-02T15:04:05Z",
+		},
+		"no token": {
+			command: `echo '{"expiration": null}'`,
+			wantErr: "the output of token_command has no token",
+		},
+		"not JSON": {
+			command: `echo secret`,
+			wantErr: "token_command must write a JSON object",
+		},
+		"failure": {
+			command: `echo 'not logged in' >&2; exit 3`,
+			wantErr: "token_command failed: exit status 3: not logged in",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			token, expiry, err := runTokenCommand(context.Background(), test.command)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("got the error %v, want %q", err, test.wantErr)
+				}
+				if err != nil && strings.Contains(err.Error(), "secret") {
+					t.Errorf("got the token in the error %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token != test.wantToken || !expiry.Equal(test.wantExpiry) {
+				t.Errorf("got the token %q expiring at %v, want %q expiring at %v", token,
+					expiry, test.wantToken, test.wantExpiry)
+			}
+		})
+	}
+}
+
+func TestCommandTokenSourceToken(t *testing.T) {
+	command, runs := fakeTokenCommand(t, false)
+	ctx := context.Background()
+	source, err := newCommandTokenSource(ctx, command)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A token without an expiry is kept.
+	if token, err := source.Token(ctx); err != nil || token != "token-1" {
+		t.Errorf("got the token %q and the error %v, want token-1", token, err)
+	}
+	// A token past its refresh time is replaced.
+	source.refreshAt = time.Now().Add(-time.Second)
+	if token, err := source.Token(ctx); err != nil || token != "token-2" {
+		t.Errorf("got the token %q and the error %v, want token-2", token, err)
+	}
+	// A token that was already replaced is not refreshed again.
+	if token, err := source.Refresh(ctx, "token-1"); err != nil || token != "token-2" {
+		t.Errorf("got the token %q and the error %v, want token-2", token, err)
+	}
+	if got := tokenCommandRuns(t, runs); got != "2" {
+		t.Errorf("got %s runs of the token command, want 2", got)
+	}
+	if token, err := source.Refresh(ctx, "token-2"); err != nil || token != "token-3" {
+		t.Errorf("got the token %q and the error %v, want token-3", token, err)
+	}
+}
+
+func TestTokenTransportRefreshesRejectedToken(t *testing.T) {
+	for name, test := range map[string]struct {
+		sameToken  bool
+		wantStatus int
+		wantRuns   string
+		wantBodies []string
+	}{
+		"new token": {
+			wantStatus: http.StatusOK,
+			wantRuns:   "2",
+			wantBodies: []string{`{"name":"gold"}`, `{"name":"gold"}`},
+		},
+		"same token": {
+			sameToken:  true,
+			wantStatus: http.StatusUnauthorized,
+			wantRuns:   "2",
+			wantBodies: []string{`{"name":"gold"}`},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("TF_LOG_PROVIDER_CLUMIO_API", "TRACE")
+			var bodies []string
+			server := httptest.NewServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					body, _ := io.ReadAll(r.Body)
+					bodies = append(bodies, string(body))
+					// The response names the token, to check that the wire log masks it.
+					authorization := r.Header.Get("Authorization")
+					if authorization != "Bearer token-2" {
+						w.WriteHeader(http.StatusUnauthorized)
+					}
+					fmt.Fprintf(w, `{"message": "authenticated with %s"}`, authorization)
+				}))
+			defer server.Close()
+
+			command, runs := fakeTokenCommand(t, test.sameToken)
+			var output bytes.Buffer
+			ctx := newWireLogContext(tflogtest.RootLogger(context.Background(), &output),
+				"token-1")
+			source, err := newCommandTokenSource(ctx, command)
+			if err != nil {
+				t.Fatal(err)
+			}
+			transport := &tokenTransport{
+				next:   &wireLogTransport{next: http.DefaultTransport, trace: true},
+				source: source,
+			}
+			req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL,
+				strings.NewReader(`{"name":"gold"}`))
+			resp, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != test.wantStatus {
+				t.Errorf("got the status %d, want %d", resp.StatusCode, test.wantStatus)
+			}
+			if got := tokenCommandRuns(t, runs); got != test.wantRuns {
+				t.Errorf("got %s runs of the token command, want %s", got, test.wantRuns)
+			}
+			if strings.Join(bodies, " ") != strings.Join(test.wantBodies, " ") {
+				t.Errorf("got the request bodies %q, want %q", bodies, test.wantBodies)
+			}
+			logged := output.String()
+			for _, token := range []string{"token-1", "token-2"} {
+				if strings.Contains(logged, token) {
+					t.Errorf("got %q in the wire log: %s", token, logged)
+				}
+			}
+			if !strings.Contains(logged, "authenticated with") {
+				t.Errorf("got no response body in the wire log: %s", logged)
+			}
+		})
+	}
+}
+
+func TestTokenTransportRefreshError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the token command needs a POSIX shell")
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	// The command fails on its second run.
+	runs := filepath.Join(t.TempDir(), "runs")
+	command := fmt.Sprintf(`if [ -e %[1]q ]; then echo 'session expired' >&2; exit 1; fi;`+
+		` touch %[1]q; echo '{"token": "token-1"}'`, runs)
+	source, err := newCommandTokenSource(context.Background(), command)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := &tokenTransport{next: http.DefaultTransport, source: source}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := transport.RoundTrip(req)
+	want := "unable to refresh the Clumio API token rejected by the Clumio API:" +
+		" token_command failed: exit status 1: session expired"
+	if resp != nil || err == nil || err.Error() != want {
+		t.Errorf("got the response %v and the error %v, want %q", resp, err, want)
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the network settings of the connections made to the Clumio API.
+
+package common
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// TransportConfig controls how the provider connects to the Clumio API. The zero value
+// uses the proxy environment variables and the system trust store.
+type TransportConfig struct {
+	// HttpProxy and HttpsProxy are the URLs of the proxies used for http and https API
+	// base URLs. An empty value falls back to the HTTP_PROXY or HTTPS_PROXY environment
+	// variable. NO_PROXY is always honoured.
+	HttpProxy  string
+	HttpsProxy string
+	// CABundle holds PEM encoded certificates trusted in addition to the system roots,
+	// such as the CA of a proxy that re-signs TLS connections.
+	CABundle []byte
+	// ClientCertificate and ClientKey hold the PEM encoded certificate and private key
+	// presented to servers that require mutual TLS. Both or none must be set.
+	ClientCertificate []byte
+	ClientKey         []byte
+	// RequestTimeout bounds every attempt of a request, from sending it to reading the
+	// response body. Zero means no timeout.
+	RequestTimeout time.Duration
+	// InsecureSkipVerify disables the verification of the server certificate.
+	InsecureSkipVerify bool
+}
+
+// Validate returns an error if the transport configuration cannot be used.
+func (c TransportConfig) Validate() error {
+	for name, proxy := range map[string]string{
+		"http_proxy":  c.HttpProxy,
+		"https_proxy": c.HttpsProxy,
+	} {
+		if proxy == "" {
+			continue
+		}
+		if parsed, err := url.Parse(proxy); err != nil || parsed.Host == "" {
+			return fmt.Errorf("%s must be a URL such as http://proxy.example.com:3128, got %q",
+				name, proxy)
+		}
+	}
+	if (len(c.ClientCertificate) == 0) != (len(c.ClientKey) == 0) {
+		return errors.New("the client certificate and the client key must be set together")
+	}
+	if c.RequestTimeout < 0 {
+		return fmt.Errorf("request_timeout must not be negative, got %s", c.RequestTimeout)
+	}
+	return nil
+}
+
+// newBaseTransport returns the transport that opens the connections to the Clumio API.
+func newBaseTransport(config TransportConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.HttpProxy != "" || config.HttpsProxy != "" {
+		proxyConfig := httpproxy.FromEnvironment()
+		if config.HttpProxy != "" {
+			proxyConfig.HTTPProxy = config.HttpProxy
+		}
+		if config.HttpsProxy != "" {
+			proxyConfig.HTTPSProxy = config.HttpsProxy
+		}
+		proxyFunc := proxyConfig.ProxyFunc()
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxyFunc(req.URL)
+		}
+	}
+	if len(config.CABundle) == 0 && len(config.ClientCertificate) == 0 &&
+		!config.InsecureSkipVerify {
+		return transport, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// The user explicitly opted out of the verification of the server certificate.
+		InsecureSkipVerify: config.InsecureSkipVerify, // #nosec G402
+	}
+	if len(config.CABundle) > 0 {
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil || rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM(config.CABundle) {
+			return nil, errors.New("the CA bundle does not contain any PEM encoded certificate")
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+	if len(config.ClientCertificate) > 0 {
+		certificate, err := tls.X509KeyPair(config.ClientCertificate, config.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate or key: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// timeoutTransport is an http.RoundTripper that bounds every attempt of a request. It is
+// placed below the retry transport so that an attempt that times out can be retried.
+type timeoutTransport struct {
+	next    http.RoundTripper
+	timeout time.Duration
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded) && req.Context().Err() == nil
+		cancel()
+		if timedOut {
+			return nil, &requestTimeoutError{method: req.Method, path: req.URL.Path,
+				timeout: t.timeout}
+		}
+		return nil, err
+	}
+	// The deadline also covers reading the body, so it is only released once the body is
+	// closed.
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: cancel}
+	return resp, nil
+}
+
+// requestTimeoutError is returned when an attempt exceeds the request timeout. It is a
+// net.Error whose Timeout method returns true so that idempotent requests are retried.
+type requestTimeoutError struct {
+	method  string
+	path    string
+	timeout time.Duration
+}
+
+// Error implements the error interface.
+func (e *requestTimeoutError) Error() string {
+	return fmt.Sprintf("%s %s did not complete within the request timeout of %s", e.method,
+		e.path, e.timeout)
+}
+
+// Timeout implements net.Error.
+func (e *requestTimeoutError) Timeout() bool {
+	return true
+}
+
+// Temporary implements net.Error.
+func (e *requestTimeoutError) Temporary() bool {
+	return true
+}
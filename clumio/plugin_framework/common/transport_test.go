@@ -0,0 +1,293 @@
+// Copyright 2024. Clumio, Inc.
+
+// Unit tests of the network settings of the connections made to the Clumio API.
+package common
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testClientCertificate returns a self-signed client certificate and its private key, PEM
+// encoded.
+func testClientCertificate(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "terraform"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey,
+		key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encodedKey, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: encodedKey})
+}
+
+// serverCABundle returns the certificate of the TLS test server, PEM encoded.
+func serverCABundle(server *httptest.Server) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE",
+		Bytes: server.Certificate().Raw})
+}
+
+func TestTransportConfigValidate(t *testing.T) {
+	for name, test := range map[string]struct {
+		config TransportConfig
+		// wantErr is a part of the expected error, if any.
+		wantErr string
+	}{
+		"zero value": {},
+		"proxies": {
+			config: TransportConfig{
+				HttpProxy:  "http://proxy.example.com:3128",
+				HttpsProxy: "http://proxy.example.com:3129",
+			},
+		},
+		"proxy without host": {
+			config:  TransportConfig{HttpsProxy: "proxy.example.com:3128"},
+			wantErr: "https_proxy must be a URL",
+		},
+		"invalid proxy": {
+			config:  TransportConfig{HttpProxy: "http://proxy.example.com:port"},
+			wantErr: "http_proxy must be a URL",
+		},
+		"client certificate without key": {
+			config:  TransportConfig{ClientCertificate: []byte("certificate")},
+			wantErr: "the client certificate and the client key must be set together",
+		},
+		"client key without certificate": {
+			config:  TransportConfig{ClientKey: []byte("key")},
+			wantErr: "the client certificate and the client key must be set together",
+		},
+		"negative request timeout": {
+			config:  TransportConfig{RequestTimeout: -time.Second},
+			wantErr: "request_timeout must not be negative, got -1s",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := test.config.Validate()
+			if test.wantErr == "" {
+				if err != nil {
+					t.Errorf("got the error %v, want none", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("got the error %v, want %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewBaseTransportProxy(t *testing.T) {
+	t.Setenv("HTTP_PROXY", "http://env-proxy.example.com:3128")
+	t.Setenv("HTTPS_PROXY", "")
+	t.Setenv("NO_PROXY", "internal.example.com")
+	transport, err := newBaseTransport(TransportConfig{
+		HttpsProxy: "http://proxy.example.com:3129",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for target, want := range map[string]string{
+		"https://us-west-2.api.clumio.com/tasks": "http://proxy.example.com:3129",
+		// The proxy of the environment is used when the configuration sets none.
+		"http://clumio.example.com/tasks":     "http://env-proxy.example.com:3128",
+		"https://internal.example.com/tasks":  "",
+		"https://api.internal.example.com/v1": "",
+	} {
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		proxy, err := transport.Proxy(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := ""
+		if proxy != nil {
+			got = proxy.String()
+		}
+		if got != want {
+			t.Errorf("got the proxy %q for %s, want %q", got, target, want)
+		}
+	}
+}
+
+func TestNewBaseTransportTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	for name, test := range map[string]struct {
+		config  TransportConfig
+		wantErr bool
+	}{
+		"system trust store": {
+			wantErr: true,
+		},
+		"CA bundle": {
+			config: TransportConfig{CABundle: serverCABundle(server)},
+		},
+		"insecure skip verify": {
+			config: TransportConfig{InsecureSkipVerify: true},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			transport, err := newBaseTransport(test.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer transport.CloseIdleConnections()
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			resp, err := transport.RoundTrip(req)
+			if test.wantErr {
+				var unknownAuthority x509.UnknownAuthorityError
+				if !errors.As(err, &unknownAuthority) {
+					t.Errorf("got the error %v, want an unknown authority error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusNoContent {
+				t.Errorf("got the status %d, want %d", resp.StatusCode, http.StatusNoContent)
+			}
+		})
+	}
+}
+
+func TestNewBaseTransportClientCertificate(t *testing.T) {
+	var subjects []string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			for _, certificate := range r.TLS.PeerCertificates {
+				subjects = append(subjects, certificate.Subject.CommonName)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	certificate, key := testClientCertificate(t)
+	transport, err := newBaseTransport(TransportConfig{
+		CABundle:          serverCABundle(server),
+		ClientCertificate: certificate,
+		ClientKey:         key,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer transport.CloseIdleConnections()
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if strings.Join(subjects, " ") != "terraform" {
+		t.Errorf("got the client certificates %v, want the one of terraform", subjects)
+	}
+}
+
+func TestNewBaseTransportErrors(t *testing.T) {
+	certificate, key := testClientCertificate(t)
+	_, otherKey := testClientCertificate(t)
+	for name, test := range map[string]struct {
+		config  TransportConfig
+		wantErr string
+	}{
+		"CA bundle without certificates": {
+			config:  TransportConfig{CABundle: []byte("not a certificate")},
+			wantErr: "the CA bundle does not contain any PEM encoded certificate",
+		},
+		"invalid client certificate": {
+			config: TransportConfig{
+				ClientCertificate: []byte("not a certificate"),
+				ClientKey:         key,
+			},
+			wantErr: "invalid client certificate or key",
+		},
+		"mismatched client key": {
+			config: TransportConfig{
+				ClientCertificate: certificate,
+				ClientKey:         otherKey,
+			},
+			wantErr: "invalid client certificate or key",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := newBaseTransport(test.config)
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("got the error %v, want %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestTimeoutTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	transport := &timeoutTransport{next: http.DefaultTransport, timeout: 50 * time.Millisecond}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/fast", nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/slow", nil)
+	_, err = transport.RoundTrip(req)
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Fatalf("got the error %v, want a timeout", err)
+	}
+	want := "GET /slow did not complete within the request timeout of 50ms"
+	if err.Error() != want {
+		t.Errorf("got the error %q, want %q", err, want)
+	}
+
+	// The cancellation of the request is not reported as a timeout.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/slow", nil)
+	if _, err := transport.RoundTrip(req); !errors.Is(err, context.Canceled) {
+		t.Errorf("got the error %v, want %v", err, context.Canceled)
+	}
+}
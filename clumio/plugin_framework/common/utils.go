@@ -6,13 +6,11 @@ package common
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"strings"
-	"time"
-
-	"github.com/clumio-code/clumio-go-sdk/controllers/tasks"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 )
 
@@ -29,36 +27,6 @@ func SnakeCaseToCamelCase(key string) string {
 	return newKey
 }
 
-// PollTask polls created tasks to ensure that the resource
-// was created successfully.
-func PollTask(ctx context.Context, apiClient *ApiClient,
-	taskId string, timeoutInSec int64, intervalInSec int64) error {
-	t := tasks.NewTasksV1(apiClient.ClumioConfig)
-	interval := time.Duration(intervalInSec) * time.Second
-	ticker := time.NewTicker(interval)
-	timeout := time.After(time.Duration(timeoutInSec) * time.Second)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ticker.C:
-			resp, err := t.ReadTask(taskId)
-			if err != nil {
-				return err
-			} else if *resp.Status == TaskSuccess {
-				return nil
-			} else if *resp.Status == TaskAborted {
-				return errors.New("task aborted")
-			} else if *resp.Status == TaskFailed {
-				return errors.New("task failed")
-			}
-		case <-timeout:
-			return errors.New("polling task timeout")
-		}
-	}
-}
-
 // SliceDifferenceAttrValue returns the slice difference in attribute value slices.
 func SliceDifferenceAttrValue(slice1 []attr.Value, slice2 []attr.Value) []attr.Value {
 	var diff []attr.Value
@@ -130,3 +98,15 @@ func GetStringPtr(v basetypes.StringValue) *string {
 		return &s
 	}
 }
+
+// RemoveMissingResource removes the resource from the Terraform state and warns that the
+// object no longer exists in Clumio. Read calls it when the object was deleted outside of
+// Terraform so that the next plan proposes to re-create it instead of failing the refresh.
+func RemoveMissingResource(ctx context.Context, resp *resource.ReadResponse, object string) {
+	resp.State.RemoveResource(ctx)
+	resp.Diagnostics.AddWarning(
+		fmt.Sprintf("%s not found.", object),
+		fmt.Sprintf("The %s no longer exists in Clumio and may have been deleted outside of"+
+			" Terraform. It has been removed from the Terraform state and will be re-created"+
+			" on the next apply.", object))
+}
@@ -0,0 +1,102 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the functions used to validate the credentials of the provider against the
+// Clumio API.
+
+package common
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	orgUnits "github.com/clumio-code/clumio-go-sdk/controllers/organizational_units"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// ValidateCredentials makes a single authenticated call to the Clumio API to check that the
+// token is accepted by the API base URL and, if set, that the Organizational Unit context
+// exists and is accessible. The returned error describes the most likely cause of the
+// failure.
+func (c *ApiClient) ValidateCredentials(ctx context.Context) error {
+	baseUrl := c.config.BaseUrl
+	if c.relay != nil {
+		baseUrl = c.relay.target.String()
+	}
+	ouContext := c.config.OrganizationalUnitContext
+	orgUnitsAPI := orgUnits.NewOrganizationalUnitsV2(c.WithContext(ctx).ClumioConfig())
+
+	tflog.Debug(ctx, "Validating Clumio credentials", map[string]any{
+		"clumio_api_base_url":                baseUrl,
+		"clumio_organizational_unit_context": ouContext,
+	})
+	var statusCode int
+	var body []byte
+	if ouContext != "" {
+		_, apiErr := orgUnitsAPI.ReadOrganizationalUnit(ouContext, nil)
+		if apiErr == nil {
+			return nil
+		}
+		statusCode, body = apiErr.ResponseCode, apiErr.Response
+	} else {
+		limit := int64(1)
+		_, apiErr := orgUnitsAPI.ListOrganizationalUnits(&limit, nil, nil)
+		if apiErr == nil {
+			return nil
+		}
+		statusCode, body = apiErr.ResponseCode, apiErr.Response
+	}
+
+	switch {
+	case statusCode == http.StatusUnauthorized:
+		if expiry, ok := tokenExpiry(c.config.Token); ok && time.Now().After(expiry) {
+			return fmt.Errorf("the Clumio API token expired at %s. Create a new API token"+
+				" and update the provider configuration", expiry.Format(time.RFC3339))
+		}
+		return fmt.Errorf("the Clumio API token was rejected by %s. The token may have been"+
+			" revoked, or it belongs to a different region than the one of the API base"+
+			" URL. Check clumio_api_base_url or clumio_region. Response: %s", baseUrl,
+			string(body))
+	case ouContext != "" && (statusCode == http.StatusNotFound ||
+		statusCode == http.StatusForbidden):
+		return fmt.Errorf("the Organizational Unit context %q was not found or is not"+
+			" accessible with the Clumio API token. Check clumio_organizational_unit_context."+
+			" Response: %s", ouContext, string(body))
+	case statusCode == http.StatusForbidden:
+		return fmt.Errorf("the Clumio API token is not allowed to access %s. Response: %s",
+			baseUrl, string(body))
+	case statusCode == http.StatusBadGateway:
+		return fmt.Errorf("unable to reach the Clumio API at %s. Response: %s", baseUrl,
+			string(body))
+	}
+	return fmt.Errorf("unexpected response from the Clumio API at %s (HTTP %d): %s",
+		baseUrl, statusCode, string(body))
+}
+
+// tokenExpiry returns the expiry time recorded in the token if the token is a JWT with an
+// exp claim. The signature of the token is not verified.
+func tokenExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp *json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == nil {
+		return time.Time{}, false
+	}
+	seconds, err := claims.Exp.Int64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(seconds, 0), true
+}
@@ -0,0 +1,187 @@
+// Copyright 2024. Clumio, Inc.
+
+// Unit tests of the validation of the credentials against the Clumio API.
+package common
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	clumioConfig "github.com/clumio-code/clumio-go-sdk/config"
+)
+
+// testJwt returns an unsigned JWT whose exp claim is the given time.
+func testJwt(exp time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString(
+		[]byte(fmt.Sprintf(`{"sub": "user-1", "exp": %d}`, exp.Unix())))
+	return "eyJhbGciOiJub25lIn0." + payload + ".signature"
+}
+
+func TestValidateCredentials(t *testing.T) {
+	expired := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for name, test := range map[string]struct {
+		token     string
+		ouContext string
+		status    int
+		body      string
+		wantPath  string
+		// wantErr is a part of the expected error, if any.
+		wantErr string
+	}{
+		"valid token": {
+			status:   http.StatusOK,
+			body:     `{"current_count": 0, "limit": 1, "start": "1"}`,
+			wantPath: "/organizational-units",
+		},
+		"valid Organizational Unit context": {
+			ouContext: "ou-1",
+			status:    http.StatusOK,
+			body:      `{"id": "ou-1", "name": "Finance"}`,
+			wantPath:  "/organizational-units/ou-1",
+		},
+		"rejected token": {
+			status:   http.StatusUnauthorized,
+			body:     `{"errors": [{"error_message": "Invalid token."}]}`,
+			wantPath: "/organizational-units",
+			wantErr: "the Clumio API token was rejected by %s. The token may have been" +
+				" revoked",
+		},
+		"expired token": {
+			token:    testJwt(expired),
+			status:   http.StatusUnauthorized,
+			wantPath: "/organizational-units",
+			wantErr: "the Clumio API token expired at " +
+				expired.Format(time.RFC3339),
+		},
+		"missing Organizational Unit context": {
+			ouContext: "ou-1",
+			status:    http.StatusNotFound,
+			body:      `{"errors": [{"error_message": "Not found."}]}`,
+			wantPath:  "/organizational-units/ou-1",
+			wantErr: `the Organizational Unit context "ou-1" was not found or is not` +
+				" accessible",
+		},
+		"forbidden": {
+			status:   http.StatusForbidden,
+			wantPath: "/organizational-units",
+			wantErr:  "the Clumio API token is not allowed to access %s",
+		},
+		"unexpected response": {
+			status:   http.StatusTeapot,
+			body:     "teapot",
+			wantPath: "/organizational-units",
+			wantErr:  "unexpected response from the Clumio API at %s (HTTP 418): teapot",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var paths, authorizations []string
+			server := httptest.NewServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					paths = append(paths, r.URL.Path)
+					authorizations = append(authorizations, r.Header.Get("Authorization"))
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(test.status)
+					w.Write([]byte(test.body))
+				}))
+			defer server.Close()
+			token := test.token
+			if token == "" {
+				token = "api-token"
+			}
+			client, err := NewApiClient(context.Background(), clumioConfig.Config{
+				Token:                     token,
+				BaseUrl:                   server.URL,
+				OrganizationalUnitContext: test.ouContext,
+			}, ApiClientOptions{Retry: RetryConfig{MaxBackoff: time.Millisecond}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+
+			err = client.ValidateCredentials(context.Background())
+			if strings.Join(paths, " ") != test.wantPath {
+				t.Errorf("got the requests %v, want one to %s", paths, test.wantPath)
+			}
+			if strings.Join(authorizations, " ") != "Bearer "+token {
+				t.Errorf("got the credentials %v, want Bearer %s", authorizations, token)
+			}
+			if test.wantErr == "" {
+				if err != nil {
+					t.Errorf("got the error %v, want none", err)
+				}
+				return
+			}
+			wantErr := test.wantErr
+			if strings.Contains(wantErr, "%s") {
+				wantErr = fmt.Sprintf(wantErr, server.URL)
+			}
+			if err == nil || !strings.Contains(err.Error(), wantErr) {
+				t.Errorf("got the error %v, want %q", err, wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCredentialsUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	baseUrl := server.URL
+	server.Close()
+	client, err := NewApiClient(context.Background(), clumioConfig.Config{
+		Token:   "api-token",
+		BaseUrl: baseUrl,
+	}, ApiClientOptions{Retry: RetryConfig{MaxBackoff: time.Millisecond}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	err = client.ValidateCredentials(context.Background())
+	want := "unable to reach the Clumio API at " + baseUrl
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("got the error %v, want %q", err, want)
+	}
+}
+
+func TestTokenExpiry(t *testing.T) {
+	exp := time.Unix(1700000000, 0)
+	for name, test := range map[string]struct {
+		token  string
+		want   time.Time
+		wantOk bool
+	}{
+		"JWT": {
+			token:  testJwt(exp),
+			want:   exp,
+			wantOk: true,
+		},
+		"padded JWT": {
+			token: "eyJhbGciOiJub25lIn0." +
+				base64.URLEncoding.EncodeToString([]byte(`{"exp": 1700000000}`)) + ".sig",
+			want:   exp,
+			wantOk: true,
+		},
+		"JWT without exp": {
+			token: "eyJhbGciOiJub25lIn0." +
+				base64.RawURLEncoding.EncodeToString([]byte(`{"sub": "user-1"}`)) + ".sig",
+		},
+		"not a JWT": {
+			token: "api-token",
+		},
+		"invalid payload": {
+			token: "header.!!!.signature",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, ok := tokenExpiry(test.token)
+			if ok != test.wantOk || !got.Equal(test.want) {
+				t.Errorf("got %v and %t, want %v and %t", got, ok, test.want, test.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the HTTP wire logging of the requests sent to the Clumio API.
+
+package common
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// WireLogSubsystem is the tflog subsystem of the Clumio API wire log. Its level follows
+	// TF_LOG_PROVIDER_CLUMIO_API, or the provider level if that variable is not set. Every
+	// request is logged at the debug level, and its headers and bodies at the trace level.
+	WireLogSubsystem = "api"
+
+	// wireLogLevelEnv is the prefix of the environment variable controlling the level of
+	// the wire log subsystem.
+	wireLogLevelEnv = "TF_LOG_PROVIDER_CLUMIO"
+
+	// maxLoggedBodySize bounds the size of the request and response bodies written to the
+	// wire log.
+	maxLoggedBodySize = 64 * 1024
+
+	// redactedValue replaces sensitive values in the wire log.
+	redactedValue = "***"
+)
+
+var (
+	// redactedBodyKeys are the JSON keys whose values are masked in the logged bodies, at
+	// any depth. They hold the Clumio API token, the external ID of the AWS roles and the
+	// tokens of the wallets and the AWS connections.
+	redactedBodyKeys = map[string]bool{
+		"token":            true,
+		"clumio_token":     true,
+		"role_external_id": true,
+	}
+
+	// redactedBodyPattern matches the string values of the sensitive keys in a body that is
+	// not valid JSON. It must cover the same keys as redactedBodyKeys.
+	redactedBodyPattern = regexp.MustCompile(
+		`"(token|clumio_token|role_external_id)"\s*:\s*"(?:[^"\\]|\\.)*"`)
+
+	// redactedHeaders are the request headers whose values are masked in the wire log.
+	redactedHeaders = map[string]bool{
+		"Authorization": true,
+	}
+
+	// requestIdHeaders are the response headers that may carry the ID assigned to the
+	// request by the Clumio API, in order of preference.
+	requestIdHeaders = []string{
+		"X-Clumio-Request-Id",
+		"X-Request-Id",
+		"X-Amzn-Requestid",
+	}
+)
+
+// newWireLogContext returns a context with the wire log subsystem set up. Every occurrence
+// of the API token is masked, in addition to the redaction applied by wireLogTransport.
+func newWireLogContext(ctx context.Context, apiToken string) context.Context {
+	ctx = tflog.NewSubsystem(ctx, WireLogSubsystem,
+		tflog.WithLevelFromEnv(wireLogLevelEnv, WireLogSubsystem))
+	if apiToken != "" {
+		ctx = tflog.SubsystemMaskAllFieldValuesStrings(ctx, WireLogSubsystem, apiToken)
+		ctx = tflog.SubsystemMaskMessageStrings(ctx, WireLogSubsystem, apiToken)
+	}
+	return ctx
+}
+
+// wireLogTraceEnabled reports whether the wire log subsystem logs at the trace level. tflog
+// offers no way to query the level of a logger, so it is derived from the same environment
+// variables, from the most to the least specific. When none is set, Terraform discards the
+// logs of the provider.
+func wireLogTraceEnabled() bool {
+	for _, env := range []string{
+		wireLogLevelEnv + "_" + strings.ToUpper(WireLogSubsystem),
+		wireLogLevelEnv,
+		"TF_LOG_PROVIDER",
+		"TF_LOG",
+	} {
+		if level := os.Getenv(env); level != "" {
+			// JSON is the trace level with JSON formatted logs.
+			return strings.EqualFold(level, "TRACE") || strings.EqualFold(level, "JSON")
+		}
+	}
+	return false
+}
+
+// wireLogTransport is an http.RoundTripper that writes every request sent to the Clumio API
+// and its response to the wire log subsystem with the sensitive values masked. The headers
+// and bodies are only read and logged if trace is set, as buffering and redacting them is
+// wasted work otherwise.
+type wireLogTransport struct {
+	next  http.RoundTripper
+	trace bool
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *wireLogTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.trace {
+		return t.roundTripSummary(req)
+	}
+	ctx := req.Context()
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+	fields := map[string]any{
+		"method":          req.Method,
+		"url":             req.URL.String(),
+		"request_headers": redactHeaders(req.Header),
+		"request_body":    redactBody(reqBody),
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	fields["latency"] = time.Since(start).String()
+	if err != nil {
+		fields["error"] = err.Error()
+		tflog.SubsystemTrace(ctx, WireLogSubsystem, "Clumio API request failed", fields)
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	fields["status"] = resp.StatusCode
+	setRequestId(fields, resp.Header)
+	if err != nil {
+		// The response is not returned with the part of its body read before the failure.
+		fields["error"] = err.Error()
+		tflog.SubsystemTrace(ctx, WireLogSubsystem, "Clumio API request failed", fields)
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	fields["response_body"] = redactBody(decodeBody(respBody, resp.Header))
+	tflog.SubsystemTrace(ctx, WireLogSubsystem, "Clumio API request", fields)
+	return resp, nil
+}
+
+// roundTripSummary sends the request and logs it without its headers and bodies.
+func (t *wireLogTransport) roundTripSummary(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	fields := map[string]any{
+		"method": req.Method,
+		"url":    req.URL.String(),
+	}
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	fields["latency"] = time.Since(start).String()
+	if err != nil {
+		fields["error"] = err.Error()
+		tflog.SubsystemDebug(ctx, WireLogSubsystem, "Clumio API request failed", fields)
+		return nil, err
+	}
+	fields["status"] = resp.StatusCode
+	setRequestId(fields, resp.Header)
+	tflog.SubsystemDebug(ctx, WireLogSubsystem, "Clumio API request", fields)
+	return resp, nil
+}
+
+// setRequestId sets the ID assigned to the request by the Clumio API in the logged fields,
+// if the response carries one.
+func setRequestId(fields map[string]any, header http.Header) {
+	for _, name := range requestIdHeaders {
+		if requestId := header.Get(name); requestId != "" {
+			fields["request_id"] = requestId
+			return
+		}
+	}
+}
+
+// decodeBody returns the body decompressed according to its Content-Encoding header. The
+// body is returned unchanged if it cannot be decompressed.
+func decodeBody(body []byte, header http.Header) []byte {
+	if len(body) == 0 || header.Get("Content-Encoding") != "gzip" {
+		return body
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return body
+	}
+	defer reader.Close()
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return body
+	}
+	return decoded
+}
+
+// redactHeaders returns the headers in a loggable form with the sensitive values masked.
+func redactHeaders(header http.Header) map[string]string {
+	redacted := make(map[string]string, len(header))
+	for key, values := range header {
+		if redactedHeaders[http.CanonicalHeaderKey(key)] {
+			redacted[key] = redactedValue
+			continue
+		}
+		if len(values) > 0 {
+			redacted[key] = values[0]
+		}
+	}
+	return redacted
+}
+
+// redactBody returns the body in a loggable form with the values of the sensitive keys
+// masked. A body that cannot be decoded as JSON is masked with a regular expression.
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var document any
+	redacted := false
+	if err := json.Unmarshal(body, &document); err == nil {
+		if encoded, err := json.Marshal(redactValue(document)); err == nil {
+			body, redacted = encoded, true
+		}
+	}
+	if !redacted {
+		body = redactedBodyPattern.ReplaceAll(body, []byte(`"$1":"`+redactedValue+`"`))
+	}
+	if len(body) > maxLoggedBodySize {
+		return string(body[:maxLoggedBodySize]) + "...(truncated)"
+	}
+	return string(body)
+}
+
+// redactValue masks the values of the sensitive keys in a decoded JSON document.
+func redactValue(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, nested := range typed {
+			if redactedBodyKeys[key] {
+				if nested != nil {
+					typed[key] = redactedValue
+				}
+				continue
+			}
+			typed[key] = redactValue(nested)
+		}
+	case []any:
+		for i, nested := range typed {
+			typed[i] = redactValue(nested)
+		}
+	}
+	return value
+}
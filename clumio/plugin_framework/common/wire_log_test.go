@@ -0,0 +1,286 @@
+// Copyright 2024. Clumio, Inc.
+
+// Unit tests of the wire log of the requests sent to the Clumio API.
+package common
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+// stubTransport returns the same response to every request and keeps the last request.
+type stubTransport struct {
+	header http.Header
+	body   string
+	last   *http.Request
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.last = req
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     t.header,
+		Body:       io.NopCloser(strings.NewReader(t.body)),
+		Request:    req,
+	}, nil
+}
+
+func TestRedactBody(t *testing.T) {
+	for name, test := range map[string]struct {
+		body string
+		want string
+	}{
+		"empty": {
+			body: "",
+			want: "",
+		},
+		"token": {
+			body: `{"token":"secret","name":"wallet"}`,
+			want: `{"name":"wallet","token":"***"}`,
+		},
+		"nested clumio_token": {
+			body: `{"_embedded":{"items":[{"clumio_token":"secret","id":"1"}]}}`,
+			want: `{"_embedded":{"items":[{"clumio_token":"***","id":"1"}]}}`,
+		},
+		"role_external_id": {
+			body: `{"role_external_id":"secret","account_native_id":"123"}`,
+			want: `{"account_native_id":"123","role_external_id":"***"}`,
+		},
+		"null values are kept": {
+			body: `{"token":null}`,
+			want: `{"token":null}`,
+		},
+		"other keys are kept": {
+			body: `{"token_type":"bearer","tokens":["a"]}`,
+			want: `{"token_type":"bearer","tokens":["a"]}`,
+		},
+		"not JSON": {
+			body: `{"token": "sec\"ret", "clumio_token":"secret", "role_external_id" :"secret",`,
+			want: `{"token":"***", "clumio_token":"***", "role_external_id":"***",`,
+		},
+		"not JSON without secrets": {
+			body: "Bad Gateway",
+			want: "Bad Gateway",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := redactBody([]byte(test.body)); got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRedactBodyTruncates(t *testing.T) {
+	body := strings.Repeat("a", maxLoggedBodySize+1)
+	want := strings.Repeat("a", maxLoggedBodySize) + "...(truncated)"
+	if got := redactBody([]byte(body)); got != want {
+		t.Errorf("got a body of %d bytes, want %d", len(got), len(want))
+	}
+}
+
+func TestRedactHeaders(t *testing.T) {
+	for name, test := range map[string]struct {
+		header http.Header
+		want   map[string]string
+	}{
+		"authorization": {
+			header: http.Header{
+				"Authorization": {"Bearer secret"},
+				"Accept":        {"application/json"},
+			},
+			want: map[string]string{
+				"Authorization": redactedValue,
+				"Accept":        "application/json",
+			},
+		},
+		"non canonical authorization": {
+			header: http.Header{"authorization": {"Bearer secret"}},
+			want:   map[string]string{"authorization": redactedValue},
+		},
+		"first value": {
+			header: http.Header{"Accept": {"a", "b"}, "Empty": {}},
+			want:   map[string]string{"Accept": "a"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := redactHeaders(test.header); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWireLogTraceEnabled(t *testing.T) {
+	for name, test := range map[string]struct {
+		env  map[string]string
+		want bool
+	}{
+		"unset": {
+			want: false,
+		},
+		"subsystem trace": {
+			env:  map[string]string{"TF_LOG_PROVIDER_CLUMIO_API": "trace"},
+			want: true,
+		},
+		"subsystem over provider": {
+			env: map[string]string{
+				"TF_LOG_PROVIDER_CLUMIO_API": "DEBUG",
+				"TF_LOG_PROVIDER_CLUMIO":     "TRACE",
+			},
+			want: false,
+		},
+		"provider trace": {
+			env:  map[string]string{"TF_LOG_PROVIDER_CLUMIO": "TRACE"},
+			want: true,
+		},
+		"all providers trace": {
+			env:  map[string]string{"TF_LOG_PROVIDER": "TRACE"},
+			want: true,
+		},
+		"everything debug": {
+			env:  map[string]string{"TF_LOG": "DEBUG"},
+			want: false,
+		},
+		"everything json": {
+			env:  map[string]string{"TF_LOG": "JSON"},
+			want: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			for _, env := range []string{"TF_LOG_PROVIDER_CLUMIO_API", "TF_LOG_PROVIDER_CLUMIO",
+				"TF_LOG_PROVIDER", "TF_LOG"} {
+				t.Setenv(env, test.env[env])
+			}
+			if got := wireLogTraceEnabled(); got != test.want {
+				t.Errorf("got %t, want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWireLogTransportTrace(t *testing.T) {
+	t.Setenv("TF_LOG_PROVIDER_CLUMIO_API", "TRACE")
+	var output bytes.Buffer
+	ctx := newWireLogContext(tflogtest.RootLogger(context.Background(), &output), "api-token")
+	next := &stubTransport{
+		header: http.Header{"X-Clumio-Request-Id": {"request-1"}},
+		body:   `{"id":"wallet-1","token":"wallet-token"}`,
+	}
+	transport := &wireLogTransport{next: next, trace: true}
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.example.com/wallets",
+		strings.NewReader(`{"role_external_id":"external-id"}`))
+	req.Header.Set("Authorization", "Bearer api-token")
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The bodies are still readable after being logged.
+	sent, _ := io.ReadAll(next.last.Body)
+	received, _ := io.ReadAll(resp.Body)
+	if string(sent) != `{"role_external_id":"external-id"}` ||
+		string(received) != `{"id":"wallet-1","token":"wallet-token"}` {
+		t.Errorf("got request body %s and response body %s, want them unchanged", sent, received)
+	}
+	logged := output.String()
+	for _, secret := range []string{"api-token", "wallet-token", "external-id"} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("got %q in the wire log: %s", secret, logged)
+		}
+	}
+	for _, want := range []string{"wallet-1", "request-1", `"@level":"trace"`} {
+		if !strings.Contains(logged, want) {
+			t.Errorf("got no %q in the wire log: %s", want, logged)
+		}
+	}
+}
+
+// failingBody is a response body whose reads fail, and which records whether it was closed.
+type failingBody struct {
+	closed bool
+}
+
+// Read implements io.Reader.
+func (b *failingBody) Read(_ []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+// Close implements io.Closer.
+func (b *failingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+// failingBodyTransport returns a response whose body cannot be read.
+type failingBodyTransport struct {
+	body *failingBody
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *failingBodyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       t.body,
+		Request:    req,
+	}, nil
+}
+
+func TestWireLogTransportTraceBodyReadError(t *testing.T) {
+	t.Setenv("TF_LOG_PROVIDER_CLUMIO_API", "TRACE")
+	var output bytes.Buffer
+	ctx := newWireLogContext(tflogtest.RootLogger(context.Background(), &output), "")
+	next := &failingBodyTransport{body: &failingBody{}}
+	transport := &wireLogTransport{next: next, trace: true}
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.example.com/tasks",
+		nil)
+	resp, err := transport.RoundTrip(req)
+	if resp != nil || err == nil {
+		t.Errorf("got response %v and error %v, want only an error", resp, err)
+	}
+	if !next.body.closed {
+		t.Errorf("got the response body left open")
+	}
+	if !strings.Contains(output.String(), "connection reset") {
+		t.Errorf("got no read error in the wire log: %s", output.String())
+	}
+}
+
+func TestWireLogTransportSummary(t *testing.T) {
+	t.Setenv("TF_LOG_PROVIDER_CLUMIO_API", "TRACE")
+	var output bytes.Buffer
+	ctx := newWireLogContext(tflogtest.RootLogger(context.Background(), &output), "")
+	next := &stubTransport{body: `{"token":"wallet-token"}`}
+	transport := &wireLogTransport{next: next}
+
+	body := io.NopCloser(strings.NewReader(`{"role_external_id":"external-id"}`))
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.example.com/wallets",
+		body)
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+
+	// Below the trace level, the request is forwarded without buffering its body.
+	if next.last.Body != body {
+		t.Errorf("got the request body replaced, want it forwarded as is")
+	}
+	logged := output.String()
+	if strings.Contains(logged, "wallet-token") || strings.Contains(logged, "external-id") {
+		t.Errorf("got a body in the wire log: %s", logged)
+	}
+	if !strings.Contains(logged, `"@level":"debug"`) || !strings.Contains(logged, "/wallets") {
+		t.Errorf("got no summary of the request in the wire log: %s", logged)
+	}
+}
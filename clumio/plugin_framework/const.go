@@ -16,8 +16,31 @@ const (
 		" there is an unknown configuration value for the Clumio API %s. " +
 		"Either target apply the source of the value first, set the value" +
 		" statically in the configuration, or use the %s environment variable."
-	baseUrl = "Base URL"
-	token   = "Token"
+	errorGenericFmt = "Error: %v"
+	baseUrl         = "Base URL"
+	token           = "Token"
+	profile         = "profile"
+	region          = "region"
+	tokenCommand    = "token command"
+
+	unknownBaseUrlFmt = "The Clumio API Base URL %s is not the endpoint of a known Clumio" +
+		" region (%s). Requests will still be sent to it. Use clumio_region to select a" +
+		" known region, or set allow_custom_api_base_url to true if the endpoint is intended."
+
+	insecureSkipVerifyWarning = "insecure_skip_verify is set: the TLS certificate of the" +
+		" Clumio API is not verified and the API token can be intercepted by anyone able" +
+		" to tamper with the connection. Use ca_bundle_file to trust a private CA instead" +
+		" and only disable the verification for troubleshooting."
+
+	// Sources reported in the logs for the provider settings.
+	sourceConfig     = "provider configuration"
+	sourceEnvFmt     = "environment variable %s"
+	sourceProfileFmt = "profile %q"
+	sourceRegion     = "provider configuration (clumio_region)"
+	sourceNone       = "not set"
+	// sourceTokenCommandFmt describes a token obtained from the token command set in the
+	// given source.
+	sourceTokenCommandFmt = "token_command (%s)"
 )
 
 var userAgentHeaderValue = fmt.Sprintf("Clumio-Terraform-Provider-%s", clumioTfProviderVersionValue)
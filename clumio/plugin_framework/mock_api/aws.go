@@ -0,0 +1,232 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the handlers of the AWS connections, AWS environments, AWS templates and AWS
+// connection post-processing APIs.
+
+package mock_api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// clumioAwsAccountId is the ID of the AWS account of Clumio returned by the server.
+	clumioAwsAccountId = "999999999999"
+
+	connectionStatusConnecting = "connecting"
+	connectionStatusConnected  = "connected"
+)
+
+// awsRoutes are the routes of the AWS connections, AWS environments, AWS templates and AWS
+// connection post-processing APIs.
+var awsRoutes = []route{
+	{http.MethodPost, "/connections/aws", createAwsConnection},
+	{http.MethodGet, "/connections/aws", listAwsConnections},
+	{http.MethodGet, "/connections/aws/{connection_id}", readAwsConnection},
+	{http.MethodPatch, "/connections/aws/{connection_id}", updateAwsConnection},
+	{http.MethodDelete, "/connections/aws/{connection_id}", deleteAwsConnection},
+	{http.MethodPost, "/connections/aws/templates", createAwsTemplate},
+	{http.MethodPost, "/connections/aws/post-process", postProcessAwsConnection},
+	{http.MethodGet, "/datasources/aws/environments", listAwsEnvironments},
+}
+
+// connectionOrganizationalUnit returns the organizational unit of an AWS connection or
+// environment.
+func connectionOrganizationalUnit(obj object) string {
+	return stringField(obj, "organizational_unit_id")
+}
+
+// connectionId returns the ID of the AWS connection of the AWS account and region.
+func connectionId(accountNativeId, awsRegion string) string {
+	return accountNativeId + "_" + awsRegion
+}
+
+// createAwsConnection handles POST /connections/aws. The AWS environment of the connection is
+// created along with it.
+func createAwsConnection(s *Server, r *request) (int, any) {
+	accountNativeId := stringField(r.body, "account_native_id")
+	awsRegion := stringField(r.body, "aws_region")
+	if accountNativeId == "" || awsRegion == "" {
+		return badRequest("The account_native_id and aws_region of the connection are required.")
+	}
+	id := connectionId(accountNativeId, awsRegion)
+	if _, ok := s.connections[id]; ok {
+		return apiError(http.StatusConflict,
+			"A connection already exists for the AWS account %s in %s.", accountNativeId,
+			awsRegion)
+	}
+	ouId := stringField(r.body, "organizational_unit_id")
+	if ouId == "" {
+		ouId = contextOrganizationalUnit(r)
+	}
+	if ok, status, body := s.checkOrganizationalUnit(r, ouId); !ok {
+		return status, body
+	}
+	token := uuid.NewString()
+	connection := object{
+		"id":                     id,
+		"account_native_id":      accountNativeId,
+		"aws_region":             awsRegion,
+		"token":                  token,
+		"namespace":              "clumio",
+		"clumio_aws_account_id":  clumioAwsAccountId,
+		"clumio_aws_region":      awsRegion,
+		"organizational_unit_id": ouId,
+		"connection_status":      connectionStatusConnecting,
+		"external_id":            "ExternalID_" + token,
+		"created_timestamp":      timestamp(time.Now()),
+	}
+	if description := stringField(r.body, "description"); description != "" {
+		connection["description"] = description
+	}
+	s.connections[id] = connection
+	environmentId := uuid.NewString()
+	s.environments[environmentId] = object{
+		"id":                     environmentId,
+		"account_native_id":      accountNativeId,
+		"aws_region":             awsRegion,
+		"connection_id":          id,
+		"connection_status":      connectionStatusConnecting,
+		"organizational_unit_id": ouId,
+		"services_enabled":       []any{},
+	}
+	return http.StatusOK, copyObject(connection)
+}
+
+// listAwsConnections handles GET /connections/aws.
+func listAwsConnections(s *Server, r *request) (int, any) {
+	return s.listObjects(r, s.connections, connectionOrganizationalUnit)
+}
+
+// findAwsConnection returns the AWS connection of the connection_id path parameter if it is
+// visible to the request.
+func (s *Server) findAwsConnection(r *request) (object, bool) {
+	connection, ok := s.connections[r.params["connection_id"]]
+	if !ok || !s.visible(r, connectionOrganizationalUnit(connection)) {
+		return nil, false
+	}
+	return connection, true
+}
+
+// readAwsConnection handles GET /connections/aws/{connection_id}.
+func readAwsConnection(s *Server, r *request) (int, any) {
+	connection, ok := s.findAwsConnection(r)
+	if !ok {
+		return notFound("AWS connection", r.params["connection_id"])
+	}
+	res := copyObject(connection)
+	if r.URL.Query().Get("return_external_id") != "true" {
+		delete(res, "external_id")
+	}
+	return http.StatusOK, res
+}
+
+// updateAwsConnection handles PATCH /connections/aws/{connection_id}. Setting the resources of
+// a manual connection connects it.
+func updateAwsConnection(s *Server, r *request) (int, any) {
+	connection, ok := s.findAwsConnection(r)
+	if !ok {
+		return notFound("AWS connection", r.params["connection_id"])
+	}
+	mergeFields(connection, r.body, "description", "asset_types_enabled", "resources")
+	if r.body["resources"] != nil {
+		s.setConnectionStatus(connection, connectionStatusConnected)
+	}
+	return http.StatusOK, copyObject(connection)
+}
+
+// deleteAwsConnection handles DELETE /connections/aws/{connection_id}.
+func deleteAwsConnection(s *Server, r *request) (int, any) {
+	connection, ok := s.findAwsConnection(r)
+	if !ok {
+		return notFound("AWS connection", r.params["connection_id"])
+	}
+	id := stringField(connection, "id")
+	delete(s.connections, id)
+	for environmentId, environment := range s.environments {
+		if stringField(environment, "connection_id") == id {
+			delete(s.environments, environmentId)
+		}
+	}
+	return http.StatusOK, object{}
+}
+
+// setConnectionStatus sets the status of the AWS connection and of its environment.
+func (s *Server) setConnectionStatus(connection object, status string) {
+	connection["connection_status"] = status
+	for _, environment := range s.environments {
+		if stringField(environment, "connection_id") == stringField(connection, "id") {
+			environment["connection_status"] = status
+		}
+	}
+}
+
+// listAwsEnvironments handles GET /datasources/aws/environments.
+func listAwsEnvironments(s *Server, r *request) (int, any) {
+	return s.listObjects(r, s.environments, connectionOrganizationalUnit)
+}
+
+// createAwsTemplate handles POST /connections/aws/templates. The resources returned for the
+// manual connections are a fixed sample.
+func createAwsTemplate(_ *Server, r *request) (int, any) {
+	accountId := stringField(r.body, "aws_account_id")
+	awsRegion := stringField(r.body, "aws_region")
+	if accountId == "" || awsRegion == "" {
+		return badRequest("The aws_account_id and aws_region of the template are required.")
+	}
+	res := object{
+		"cloudformation_url": "https://clumio-templates.s3.amazonaws.com/clumio.yaml",
+		"terraform_url":      "https://registry.terraform.io/modules/clumio-code/aws-template",
+	}
+	if showManual, _ := r.body["show_manual_resources"].(bool); showManual {
+		res["resources"] = object{
+			"roles": object{
+				"clumio_iam_role": object{
+					"description": "The role assumed by Clumio in the AWS account " +
+						accountId + ".",
+					"steps": "Create the role with the trust policy below.",
+					"trust_policy": object{
+						"Version": "2012-10-17",
+						"Statement": []object{{
+							"Effect":    "Allow",
+							"Principal": object{"AWS": "arn:aws:iam::" + clumioAwsAccountId + ":root"},
+							"Action":    "sts:AssumeRole",
+						}},
+					},
+				},
+			},
+			"policies":      object{},
+			"rules":         object{},
+			"ssm_documents": object{},
+			"topics":        object{},
+		}
+	}
+	return http.StatusOK, res
+}
+
+// postProcessAwsConnection handles POST /connections/aws/post-process, which is called once
+// the Clumio template is installed in the AWS account. It connects the AWS connection, or
+// disconnects it when the template is removed.
+func postProcessAwsConnection(s *Server, r *request) (int, any) {
+	id := connectionId(stringField(r.body, "account_native_id"),
+		stringField(r.body, "aws_region"))
+	connection, ok := s.connections[id]
+	if !ok || !s.visible(r, connectionOrganizationalUnit(connection)) {
+		return notFound("AWS connection", id)
+	}
+	if stringField(r.body, "token") != stringField(connection, "token") {
+		return badRequest("The token does not match the token of the AWS connection %s.", id)
+	}
+	switch requestType := stringField(r.body, "request_type"); requestType {
+	case "Create", "Update":
+		s.setConnectionStatus(connection, connectionStatusConnected)
+	case "Delete":
+		s.setConnectionStatus(connection, connectionStatusConnecting)
+	default:
+		return badRequest("Unsupported request_type %q.", requestType)
+	}
+	return http.StatusOK, object{}
+}
@@ -0,0 +1,118 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the faults injected in the server to test how the provider handles the errors of
+// the API.
+
+package mock_api
+
+import (
+	"net/http"
+)
+
+// Fault makes the server fail the requests it matches instead of handling them.
+type Fault struct {
+	// Method is the HTTP method of the requests to fail. An empty method matches all of them.
+	Method string
+	// Path is the path of the requests to fail, such as /policies/definitions/{policy_id}.
+	// The segments between braces match any value. An empty path matches all the requests.
+	Path string
+	// StatusCode is the status code of the response.
+	StatusCode int
+	// Message is the error message of the response. It defaults to the text of the status
+	// code.
+	Message string
+	// Header holds extra headers of the response, such as Retry-After.
+	Header map[string]string
+	// Times is the number of requests to fail. Zero fails all of them until ClearFaults is
+	// called. Note that the provider retries the requests failed with a 429 or 5xx status.
+	Times int
+}
+
+// TaskFault makes the tasks started by the server end with a failure. The effects of a failed
+// task, such as the assignment of a policy, are not applied.
+type TaskFault struct {
+	// Type is the type of the tasks to fail, such as policy_update. An empty type matches all
+	// of them.
+	Type string
+	// Status is the final status of the tasks, failed or aborted. It defaults to failed.
+	Status string
+	// Reason is the error message reported for the tasks.
+	Reason string
+	// Times is the number of tasks to fail. Zero fails all of them until ClearFaults is
+	// called.
+	Times int
+}
+
+// InjectFault makes the server fail the requests matched by the fault. The faults are matched
+// in the order they were injected.
+func (s *Server) InjectFault(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault)
+}
+
+// InjectTaskFault makes the server fail the tasks matched by the fault when they are started.
+func (s *Server) InjectTaskFault(fault TaskFault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.taskFaults = append(s.taskFaults, &fault)
+}
+
+// ClearFaults removes all the faults injected in the server.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+	s.taskFaults = nil
+}
+
+// matchFault returns the fault matching the request, or nil if there is none. A fault that
+// has failed the number of requests it was given is removed.
+func (s *Server) matchFault(method, path string) *Fault {
+	for i, fault := range s.faults {
+		if fault.Method != "" && fault.Method != method {
+			continue
+		}
+		if fault.Path != "" {
+			if _, ok := matchPath(fault.Path, path); !ok {
+				continue
+			}
+		}
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return fault
+	}
+	return nil
+}
+
+// matchTaskFault returns the fault of a task of the given type, or nil if there is none.
+func (s *Server) matchTaskFault(taskType string) *TaskFault {
+	for i, fault := range s.taskFaults {
+		if fault.Type != "" && fault.Type != taskType {
+			continue
+		}
+		matched := *fault
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				s.taskFaults = append(s.taskFaults[:i:i], s.taskFaults[i+1:]...)
+			}
+		}
+		return &matched
+	}
+	return nil
+}
+
+// response returns the status code and the body of the response of a request failed by the
+// fault.
+func (f *Fault) response() (int, any) {
+	message := f.Message
+	if message == "" {
+		message = http.StatusText(f.StatusCode)
+	}
+	return apiError(f.StatusCode, "%s", message)
+}
@@ -0,0 +1,259 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the handlers of the organizational units API, in its V1 and V2 versions.
+
+package mock_api
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+const entityTypeAwsEnvironment = "aws_environment"
+
+// organizationalUnitRoutes are the routes of the organizational units API.
+var organizationalUnitRoutes = []route{
+	{http.MethodGet, "/organizational-units", listOrganizationalUnits},
+	{http.MethodPost, "/organizational-units", createOrganizationalUnit},
+	{http.MethodGet, "/organizational-units/{id}", readOrganizationalUnit},
+	{http.MethodPatch, "/organizational-units/{id}", patchOrganizationalUnit},
+	{http.MethodDelete, "/organizational-units/{id}", deleteOrganizationalUnit},
+}
+
+// ouRepresentation returns the representation of the organizational unit in the given version
+// of the API. Its counts, descendants and users are derived from the other objects of the
+// server: the users are listed with their role in V2, and by ID only in V1. As they are not
+// fields of the organizational unit itself, their changes do not change its ETag. Whether the
+// Clumio API changes it with them is not known, so the mock does not model it.
+func (s *Server) ouRepresentation(ou object, version int) object {
+	res := copyObject(ou)
+	id := stringField(ou, "id")
+	childrenCount := int64(0)
+	descendantIds := []string{}
+	for otherId, other := range s.organizationalUnits {
+		if otherId == id {
+			continue
+		}
+		if stringField(other, "parent_id") == id {
+			childrenCount++
+		}
+		if s.isDescendant(otherId, id) {
+			descendantIds = append(descendantIds, otherId)
+		}
+	}
+	sort.Strings(descendantIds)
+	res["children_count"] = childrenCount
+	res["descendant_ids"] = toAnySlice(descendantIds)
+
+	var userIds []string
+	for userId := range s.users {
+		userIds = append(userIds, userId)
+	}
+	sort.Strings(userIds)
+	users := []any{}
+	for _, userId := range userIds {
+		for _, assignment := range userAssignments(s.users[userId]) {
+			if !containsString(assignment.ouIds, id) {
+				continue
+			}
+			if version >= 2 {
+				users = append(users, object{"user_id": userId, "assigned_role": assignment.roleId})
+			} else {
+				users = append(users, userId)
+			}
+			break
+		}
+	}
+	res["users"] = users
+	res["user_count"] = int64(len(users))
+	return res
+}
+
+// listOrganizationalUnits handles GET /organizational-units.
+func listOrganizationalUnits(s *Server, r *request) (int, any) {
+	conditions, err := parseFilter(r)
+	if err != nil {
+		return badRequest("%v", err)
+	}
+	ids := make([]string, 0, len(s.organizationalUnits))
+	for id := range s.organizationalUnits {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	items := make([]any, 0, len(ids))
+	for _, id := range ids {
+		ou := s.organizationalUnits[id]
+		if !s.visible(r, id) || !matches(ou, conditions) {
+			continue
+		}
+		items = append(items, s.ouRepresentation(ou, r.version))
+	}
+	return http.StatusOK, paginate(r, items)
+}
+
+// createOrganizationalUnit handles POST /organizational-units. The organizational unit is
+// created right away, and the entities of the request are moved to it by a task.
+func createOrganizationalUnit(s *Server, r *request) (int, any) {
+	name := stringField(r.body, "name")
+	if name == "" {
+		return badRequest("The name of the organizational unit is required.")
+	}
+	parentId := stringField(r.body, "parent_id")
+	if parentId == "" {
+		parentId = contextOrganizationalUnit(r)
+	}
+	if ok, status, body := s.checkOrganizationalUnit(r, parentId); !ok {
+		return status, body
+	}
+	for _, other := range s.organizationalUnits {
+		if stringField(other, "parent_id") == parentId && stringField(other, "name") == name {
+			return apiError(http.StatusConflict,
+				"An organizational unit named %s already exists in %s.", name, parentId)
+		}
+	}
+	ou := object{
+		"id":                          uuid.NewString(),
+		"name":                        name,
+		"parent_id":                   parentId,
+		"configured_datasource_types": []any{},
+		"_etag":                       s.newETag(),
+	}
+	if description, ok := r.body["description"].(string); ok {
+		ou["description"] = description
+	}
+	s.organizationalUnits[stringField(ou, "id")] = ou
+
+	entities, _ := r.body["entities"].([]any)
+	if len(entities) == 0 {
+		return http.StatusOK, s.ouRepresentation(ou, r.version)
+	}
+	t := s.moveEntitiesTask(ou, entities, nil)
+	res := s.ouRepresentation(ou, r.version)
+	res["task_id"] = t.id
+	return http.StatusAccepted, res
+}
+
+// findOrganizationalUnit returns the organizational unit of the id path parameter if it is
+// visible to the request.
+func (s *Server) findOrganizationalUnit(r *request) (object, bool) {
+	ou, ok := s.organizationalUnits[r.params["id"]]
+	if !ok || !s.visible(r, r.params["id"]) {
+		return nil, false
+	}
+	return ou, true
+}
+
+// readOrganizationalUnit handles GET /organizational-units/{id}.
+func readOrganizationalUnit(s *Server, r *request) (int, any) {
+	ou, ok := s.findOrganizationalUnit(r)
+	if !ok {
+		return notFound("organizational unit", r.params["id"])
+	}
+	return http.StatusOK, s.ouRepresentation(ou, r.version)
+}
+
+// patchOrganizationalUnit handles PATCH /organizational-units/{id}. The name and description
+// are updated right away, while the entities added or removed are moved by a task.
+func patchOrganizationalUnit(s *Server, r *request) (int, any) {
+	ou, ok := s.findOrganizationalUnit(r)
+	if !ok {
+		return notFound("organizational unit", r.params["id"])
+	}
+	if ok, status, body := checkIfMatch(r, ou); !ok {
+		return status, body
+	}
+	if name, ok := r.body["name"].(string); ok && name == "" {
+		return badRequest("The name of the organizational unit cannot be empty.")
+	}
+	mergeFields(ou, r.body, "name", "description")
+	ou["_etag"] = s.newETag()
+
+	entities := objectField(r.body, "entities")
+	added, _ := entities["add"].([]any)
+	removed, _ := entities["remove"].([]any)
+	if len(added) == 0 && len(removed) == 0 {
+		return http.StatusOK, s.ouRepresentation(ou, r.version)
+	}
+	t := s.moveEntitiesTask(ou, added, removed)
+	res := s.ouRepresentation(ou, r.version)
+	res["task_id"] = t.id
+	return http.StatusAccepted, res
+}
+
+// moveEntitiesTask starts the task moving the AWS environments added to the organizational
+// unit into it, and the ones removed from it to its parent. The AWS connections of the
+// environments move along with them.
+func (s *Server) moveEntitiesTask(ou object, added, removed []any) *task {
+	ouId := stringField(ou, "id")
+	parentId := stringField(ou, "parent_id")
+	moves := map[string]string{}
+	for _, entities := range []struct {
+		items  []any
+		target string
+	}{{added, ouId}, {removed, parentId}} {
+		for _, item := range entities.items {
+			primary := objectField(item.(map[string]any), "primary_entity")
+			if stringField(primary, "type") == entityTypeAwsEnvironment {
+				moves[stringField(primary, "id")] = entities.target
+			}
+		}
+	}
+	t := s.startTask("organizational_unit_entities_update",
+		&taskEntity{entityType: "organizational_unit", id: ouId, value: stringField(ou, "name")},
+		nil)
+	t.effect = func() {
+		for environmentId, target := range moves {
+			environment, ok := s.environments[environmentId]
+			if !ok || target == "" {
+				continue
+			}
+			environment["organizational_unit_id"] = target
+			if connection, ok := s.connections[stringField(environment, "connection_id")]; ok {
+				connection["organizational_unit_id"] = target
+			}
+		}
+	}
+	return t
+}
+
+// deleteOrganizationalUnit handles DELETE /organizational-units/{id}. The organizational unit
+// is removed right away, and the users assigned to it lose the assignment.
+func deleteOrganizationalUnit(s *Server, r *request) (int, any) {
+	ou, ok := s.findOrganizationalUnit(r)
+	if !ok {
+		return notFound("organizational unit", r.params["id"])
+	}
+	id := stringField(ou, "id")
+	if id == GlobalOrganizationalUnitId {
+		return badRequest("The Global Organizational Unit cannot be deleted.")
+	}
+	if ok, status, body := checkIfMatch(r, ou); !ok {
+		return status, body
+	}
+	for _, other := range s.organizationalUnits {
+		if stringField(other, "parent_id") == id {
+			return badRequest("The organizational unit %s has child organizational units.", id)
+		}
+	}
+	parentId := stringField(ou, "parent_id")
+	delete(s.organizationalUnits, id)
+	for _, user := range s.users {
+		if removeUserAssignments(user, id) {
+			user["_etag"] = s.newETag()
+		}
+	}
+	for _, objects := range []map[string]object{s.environments, s.connections,
+		s.protectionGroups, s.policies} {
+		for _, obj := range objects {
+			if stringField(obj, "organizational_unit_id") == id {
+				obj["organizational_unit_id"] = parentId
+			}
+		}
+	}
+	t := s.startTask("organizational_unit_delete",
+		&taskEntity{entityType: "organizational_unit", id: id, value: stringField(ou, "name")},
+		nil)
+	return http.StatusAccepted, object{"task_id": t.id}
+}
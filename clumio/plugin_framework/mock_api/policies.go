@@ -0,0 +1,408 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the handlers of the policy definitions, policy rules and policy assignments APIs.
+
+package mock_api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	lockStatusUnlocked = "unlocked"
+	lockStatusUpdating = "updating"
+
+	entityTypeProtectionGroup = "protection_group"
+)
+
+// policyRoutes are the routes of the policy definitions, policy rules and policy assignments
+// APIs.
+var policyRoutes = []route{
+	{http.MethodGet, "/policies/definitions", listPolicies},
+	{http.MethodPost, "/policies/definitions", createPolicy},
+	{http.MethodGet, "/policies/definitions/{policy_id}", readPolicy},
+	{http.MethodPut, "/policies/definitions/{policy_id}", updatePolicy},
+	{http.MethodDelete, "/policies/definitions/{policy_id}", deletePolicy},
+	{http.MethodPost, "/policies/rules", createRule},
+	{http.MethodGet, "/policies/rules/{rule_id}", readRule},
+	{http.MethodPut, "/policies/rules/{rule_id}", updateRule},
+	{http.MethodDelete, "/policies/rules/{rule_id}", deleteRule},
+	{http.MethodPost, "/policies/assignments", setPolicyAssignments},
+}
+
+// policyOrganizationalUnit returns the organizational unit of a policy or rule.
+func policyOrganizationalUnit(obj object) string {
+	return stringField(obj, "organizational_unit_id")
+}
+
+// validatePolicy verifies the fields of a policy sent in the body of a request. It returns
+// false along with the error to respond with if they are invalid.
+func validatePolicy(body object) (bool, int, any) {
+	if stringField(body, "name") == "" {
+		status, errBody := badRequest("The name of the policy is required.")
+		return false, status, errBody
+	}
+	operations, _ := body["operations"].([]any)
+	for _, op := range operations {
+		operation, _ := op.(map[string]any)
+		if stringField(operation, "type") == "" {
+			status, errBody := badRequest("The type of the policy operation is required.")
+			return false, status, errBody
+		}
+		if region, ok := operation["backup_aws_region"].(string); ok && region == "" {
+			status, errBody := badRequest("The backup_aws_region of the policy operation"+
+				" %s cannot be empty.", stringField(operation, "type"))
+			return false, status, errBody
+		}
+	}
+	return true, 0, nil
+}
+
+// setPolicyFields sets the fields of the policy from the body of a create or update request,
+// defaulting the ones left empty.
+func setPolicyFields(r *request, policy, body object) {
+	policy["name"] = body["name"]
+	policy["activation_status"] = body["activation_status"]
+	if stringField(policy, "activation_status") == "" {
+		policy["activation_status"] = "activated"
+	}
+	policy["timezone"] = body["timezone"]
+	if stringField(policy, "timezone") == "" {
+		policy["timezone"] = "UTC"
+	}
+	if ouId := stringField(body, "organizational_unit_id"); ouId != "" {
+		policy["organizational_unit_id"] = ouId
+	} else if stringField(policy, "organizational_unit_id") == "" {
+		policy["organizational_unit_id"] = contextOrganizationalUnit(r)
+	}
+	operations, _ := body["operations"].([]any)
+	if operations == nil {
+		operations = []any{}
+	}
+	policy["operations"] = operations
+	policy["updated_time"] = time.Now().Unix()
+}
+
+// listPolicies handles GET /policies/definitions.
+func listPolicies(s *Server, r *request) (int, any) {
+	return s.listObjects(r, s.policies, policyOrganizationalUnit)
+}
+
+// createPolicy handles POST /policies/definitions.
+func createPolicy(s *Server, r *request) (int, any) {
+	if ok, status, body := validatePolicy(r.body); !ok {
+		return status, body
+	}
+	policy := object{
+		"id":           uuid.NewString(),
+		"lock_status":  lockStatusUnlocked,
+		"created_time": time.Now().Unix(),
+	}
+	setPolicyFields(r, policy, r.body)
+	if ok, status, body := s.checkOrganizationalUnit(
+		r, stringField(policy, "organizational_unit_id")); !ok {
+		return status, body
+	}
+	s.policies[stringField(policy, "id")] = policy
+	return http.StatusOK, copyObject(policy)
+}
+
+// findPolicy returns the policy of the policy_id path parameter if it is visible to the
+// request.
+func (s *Server) findPolicy(r *request) (object, bool) {
+	policy, ok := s.policies[r.params["policy_id"]]
+	if !ok || !s.visible(r, policyOrganizationalUnit(policy)) {
+		return nil, false
+	}
+	return policy, true
+}
+
+// readPolicy handles GET /policies/definitions/{policy_id}.
+func readPolicy(s *Server, r *request) (int, any) {
+	policy, ok := s.findPolicy(r)
+	if !ok {
+		return notFound("policy", r.params["policy_id"])
+	}
+	return http.StatusOK, copyObject(policy)
+}
+
+// updatePolicy handles PUT /policies/definitions/{policy_id}. The changes are applied right
+// away, and the policy stays locked until the task of the update completes.
+func updatePolicy(s *Server, r *request) (int, any) {
+	policy, ok := s.findPolicy(r)
+	if !ok {
+		return notFound("policy", r.params["policy_id"])
+	}
+	if stringField(policy, "lock_status") != lockStatusUnlocked {
+		return apiError(http.StatusConflict,
+			"The policy %s is locked by an operation in progress.", r.params["policy_id"])
+	}
+	if ok, status, body := validatePolicy(r.body); !ok {
+		return status, body
+	}
+	updated := copyObject(policy)
+	setPolicyFields(r, updated, r.body)
+	if ok, status, body := s.checkOrganizationalUnit(
+		r, stringField(updated, "organizational_unit_id")); !ok {
+		return status, body
+	}
+	updated["lock_status"] = lockStatusUpdating
+	s.policies[r.params["policy_id"]] = updated
+
+	t := s.startTask("policy_update", policyEntity(updated), nil)
+	t.cleanup = func() {
+		if current, ok := s.policies[stringField(updated, "id")]; ok {
+			current["lock_status"] = lockStatusUnlocked
+		}
+	}
+	res := copyObject(updated)
+	res["task_id"] = t.id
+	return http.StatusAccepted, res
+}
+
+// deletePolicy handles DELETE /policies/definitions/{policy_id}. The policy is removed right
+// away from the protection groups it is assigned to, unless it is locked.
+func deletePolicy(s *Server, r *request) (int, any) {
+	policy, ok := s.findPolicy(r)
+	if !ok {
+		return notFound("policy", r.params["policy_id"])
+	}
+	if stringField(policy, "lock_status") != lockStatusUnlocked {
+		return apiError(http.StatusConflict,
+			"The policy %s is locked by an operation in progress.", r.params["policy_id"])
+	}
+	policyId := stringField(policy, "id")
+	delete(s.policies, policyId)
+	for _, pg := range s.protectionGroups {
+		if stringField(objectField(pg, "protection_info"), "policy_id") == policyId {
+			unassignPolicy(pg)
+		}
+	}
+	t := s.startTask("policy_delete", policyEntity(policy), nil)
+	return http.StatusAccepted, object{"task_id": t.id}
+}
+
+// policyEntity returns the policy as the entity of a task.
+func policyEntity(policy object) *taskEntity {
+	return &taskEntity{
+		entityType: "policy",
+		id:         stringField(policy, "id"),
+		value:      stringField(policy, "name"),
+	}
+}
+
+// ruleIndex returns the index of the rule in the ordered list of rules, or -1.
+func (s *Server) ruleIndex(ruleId string) int {
+	for i, rule := range s.rules {
+		if stringField(rule, "id") == ruleId {
+			return i
+		}
+	}
+	return -1
+}
+
+// ruleRepresentation returns the representation of the rule at the given index. Its priority
+// is the ID of the rule after it, or an empty string for the last rule.
+func (s *Server) ruleRepresentation(i int) object {
+	rule := copyObject(s.rules[i])
+	beforeRuleId := ""
+	if i+1 < len(s.rules) {
+		beforeRuleId = stringField(s.rules[i+1], "id")
+	}
+	rule["priority"] = object{"before_rule_id": beforeRuleId}
+	return rule
+}
+
+// insertRule inserts the rule before the rule whose ID is set in the priority of the body of
+// the request, or at the end of the list if it is empty.
+func (s *Server) insertRule(rule, body object) (bool, int, any) {
+	beforeRuleId := stringField(objectField(body, "priority"), "before_rule_id")
+	position := len(s.rules)
+	if beforeRuleId != "" {
+		position = s.ruleIndex(beforeRuleId)
+		if position < 0 {
+			status, errBody := badRequest("The rule %s was not found.", beforeRuleId)
+			return false, status, errBody
+		}
+	}
+	s.rules = append(s.rules, nil)
+	copy(s.rules[position+1:], s.rules[position:])
+	s.rules[position] = rule
+	return true, 0, nil
+}
+
+// validateRule verifies the fields of a rule sent in the body of a request. It returns false
+// along with the error to respond with if they are invalid.
+func (s *Server) validateRule(r *request) (bool, int, any) {
+	if stringField(r.body, "name") == "" || stringField(r.body, "condition") == "" {
+		status, body := badRequest("The name and the condition of the rule are required.")
+		return false, status, body
+	}
+	policyId := stringField(objectField(objectField(r.body, "action"), "assign_policy"),
+		"policy_id")
+	if policy, ok := s.policies[policyId]; !ok ||
+		!s.visible(r, policyOrganizationalUnit(policy)) {
+		status, body := badRequest("The policy %s was not found.", policyId)
+		return false, status, body
+	}
+	return true, 0, nil
+}
+
+// createRule handles POST /policies/rules.
+func createRule(s *Server, r *request) (int, any) {
+	if ok, status, body := s.validateRule(r); !ok {
+		return status, body
+	}
+	rule := object{
+		"id":                     uuid.NewString(),
+		"name":                   r.body["name"],
+		"condition":              r.body["condition"],
+		"action":                 r.body["action"],
+		"organizational_unit_id": contextOrganizationalUnit(r),
+	}
+	if ok, status, body := s.insertRule(rule, r.body); !ok {
+		return status, body
+	}
+	t := s.startTask("policy_rule_create", ruleEntity(rule), nil)
+	return http.StatusAccepted, object{
+		"rule":    s.ruleRepresentation(s.ruleIndex(stringField(rule, "id"))),
+		"task_id": t.id,
+	}
+}
+
+// findRule returns the index of the rule of the rule_id path parameter if it is visible to
+// the request, or -1.
+func (s *Server) findRule(r *request) int {
+	i := s.ruleIndex(r.params["rule_id"])
+	if i < 0 || !s.visible(r, policyOrganizationalUnit(s.rules[i])) {
+		return -1
+	}
+	return i
+}
+
+// readRule handles GET /policies/rules/{rule_id}.
+func readRule(s *Server, r *request) (int, any) {
+	i := s.findRule(r)
+	if i < 0 {
+		return notFound("rule", r.params["rule_id"])
+	}
+	return http.StatusOK, s.ruleRepresentation(i)
+}
+
+// updateRule handles PUT /policies/rules/{rule_id}.
+func updateRule(s *Server, r *request) (int, any) {
+	i := s.findRule(r)
+	if i < 0 {
+		return notFound("rule", r.params["rule_id"])
+	}
+	if ok, status, body := s.validateRule(r); !ok {
+		return status, body
+	}
+	if stringField(objectField(r.body, "priority"), "before_rule_id") == r.params["rule_id"] {
+		return badRequest("A rule cannot be placed before itself.")
+	}
+	previous := append([]object(nil), s.rules...)
+	rule := s.rules[i]
+	mergeFields(rule, r.body, "name", "condition", "action")
+	s.rules = append(s.rules[:i], s.rules[i+1:]...)
+	if ok, status, body := s.insertRule(rule, r.body); !ok {
+		s.rules = previous
+		return status, body
+	}
+	t := s.startTask("policy_rule_update", ruleEntity(rule), nil)
+	return http.StatusAccepted, object{
+		"rule":    s.ruleRepresentation(s.ruleIndex(stringField(rule, "id"))),
+		"task_id": t.id,
+	}
+}
+
+// deleteRule handles DELETE /policies/rules/{rule_id}.
+func deleteRule(s *Server, r *request) (int, any) {
+	i := s.findRule(r)
+	if i < 0 {
+		return notFound("rule", r.params["rule_id"])
+	}
+	rule := s.rules[i]
+	s.rules = append(s.rules[:i], s.rules[i+1:]...)
+	t := s.startTask("policy_rule_delete", ruleEntity(rule), nil)
+	return http.StatusAccepted, object{"task_id": t.id}
+}
+
+// ruleEntity returns the rule as the entity of a task.
+func ruleEntity(rule object) *taskEntity {
+	return &taskEntity{
+		entityType: "policy_rule",
+		id:         stringField(rule, "id"),
+		value:      stringField(rule, "name"),
+	}
+}
+
+// setPolicyAssignments handles POST /policies/assignments. The policies are assigned to or
+// unassigned from the entities when the task completes.
+func setPolicyAssignments(s *Server, r *request) (int, any) {
+	items, _ := r.body["items"].([]any)
+	if len(items) == 0 {
+		return badRequest("At least one assignment is required.")
+	}
+	var effects []func()
+	var primary *taskEntity
+	for _, item := range items {
+		assignment, _ := item.(map[string]any)
+		entity := objectField(assignment, "entity")
+		entityId, entityType := stringField(entity, "id"), stringField(entity, "type")
+		if entityType != entityTypeProtectionGroup {
+			return badRequest("Unsupported entity type %q.", entityType)
+		}
+		pg, ok := s.protectionGroups[entityId]
+		if !ok || !s.visible(r, stringField(pg, "organizational_unit_id")) {
+			return badRequest("The protection group %s was not found.", entityId)
+		}
+		policyId := stringField(assignment, "policy_id")
+		switch stringField(assignment, "action") {
+		case "assign":
+			policy, ok := s.policies[policyId]
+			if !ok || !s.visible(r, policyOrganizationalUnit(policy)) {
+				return badRequest("The policy %s was not found.", policyId)
+			}
+			effects = append(effects, func() {
+				if _, ok := s.policies[policyId]; ok {
+					assignPolicy(pg, policyId)
+				}
+			})
+		case "unassign":
+			effects = append(effects, func() { unassignPolicy(pg) })
+		default:
+			return badRequest("Unsupported action %q.", stringField(assignment, "action"))
+		}
+		if primary == nil {
+			primary = &taskEntity{entityType: entityType, id: entityId,
+				value: stringField(pg, "name")}
+		}
+	}
+	t := s.startTask("policy_assignment", primary, nil)
+	t.effect = func() {
+		for _, effect := range effects {
+			effect()
+		}
+	}
+	return http.StatusAccepted, object{"task_id": t.id}
+}
+
+// assignPolicy applies the policy to the protection group.
+func assignPolicy(pg object, policyId string) {
+	pg["protection_info"] = object{
+		"policy_id":              policyId,
+		"inheriting_entity_id":   nil,
+		"inheriting_entity_type": nil,
+	}
+	pg["protection_status"] = "protected"
+}
+
+// unassignPolicy removes the policy applied to the protection group.
+func unassignPolicy(pg object) {
+	pg["protection_info"] = nil
+	pg["protection_status"] = "unprotected"
+}
@@ -0,0 +1,117 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the handlers of the protection groups API.
+
+package mock_api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// protectionGroupRoutes are the routes of the protection groups API.
+var protectionGroupRoutes = []route{
+	{http.MethodPost, "/protection-groups", createProtectionGroup},
+	{http.MethodGet, "/datasources/protection-groups", listProtectionGroups},
+	{http.MethodGet, "/datasources/protection-groups/{group_id}", readProtectionGroup},
+	{http.MethodPut, "/protection-groups/{group_id}", updateProtectionGroup},
+	{http.MethodDelete, "/protection-groups/{group_id}", deleteProtectionGroup},
+}
+
+// protectionGroupOrganizationalUnit returns the organizational unit of a protection group.
+func protectionGroupOrganizationalUnit(pg object) string {
+	return stringField(pg, "organizational_unit_id")
+}
+
+// setProtectionGroupFields sets the fields of the protection group from the body of a create
+// or update request. The API returns empty strings for the description and bucket rule left
+// unset.
+func setProtectionGroupFields(pg, body object) {
+	pg["name"] = body["name"]
+	pg["description"] = stringField(body, "description")
+	pg["bucket_rule"] = stringField(body, "bucket_rule")
+	pg["object_filter"] = body["object_filter"]
+	if pg["object_filter"] == nil {
+		pg["object_filter"] = object{"storage_classes": []any{}}
+	}
+	pg["modified_timestamp"] = timestamp(time.Now())
+	pg["version"] = pgVersion(pg) + 1
+}
+
+// pgVersion returns the version of the protection group, incremented by every update.
+func pgVersion(pg object) int64 {
+	switch version := pg["version"].(type) {
+	case int64:
+		return version
+	case float64:
+		return int64(version)
+	}
+	return 0
+}
+
+// createProtectionGroup handles POST /protection-groups.
+func createProtectionGroup(s *Server, r *request) (int, any) {
+	if stringField(r.body, "name") == "" {
+		return badRequest("The name of the protection group is required.")
+	}
+	pg := object{
+		"id":                     uuid.NewString(),
+		"organizational_unit_id": contextOrganizationalUnit(r),
+		"created_timestamp":      timestamp(time.Now()),
+		"bucket_count":           int64(0),
+		"regions":                []any{},
+	}
+	setProtectionGroupFields(pg, r.body)
+	unassignPolicy(pg)
+	s.protectionGroups[stringField(pg, "id")] = pg
+	return http.StatusOK, copyObject(pg)
+}
+
+// listProtectionGroups handles GET /datasources/protection-groups.
+func listProtectionGroups(s *Server, r *request) (int, any) {
+	return s.listObjects(r, s.protectionGroups, protectionGroupOrganizationalUnit)
+}
+
+// findProtectionGroup returns the protection group of the group_id path parameter if it is
+// visible to the request.
+func (s *Server) findProtectionGroup(r *request) (object, bool) {
+	pg, ok := s.protectionGroups[r.params["group_id"]]
+	if !ok || !s.visible(r, protectionGroupOrganizationalUnit(pg)) {
+		return nil, false
+	}
+	return pg, true
+}
+
+// readProtectionGroup handles GET /datasources/protection-groups/{group_id}.
+func readProtectionGroup(s *Server, r *request) (int, any) {
+	pg, ok := s.findProtectionGroup(r)
+	if !ok {
+		return notFound("protection group", r.params["group_id"])
+	}
+	return http.StatusOK, copyObject(pg)
+}
+
+// updateProtectionGroup handles PUT /protection-groups/{group_id}.
+func updateProtectionGroup(s *Server, r *request) (int, any) {
+	pg, ok := s.findProtectionGroup(r)
+	if !ok {
+		return notFound("protection group", r.params["group_id"])
+	}
+	if stringField(r.body, "name") == "" {
+		return badRequest("The name of the protection group is required.")
+	}
+	setProtectionGroupFields(pg, r.body)
+	return http.StatusOK, copyObject(pg)
+}
+
+// deleteProtectionGroup handles DELETE /protection-groups/{group_id}.
+func deleteProtectionGroup(s *Server, r *request) (int, any) {
+	pg, ok := s.findProtectionGroup(r)
+	if !ok {
+		return notFound("protection group", r.params["group_id"])
+	}
+	delete(s.protectionGroups, stringField(pg, "id"))
+	return http.StatusOK, object{}
+}
@@ -0,0 +1,364 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the in-process mock of the Clumio REST API used to run the acceptance tests without
+// a Clumio tenant. The server keeps the objects created through it in memory, backs the
+// asynchronous operations with tasks that complete after TaskDuration, scopes the objects by
+// the organizational unit context of the requests and can be told to fail requests or tasks.
+
+package mock_api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultToken is the API token accepted by the servers returned by NewServer.
+	DefaultToken = "mock-clumio-api-token"
+
+	// DefaultTaskDuration is how long the tasks of the server take to complete.
+	DefaultTaskDuration = time.Second
+
+	// GlobalOrganizationalUnitId is the ID of the root organizational unit, which every
+	// other organizational unit descends from.
+	GlobalOrganizationalUnitId = "00000000-0000-0000-0000-000000000000"
+
+	// globalOrganizationalUnitName is the name of the root organizational unit.
+	globalOrganizationalUnitName = "Global Organizational Unit"
+
+	// ouContextHeader is the header holding the organizational unit context of a request.
+	ouContextHeader = "x-clumio-organizationalunit-context"
+
+	// ifMatchHeader is the header holding the ETag the object must have for the request to
+	// be applied.
+	ifMatchHeader = "If-Match"
+)
+
+// acceptVersionRegex extracts the API version from the Accept header, such as
+// application/api.clumio.users=v2+json.
+var acceptVersionRegex = regexp.MustCompile(`=v(\d+)\+json`)
+
+// object is the representation of the objects kept by the server. It is the JSON
+// representation returned by the API, so that the fields sent by the provider are echoed back
+// as they were sent.
+type object = map[string]any
+
+// Request is a request received by the server.
+type Request struct {
+	Method string
+	Path   string
+	// Query is the raw query string of the request.
+	Query string
+	// Header holds the headers of the request.
+	Header http.Header
+	// Body is the raw body of the request.
+	Body []byte
+	// StatusCode is the status code of the response.
+	StatusCode int
+}
+
+// Server is a stateful in-process mock of the Clumio REST API. Its zero value is not usable,
+// servers are created with NewServer.
+type Server struct {
+	// URL is the base URL of the server, to use as the clumio_api_base_url of the provider.
+	URL string
+	// Token is the API token accepted by the server.
+	Token string
+
+	httpServer *httptest.Server
+	routes     []route
+
+	mu           sync.Mutex
+	taskDuration time.Duration
+	requests     []Request
+	faults       []*Fault
+	taskFaults   []*TaskFault
+	etagSequence int64
+
+	tasks               map[string]*task
+	taskOrder           []string
+	policies            map[string]object
+	rules               []object
+	protectionGroups    map[string]object
+	connections         map[string]object
+	environments        map[string]object
+	wallets             map[string]object
+	organizationalUnits map[string]object
+	users               map[string]object
+	userSequence        int64
+	roles               []object
+	aupRules            map[string]object
+	aupEnabled          bool
+}
+
+// request is a request being handled by the server.
+type request struct {
+	*http.Request
+	// params holds the values of the path parameters of the route, such as policy_id.
+	params map[string]string
+	// version is the API version requested through the Accept header.
+	version int
+	// ouContext is the organizational unit context of the request, or an empty string.
+	ouContext string
+	// body is the decoded body of the request.
+	body object
+}
+
+// handlerFunc handles a request and returns the status code and the body of the response.
+type handlerFunc func(s *Server, r *request) (int, any)
+
+// route is an endpoint of the API. Its path segments between braces, such as {policy_id},
+// match any value and are passed to the handler as path parameters.
+type route struct {
+	method  string
+	path    string
+	handler handlerFunc
+}
+
+// NewServer starts a mock of the Clumio API on a local port. The server holds the Global
+// Organizational Unit, the predefined roles and nothing else. Close must be called to stop it.
+func NewServer() *Server {
+	s := &Server{
+		Token:               DefaultToken,
+		taskDuration:        DefaultTaskDuration,
+		tasks:               map[string]*task{},
+		policies:            map[string]object{},
+		protectionGroups:    map[string]object{},
+		connections:         map[string]object{},
+		environments:        map[string]object{},
+		wallets:             map[string]object{},
+		organizationalUnits: map[string]object{},
+		users:               map[string]object{},
+		aupRules:            map[string]object{},
+	}
+	s.seed()
+	s.routes = append(s.routes, policyRoutes...)
+	s.routes = append(s.routes, protectionGroupRoutes...)
+	s.routes = append(s.routes, awsRoutes...)
+	s.routes = append(s.routes, organizationalUnitRoutes...)
+	s.routes = append(s.routes, userRoutes...)
+	s.routes = append(s.routes, walletRoutes...)
+	s.routes = append(s.routes, taskRoutes...)
+	s.httpServer = httptest.NewServer(s)
+	s.URL = s.httpServer.URL
+	return s
+}
+
+// Close stops the server.
+func (s *Server) Close() {
+	s.httpServer.Close()
+}
+
+// SetTaskDuration sets how long the tasks started from now on take to complete. A zero
+// duration completes them on the next request.
+func (s *Server) SetTaskDuration(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.taskDuration = d
+}
+
+// CompleteTasks ends the tasks in progress right away, as if their duration had elapsed.
+func (s *Server) CompleteTasks() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.tasks {
+		if !t.isTerminal() {
+			t.duration = 0
+		}
+	}
+	s.advanceTasks(time.Now())
+}
+
+// Requests returns the requests received by the server so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// seed creates the objects that exist in every Clumio tenant.
+func (s *Server) seed() {
+	s.organizationalUnits[GlobalOrganizationalUnitId] = object{
+		"id":                          GlobalOrganizationalUnitId,
+		"name":                        globalOrganizationalUnitName,
+		"description":                 "The root organizational unit.",
+		"parent_id":                   nil,
+		"children_count":              int64(0),
+		"user_count":                  int64(0),
+		"users":                       []any{},
+		"descendant_ids":              []any{},
+		"configured_datasource_types": []any{},
+		"_etag":                       s.newETag(),
+	}
+	s.roles = []object{
+		newRole("00000000-0000-0000-0000-000000000000", "Super Admin",
+			"Full access to all the features of Clumio."),
+		newRole("10000000-0000-0000-0000-000000000000", "Organizational Unit Admin",
+			"Full access to the organizational units assigned to the user."),
+		newRole("20000000-0000-0000-0000-000000000000", "Restore Admin",
+			"Access to the restores of the organizational units assigned to the user."),
+		newRole("30000000-0000-0000-0000-000000000000", "Backup Admin",
+			"Access to the backups of the organizational units assigned to the user."),
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, httpReq *http.Request) {
+	rawBody, _ := io.ReadAll(httpReq.Body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.advanceTasks(time.Now())
+
+	status, body, header := s.handle(httpReq, rawBody)
+	s.requests = append(s.requests, Request{
+		Method:     httpReq.Method,
+		Path:       httpReq.URL.Path,
+		Query:      httpReq.URL.RawQuery,
+		Header:     httpReq.Header.Clone(),
+		Body:       rawBody,
+		StatusCode: status,
+	})
+
+	for key, value := range header {
+		w.Header().Set(key, value)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if body != nil {
+		_ = json.NewEncoder(w).Encode(body)
+	}
+}
+
+// handle authenticates and routes the request, and returns the status code, body and extra
+// headers of the response.
+func (s *Server) handle(httpReq *http.Request, rawBody []byte) (int, any, map[string]string) {
+	if httpReq.Header.Get("Authorization") != "Bearer "+s.Token {
+		status, body := apiError(http.StatusUnauthorized, "The API token is invalid.")
+		return status, body, nil
+	}
+	if fault := s.matchFault(httpReq.Method, httpReq.URL.Path); fault != nil {
+		status, body := fault.response()
+		return status, body, fault.Header
+	}
+
+	var handler handlerFunc
+	var params map[string]string
+	for _, rt := range s.routes {
+		if rt.method != httpReq.Method {
+			continue
+		}
+		if values, ok := matchPath(rt.path, httpReq.URL.Path); ok {
+			handler, params = rt.handler, values
+			break
+		}
+	}
+	if handler == nil {
+		status, body := apiError(http.StatusNotFound, "No route for %s %s.",
+			httpReq.Method, httpReq.URL.Path)
+		return status, body, nil
+	}
+
+	req := &request{
+		Request:   httpReq,
+		params:    params,
+		version:   1,
+		ouContext: httpReq.Header.Get(ouContextHeader),
+	}
+	if match := acceptVersionRegex.FindStringSubmatch(httpReq.Header.Get("Accept")); match != nil {
+		req.version, _ = strconv.Atoi(match[1])
+	}
+	if req.ouContext != "" {
+		if _, ok := s.organizationalUnits[req.ouContext]; !ok {
+			status, body := apiError(http.StatusForbidden,
+				"The organizational unit context %s is not accessible.", req.ouContext)
+			return status, body, nil
+		}
+	}
+	if len(rawBody) > 0 {
+		if err := json.Unmarshal(rawBody, &req.body); err != nil {
+			status, body := apiError(http.StatusBadRequest, "Invalid request body: %v", err)
+			return status, body, nil
+		}
+	}
+	if req.body == nil {
+		req.body = object{}
+	}
+	status, body := handler(s, req)
+	return status, body, nil
+}
+
+// matchPath matches the path of a request against the path of a route, and returns the
+// values of the path parameters of the route.
+func matchPath(pattern, path string) (map[string]string, bool) {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(patternSegments) != len(pathSegments) {
+		return nil, false
+	}
+	params := map[string]string{}
+	for i, segment := range patternSegments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params[strings.Trim(segment, "{}")] = pathSegments[i]
+			continue
+		}
+		if segment != pathSegments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// apiError returns the status code and the error envelope of an API error.
+func apiError(status int, format string, args ...any) (int, any) {
+	return status, object{
+		"errors": []object{{
+			"error_code":    status,
+			"error_message": fmt.Sprintf(format, args...),
+		}},
+		"request_id": uuid.NewString(),
+	}
+}
+
+// notFound returns the error of a request for an object that does not exist.
+func notFound(kind, id string) (int, any) {
+	return apiError(http.StatusNotFound, "The %s %s was not found.", kind, id)
+}
+
+// badRequest returns the error of an invalid request.
+func badRequest(format string, args ...any) (int, any) {
+	return apiError(http.StatusBadRequest, format, args...)
+}
+
+// newETag returns a new ETag, different from all the ones returned before.
+func (s *Server) newETag() string {
+	s.etagSequence++
+	return fmt.Sprintf("%016x", s.etagSequence)
+}
+
+// checkIfMatch verifies the If-Match header of the request against the ETag of the object.
+// It returns false along with the error to respond with if they differ.
+func checkIfMatch(r *request, obj object) (bool, int, any) {
+	etag := r.Header.Get(ifMatchHeader)
+	if etag == "" || etag == stringField(obj, "_etag") {
+		return true, 0, nil
+	}
+	status, body := apiError(http.StatusPreconditionFailed,
+		"The ETag %s does not match the current ETag of the object.", etag)
+	return false, status, body
+}
+
+// timestamp returns the given time in the RFC-3339 format of the API.
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
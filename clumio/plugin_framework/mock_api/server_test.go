@@ -0,0 +1,120 @@
+// Copyright 2024. Clumio, Inc.
+
+// Unit tests of the mock Clumio API.
+
+package mock_api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// do sends a request to the server and returns the status code and decoded body of the
+// response.
+func do(t *testing.T, s *Server, method, path string, body object) (int, object) {
+	t.Helper()
+	var reqBody []byte
+	if body != nil {
+		var err error
+		if reqBody, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req, err := http.NewRequest(method, s.URL+path, bytes.NewReader(reqBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.Token)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	var resBody object
+	_ = json.NewDecoder(res.Body).Decode(&resBody)
+	return res.StatusCode, resBody
+}
+
+func TestServerRejectsInvalidToken(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	req, err := http.NewRequest(http.MethodGet, s.URL+"/wallets", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer another-token")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("got status %d, want %d", res.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestServerFault(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.InjectFault(Fault{
+		Method:     http.MethodGet,
+		Path:       "/wallets/{wallet_id}",
+		StatusCode: http.StatusServiceUnavailable,
+		Times:      1,
+	})
+	_, wallet := do(t, s, http.MethodPost, "/wallets", object{"account_native_id": "123456789012"})
+	path := "/wallets/" + stringField(wallet, "id")
+	if status, _ := do(t, s, http.MethodGet, path, nil); status != http.StatusServiceUnavailable {
+		t.Errorf("got status %d, want %d", status, http.StatusServiceUnavailable)
+	}
+	if status, _ := do(t, s, http.MethodGet, path, nil); status != http.StatusOK {
+		t.Errorf("got status %d after the fault, want %d", status, http.StatusOK)
+	}
+}
+
+func TestServerTaskFault(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.SetTaskDuration(0)
+	s.InjectTaskFault(TaskFault{Type: "organizational_unit_delete", Reason: "Injected."})
+	_, ou := do(t, s, http.MethodPost, "/organizational-units", object{"name": "test-ou"})
+	status, res := do(t, s, http.MethodDelete, "/organizational-units/"+stringField(ou, "id"),
+		nil)
+	if status != http.StatusAccepted {
+		t.Fatalf("got status %d, want %d", status, http.StatusAccepted)
+	}
+	_, task := do(t, s, http.MethodGet, "/tasks/"+stringField(res, "task_id"), nil)
+	if got := stringField(task, "status"); got != taskFailed {
+		t.Errorf("got task status %q, want %q", got, taskFailed)
+	}
+}
+
+func TestServerPolicyLock(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	policyBody := object{"name": "test-policy", "operations": []any{}}
+	_, policy := do(t, s, http.MethodPost, "/policies/definitions", policyBody)
+	policyPath := "/policies/definitions/" + stringField(policy, "id")
+	if status, _ := do(t, s, http.MethodPut, policyPath, policyBody); status !=
+		http.StatusAccepted {
+		t.Fatalf("got status %d, want %d", status, http.StatusAccepted)
+	}
+	for _, method := range []string{http.MethodPut, http.MethodDelete} {
+		if status, _ := do(t, s, method, policyPath, policyBody); status !=
+			http.StatusConflict {
+			t.Errorf("%s of a locked policy: got status %d, want %d", method, status,
+				http.StatusConflict)
+		}
+	}
+	s.CompleteTasks()
+	_, policy = do(t, s, http.MethodGet, policyPath, nil)
+	if got := stringField(policy, "lock_status"); got != lockStatusUnlocked {
+		t.Errorf("got lock status %q, want %q", got, lockStatusUnlocked)
+	}
+	if status, _ := do(t, s, http.MethodDelete, policyPath, nil); status !=
+		http.StatusAccepted {
+		t.Errorf("got status %d, want %d", status, http.StatusAccepted)
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the helpers used by the handlers to store, scope, filter and list the objects of
+// the server.
+
+package mock_api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// copyObject returns a deep copy of the object, so that the objects kept by the server are
+// never shared with the responses.
+func copyObject(obj object) object {
+	encoded, err := json.Marshal(obj)
+	if err != nil {
+		panic(fmt.Sprintf("unable to copy object: %v", err))
+	}
+	var copied object
+	if err := json.Unmarshal(encoded, &copied); err != nil {
+		panic(fmt.Sprintf("unable to copy object: %v", err))
+	}
+	return copied
+}
+
+// stringField returns the value of a string field of the object, or an empty string if the
+// field is missing or null.
+func stringField(obj object, key string) string {
+	value, _ := obj[key].(string)
+	return value
+}
+
+// stringSliceField returns the value of a field of the object holding a list of strings.
+func stringSliceField(obj object, key string) []string {
+	values, _ := obj[key].([]any)
+	strs := make([]string, 0, len(values))
+	for _, value := range values {
+		if str, ok := value.(string); ok {
+			strs = append(strs, str)
+		}
+	}
+	return strs
+}
+
+// objectField returns the value of a field of the object holding an object, or nil if the
+// field is missing or null.
+func objectField(obj object, key string) object {
+	value, _ := obj[key].(map[string]any)
+	return value
+}
+
+// mergeFields sets the fields of the object present and not null in the body of a request,
+// among the given keys.
+func mergeFields(obj, body object, keys ...string) {
+	for _, key := range keys {
+		if value, ok := body[key]; ok && value != nil {
+			obj[key] = value
+		}
+	}
+}
+
+// toAnySlice converts a list of strings to its JSON representation.
+func toAnySlice(strs []string) []any {
+	values := make([]any, 0, len(strs))
+	for _, str := range strs {
+		values = append(values, str)
+	}
+	return values
+}
+
+// contextOrganizationalUnit returns the organizational unit of the objects created by the
+// request when the request does not set one: the organizational unit context of the request,
+// or the Global Organizational Unit.
+func contextOrganizationalUnit(r *request) string {
+	if r.ouContext != "" {
+		return r.ouContext
+	}
+	return GlobalOrganizationalUnitId
+}
+
+// isDescendant returns whether the organizational unit ouId is ancestorId or one of its
+// descendants.
+func (s *Server) isDescendant(ouId, ancestorId string) bool {
+	for ouId != "" {
+		if ouId == ancestorId {
+			return true
+		}
+		ou, ok := s.organizationalUnits[ouId]
+		if !ok {
+			return false
+		}
+		ouId = stringField(ou, "parent_id")
+	}
+	return false
+}
+
+// visible returns whether an object of the given organizational unit is visible to the
+// request, that is whether it belongs to the organizational unit context of the request or
+// to one of its descendants.
+func (s *Server) visible(r *request, ouId string) bool {
+	if r.ouContext == "" {
+		return true
+	}
+	return s.isDescendant(ouId, r.ouContext)
+}
+
+// checkOrganizationalUnit verifies that the organizational unit set in the body of a request
+// exists and is visible to the request. It returns false along with the error to respond with
+// otherwise.
+func (s *Server) checkOrganizationalUnit(r *request, ouId string) (bool, int, any) {
+	if _, ok := s.organizationalUnits[ouId]; !ok || !s.visible(r, ouId) {
+		status, body := badRequest("The organizational unit %s was not found.", ouId)
+		return false, status, body
+	}
+	return true, 0, nil
+}
+
+// filterCondition is a condition of the filter query parameter of the list APIs, such as
+// {"name": {"$eq": "gold"}}.
+type filterCondition struct {
+	field    string
+	operator string
+	value    any
+}
+
+// parseFilter parses the filter query parameter of a request.
+func parseFilter(r *request) ([]filterCondition, error) {
+	raw := r.URL.Query().Get("filter")
+	if raw == "" {
+		return nil, nil
+	}
+	var filter map[string]map[string]any
+	if err := json.Unmarshal([]byte(raw), &filter); err != nil {
+		return nil, fmt.Errorf("invalid filter %s: %w", raw, err)
+	}
+	var conditions []filterCondition
+	for field, operators := range filter {
+		for operator, value := range operators {
+			conditions = append(conditions, filterCondition{field, operator, value})
+		}
+	}
+	return conditions, nil
+}
+
+// matches returns whether the object satisfies all the conditions of the filter. The
+// conditions on fields the object does not have are ignored, as the API supports filtering on
+// fields that are not part of the representation of the objects.
+func matches(obj object, conditions []filterCondition) bool {
+	for _, condition := range conditions {
+		value, ok := obj[condition.field]
+		if !ok {
+			continue
+		}
+		switch condition.operator {
+		case "$eq":
+			if !reflect.DeepEqual(value, condition.value) {
+				return false
+			}
+		case "$in":
+			candidates, _ := condition.value.([]any)
+			found := false
+			for _, candidate := range candidates {
+				if reflect.DeepEqual(value, candidate) {
+					found = true
+				}
+			}
+			if !found {
+				return false
+			}
+		case "$contains":
+			str, _ := value.(string)
+			substr, _ := condition.value.(string)
+			if !strings.Contains(strings.ToLower(str), strings.ToLower(substr)) {
+				return false
+			}
+		case "$begins_with":
+			str, _ := value.(string)
+			prefix, _ := condition.value.(string)
+			if !strings.HasPrefix(str, prefix) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// listObjects returns the response of a list API: the objects visible to the request and
+// matching its filter, paginated with its limit and start query parameters. ouIdOf returns
+// the organizational unit of an object.
+func (s *Server) listObjects(r *request, objects map[string]object,
+	ouIdOf func(object) string) (int, any) {
+	conditions, err := parseFilter(r)
+	if err != nil {
+		return badRequest("%v", err)
+	}
+	ids := make([]string, 0, len(objects))
+	for id := range objects {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	items := make([]any, 0, len(ids))
+	for _, id := range ids {
+		obj := objects[id]
+		if !s.visible(r, ouIdOf(obj)) || !matches(obj, conditions) {
+			continue
+		}
+		items = append(items, copyObject(obj))
+	}
+	return http.StatusOK, paginate(r, items)
+}
+
+// paginate returns the page of the items requested through the limit and start query
+// parameters, in the representation returned by the list APIs.
+func paginate(r *request, items []any) object {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	start, err := strconv.Atoi(r.URL.Query().Get("start"))
+	if err != nil || start <= 0 {
+		start = 1
+	}
+	total := len(items)
+	first := (start - 1) * limit
+	if first > total {
+		first = total
+	}
+	last := first + limit
+	if last > total {
+		last = total
+	}
+	page := items[first:last]
+	totalPages := (total + limit - 1) / limit
+	return object{
+		"_embedded":         object{"items": page},
+		"current_count":     len(page),
+		"total_count":       total,
+		"total_pages_count": totalPages,
+		"limit":             limit,
+		"start":             strconv.Itoa(start),
+		"filter_applied":    r.URL.Query().Get("filter"),
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the tasks backing the asynchronous operations of the server.
+
+package mock_api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	taskQueued     = "queued"
+	taskInProgress = "in_progress"
+	taskCompleted  = "completed"
+	taskFailed     = "failed"
+	taskAborted    = "aborted"
+)
+
+// taskRoutes are the routes of the tasks API.
+var taskRoutes = []route{
+	{http.MethodGet, "/tasks/{task_id}", readTask},
+}
+
+// taskEntity is an entity a task operates on.
+type taskEntity struct {
+	entityType string
+	id         string
+	value      string
+}
+
+// task is an asynchronous operation of the server. It completes once its duration has
+// elapsed, at which point its effect is applied. A failed or aborted task does not apply its
+// effect, but always runs its cleanup, such as the unlocking of the policy it updates.
+type task struct {
+	id            string
+	taskType      string
+	primaryEntity *taskEntity
+	parentEntity  *taskEntity
+	created       time.Time
+	duration      time.Duration
+	status        string
+	ended         time.Time
+	effect        func()
+	cleanup       func()
+	fault         *TaskFault
+}
+
+// startTask starts a task of the given type operating on primary, and returns it. Its effect
+// and cleanup may be set by the caller.
+func (s *Server) startTask(taskType string, primary, parent *taskEntity) *task {
+	t := &task{
+		id:            uuid.NewString(),
+		taskType:      taskType,
+		primaryEntity: primary,
+		parentEntity:  parent,
+		created:       time.Now(),
+		duration:      s.taskDuration,
+		status:        taskQueued,
+		fault:         s.matchTaskFault(taskType),
+	}
+	s.tasks[t.id] = t
+	s.taskOrder = append(s.taskOrder, t.id)
+	return t
+}
+
+// advanceTasks ends the tasks whose duration has elapsed, in the order they were started, and
+// moves the others to in_progress.
+func (s *Server) advanceTasks(now time.Time) {
+	for _, id := range s.taskOrder {
+		t := s.tasks[id]
+		if t.isTerminal() {
+			continue
+		}
+		if now.Before(t.created.Add(t.duration)) {
+			t.status = taskInProgress
+			continue
+		}
+		t.ended = now
+		if t.fault != nil {
+			t.status = taskFailed
+			if t.fault.Status != "" {
+				t.status = t.fault.Status
+			}
+		} else {
+			t.status = taskCompleted
+			if t.effect != nil {
+				t.effect()
+			}
+		}
+		if t.cleanup != nil {
+			t.cleanup()
+		}
+	}
+}
+
+// isTerminal returns whether the task has ended.
+func (t *task) isTerminal() bool {
+	return t.status == taskCompleted || t.status == taskFailed || t.status == taskAborted
+}
+
+// representation returns the representation of the task returned by the tasks API.
+func (t *task) representation(now time.Time) object {
+	progress := int64(0)
+	switch {
+	case t.status == taskCompleted:
+		progress = 100
+	case t.isTerminal():
+		progress = int64(50)
+	case t.duration > 0:
+		progress = int64(100 * now.Sub(t.created) / t.duration)
+		if progress > 99 {
+			progress = 99
+		}
+	}
+	representation := object{
+		"id":                  t.id,
+		"type":                t.taskType,
+		"category":            "management",
+		"status":              t.status,
+		"progress_percentage": progress,
+		"is_abortable":        false,
+		"created_timestamp":   timestamp(t.created),
+		"start_timestamp":     nil,
+		"end_timestamp":       nil,
+		"primary_entity":      t.primaryEntity.representation(),
+		"parent_entity":       t.parentEntity.representation(),
+	}
+	if t.status != taskQueued {
+		representation["start_timestamp"] = timestamp(t.created)
+	}
+	if t.isTerminal() {
+		representation["end_timestamp"] = timestamp(t.ended)
+	}
+	if t.fault != nil && t.isTerminal() {
+		representation["errors"] = []object{{
+			"error_code":    http.StatusInternalServerError,
+			"error_message": t.fault.Reason,
+		}}
+	}
+	return representation
+}
+
+// representation returns the representation of the entity returned by the tasks API.
+func (e *taskEntity) representation() any {
+	if e == nil {
+		return nil
+	}
+	return object{"type": e.entityType, "id": e.id, "value": e.value}
+}
+
+// readTask handles GET /tasks/{task_id}.
+func readTask(s *Server, r *request) (int, any) {
+	t, ok := s.tasks[r.params["task_id"]]
+	if !ok {
+		return notFound("task", r.params["task_id"])
+	}
+	return http.StatusOK, t.representation(time.Now())
+}
@@ -0,0 +1,474 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the handlers of the users API in its V1 and V2 versions, and of the roles and auto
+// user provisioning APIs.
+
+package mock_api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// mockInviter is the ID of the user inviting the users created through the server.
+const mockInviter = "1"
+
+// userRoutes are the routes of the users, roles and auto user provisioning APIs.
+var userRoutes = []route{
+	{http.MethodGet, "/users", listUsers},
+	{http.MethodPost, "/users", createUser},
+	{http.MethodGet, "/users/{user_id}", readUser},
+	{http.MethodPatch, "/users/{user_id}", updateUser},
+	{http.MethodDelete, "/users/{user_id}", deleteUser},
+	{http.MethodGet, "/roles", listRoles},
+	{http.MethodGet, "/roles/{role_id}", readRole},
+	{http.MethodGet, "/settings/auto-user-provisioning", readAupSetting},
+	{http.MethodPut, "/settings/auto-user-provisioning", updateAupSetting},
+	{http.MethodGet, "/settings/auto-user-provisioning/rules", listAupRules},
+	{http.MethodPost, "/settings/auto-user-provisioning/rules", createAupRule},
+	{http.MethodGet, "/settings/auto-user-provisioning/rules/{rule_id}", readAupRule},
+	{http.MethodPut, "/settings/auto-user-provisioning/rules/{rule_id}", updateAupRule},
+	{http.MethodDelete, "/settings/auto-user-provisioning/rules/{rule_id}", deleteAupRule},
+}
+
+// newRole returns a role with a single permission named after it.
+func newRole(id, name, description string) object {
+	return object{
+		"id":          id,
+		"name":        name,
+		"description": description,
+		"permissions": []any{object{
+			"id":          uuid.NewString(),
+			"name":        name + " permission",
+			"description": "Grants the permissions of the " + name + " role.",
+		}},
+		"_etag": "",
+	}
+}
+
+// assignment is the assignment of a role to a user in a set of organizational units.
+type assignment struct {
+	roleId string
+	ouIds  []string
+}
+
+// userAssignments returns the assignments of the user, which are stored in the V2
+// access_control_configuration format whatever the version of the API used.
+func userAssignments(user object) []assignment {
+	var assignments []assignment
+	items, _ := user["access_control_configuration"].([]any)
+	for _, item := range items {
+		acc, _ := item.(map[string]any)
+		assignments = append(assignments, assignment{
+			roleId: stringField(acc, "role_id"),
+			ouIds:  stringSliceField(acc, "organizational_unit_ids"),
+		})
+	}
+	return assignments
+}
+
+// setUserAssignments stores the assignments of the user, dropping the ones left without any
+// organizational unit.
+func setUserAssignments(user object, assignments []assignment) {
+	items := []any{}
+	for _, a := range assignments {
+		if len(a.ouIds) == 0 {
+			continue
+		}
+		ouIds := append([]string{}, a.ouIds...)
+		sort.Strings(ouIds)
+		items = append(items, object{
+			"role_id":                 a.roleId,
+			"organizational_unit_ids": toAnySlice(ouIds),
+		})
+	}
+	user["access_control_configuration"] = items
+}
+
+// userOrganizationalUnits returns the sorted IDs of the organizational units the user is
+// assigned to.
+func userOrganizationalUnits(user object) []string {
+	var ouIds []string
+	for _, a := range userAssignments(user) {
+		for _, ouId := range a.ouIds {
+			if !containsString(ouIds, ouId) {
+				ouIds = append(ouIds, ouId)
+			}
+		}
+	}
+	sort.Strings(ouIds)
+	return ouIds
+}
+
+// removeUserAssignments removes the organizational unit from the assignments of the user, and
+// returns whether it was assigned to it.
+func removeUserAssignments(user object, ouId string) bool {
+	assignments := userAssignments(user)
+	removed := false
+	for i := range assignments {
+		if containsString(assignments[i].ouIds, ouId) {
+			assignments[i].ouIds = removeString(assignments[i].ouIds, ouId)
+			removed = true
+		}
+	}
+	if removed {
+		setUserAssignments(user, assignments)
+	}
+	return removed
+}
+
+// containsString returns whether the value is in the slice.
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// removeString returns the slice without the value.
+func removeString(values []string, value string) []string {
+	res := []string{}
+	for _, v := range values {
+		if v != value {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// userRepresentation returns the representation of the user in the given version of the API.
+// V1 has a single role, assigned in all the organizational units of the user.
+func userRepresentation(user object, version int) object {
+	res := copyObject(user)
+	ouIds := userOrganizationalUnits(user)
+	res["organizational_unit_count"] = int64(len(ouIds))
+	if version >= 2 {
+		return res
+	}
+	delete(res, "access_control_configuration")
+	delete(res, "_etag")
+	res["assigned_role"] = nil
+	if assignments := userAssignments(user); len(assignments) > 0 {
+		res["assigned_role"] = assignments[0].roleId
+	}
+	res["assigned_organizational_unit_ids"] = toAnySlice(ouIds)
+	return res
+}
+
+// checkAssignments validates the roles and organizational units of the assignments.
+func (s *Server) checkAssignments(r *request, assignments []assignment) (bool, int, any) {
+	for _, a := range assignments {
+		if !s.roleExists(a.roleId) {
+			status, body := badRequest("The role %s does not exist.", a.roleId)
+			return false, status, body
+		}
+		for _, ouId := range a.ouIds {
+			if ok, status, body := s.checkOrganizationalUnit(r, ouId); !ok {
+				return false, status, body
+			}
+		}
+	}
+	return true, 0, nil
+}
+
+// roleExists returns whether the role exists.
+func (s *Server) roleExists(roleId string) bool {
+	for _, role := range s.roles {
+		if stringField(role, "id") == roleId {
+			return true
+		}
+	}
+	return false
+}
+
+// assignmentsField returns the assignments of a V2 access_control_configuration field.
+func assignmentsField(obj object, key string) []assignment {
+	return userAssignments(object{"access_control_configuration": obj[key]})
+}
+
+// listUsers handles GET /users.
+func listUsers(s *Server, r *request) (int, any) {
+	ids := make([]string, 0, len(s.users))
+	for id := range s.users {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		left, _ := strconv.ParseInt(ids[i], 10, 64)
+		right, _ := strconv.ParseInt(ids[j], 10, 64)
+		return left < right
+	})
+	conditions, err := parseFilter(r)
+	if err != nil {
+		return badRequest("%v", err)
+	}
+	items := make([]any, 0, len(ids))
+	for _, id := range ids {
+		if user := s.users[id]; matches(user, conditions) {
+			items = append(items, userRepresentation(user, r.version))
+		}
+	}
+	return http.StatusOK, paginate(r, items)
+}
+
+// createUser handles POST /users. V1 requests assign a single role in all the organizational
+// units of the user, while V2 requests list the role assigned in each of them.
+func createUser(s *Server, r *request) (int, any) {
+	email := stringField(r.body, "email")
+	if email == "" || stringField(r.body, "full_name") == "" {
+		return badRequest("The email and full_name of the user are required.")
+	}
+	for _, other := range s.users {
+		if stringField(other, "email") == email {
+			return apiError(http.StatusConflict, "A user with the email %s already exists.", email)
+		}
+	}
+	var assignments []assignment
+	if r.version >= 2 {
+		assignments = assignmentsField(r.body, "access_control_configuration")
+	} else {
+		ouIds := stringSliceField(r.body, "organizational_unit_ids")
+		if len(ouIds) == 0 {
+			ouIds = []string{contextOrganizationalUnit(r)}
+		}
+		roleId := stringField(r.body, "assigned_role")
+		if roleId == "" {
+			return badRequest("The assigned_role of the user is required.")
+		}
+		assignments = []assignment{{roleId: roleId, ouIds: ouIds}}
+	}
+	if ok, status, body := s.checkAssignments(r, assignments); !ok {
+		return status, body
+	}
+	s.userSequence++
+	user := object{
+		"id":                      strconv.FormatInt(s.userSequence, 10),
+		"email":                   email,
+		"full_name":               r.body["full_name"],
+		"inviter":                 mockInviter,
+		"is_confirmed":            false,
+		"is_enabled":              true,
+		"last_activity_timestamp": "",
+		"_etag":                   s.newETag(),
+	}
+	setUserAssignments(user, assignments)
+	s.users[stringField(user, "id")] = user
+	return http.StatusOK, userRepresentation(user, r.version)
+}
+
+// readUser handles GET /users/{user_id}.
+func readUser(s *Server, r *request) (int, any) {
+	user, ok := s.users[r.params["user_id"]]
+	if !ok {
+		return notFound("user", r.params["user_id"])
+	}
+	return http.StatusOK, userRepresentation(user, r.version)
+}
+
+// updateUser handles PATCH /users/{user_id}. V1 requests may change the single role of the
+// user and add or remove organizational units, while V2 requests add or remove role
+// assignments.
+func updateUser(s *Server, r *request) (int, any) {
+	user, ok := s.users[r.params["user_id"]]
+	if !ok {
+		return notFound("user", r.params["user_id"])
+	}
+	if ok, status, body := checkIfMatch(r, user); !ok {
+		return status, body
+	}
+	previousOuIds := userOrganizationalUnits(user)
+	assignments := userAssignments(user)
+	if r.version >= 2 {
+		updates := objectField(r.body, "access_control_configuration_updates")
+		added := assignmentsField(updates, "add")
+		if ok, status, body := s.checkAssignments(r, added); !ok {
+			return status, body
+		}
+		for _, a := range assignmentsField(updates, "remove") {
+			for i := range assignments {
+				if assignments[i].roleId != a.roleId {
+					continue
+				}
+				for _, ouId := range a.ouIds {
+					assignments[i].ouIds = removeString(assignments[i].ouIds, ouId)
+				}
+			}
+		}
+		for _, a := range added {
+			found := false
+			for i := range assignments {
+				if assignments[i].roleId == a.roleId {
+					found = true
+					for _, ouId := range a.ouIds {
+						if !containsString(assignments[i].ouIds, ouId) {
+							assignments[i].ouIds = append(assignments[i].ouIds, ouId)
+						}
+					}
+				}
+			}
+			if !found {
+				assignments = append(assignments, a)
+			}
+		}
+	} else {
+		roleId := stringField(r.body, "assigned_role")
+		if roleId == "" && len(assignments) > 0 {
+			roleId = assignments[0].roleId
+		}
+		ouIds := previousOuIds
+		updates := objectField(r.body, "organizational_unit_assignment_updates")
+		for _, ouId := range stringSliceField(updates, "remove") {
+			ouIds = removeString(ouIds, ouId)
+		}
+		for _, ouId := range stringSliceField(updates, "add") {
+			if !containsString(ouIds, ouId) {
+				ouIds = append(ouIds, ouId)
+			}
+		}
+		assignments = []assignment{{roleId: roleId, ouIds: ouIds}}
+		if ok, status, body := s.checkAssignments(r, assignments); !ok {
+			return status, body
+		}
+	}
+	mergeFields(user, r.body, "full_name")
+	setUserAssignments(user, assignments)
+	user["_etag"] = s.newETag()
+	return http.StatusOK, userRepresentation(user, r.version)
+}
+
+// deleteUser handles DELETE /users/{user_id}.
+func deleteUser(s *Server, r *request) (int, any) {
+	user, ok := s.users[r.params["user_id"]]
+	if !ok {
+		return notFound("user", r.params["user_id"])
+	}
+	if ok, status, body := checkIfMatch(r, user); !ok {
+		return status, body
+	}
+	delete(s.users, stringField(user, "id"))
+	return http.StatusOK, object{}
+}
+
+// roleRepresentation returns the representation of the role, with the count of the users
+// assigned to it.
+func (s *Server) roleRepresentation(role object) object {
+	res := copyObject(role)
+	userCount := int64(0)
+	for _, user := range s.users {
+		for _, a := range userAssignments(user) {
+			if a.roleId == stringField(role, "id") {
+				userCount++
+				break
+			}
+		}
+	}
+	res["user_count"] = userCount
+	return res
+}
+
+// listRoles handles GET /roles.
+func listRoles(s *Server, r *request) (int, any) {
+	items := make([]any, 0, len(s.roles))
+	for _, role := range s.roles {
+		items = append(items, s.roleRepresentation(role))
+	}
+	return http.StatusOK, paginate(r, items)
+}
+
+// readRole handles GET /roles/{role_id}.
+func readRole(s *Server, r *request) (int, any) {
+	for _, role := range s.roles {
+		if stringField(role, "id") == r.params["role_id"] {
+			return http.StatusOK, s.roleRepresentation(role)
+		}
+	}
+	return notFound("role", r.params["role_id"])
+}
+
+// readAupSetting handles GET /settings/auto-user-provisioning.
+func readAupSetting(s *Server, _ *request) (int, any) {
+	return http.StatusOK, object{"is_enabled": s.aupEnabled}
+}
+
+// updateAupSetting handles PUT /settings/auto-user-provisioning.
+func updateAupSetting(s *Server, r *request) (int, any) {
+	enabled, ok := r.body["is_enabled"].(bool)
+	if !ok {
+		return badRequest("The is_enabled field of the setting is required.")
+	}
+	s.aupEnabled = enabled
+	return http.StatusOK, object{"is_enabled": s.aupEnabled}
+}
+
+// setAupRuleFields validates the body of an auto user provisioning rule create or update
+// request, and sets the fields of the rule from it.
+func (s *Server) setAupRuleFields(r *request, rule object) (bool, int, any) {
+	if stringField(r.body, "name") == "" || stringField(r.body, "condition") == "" {
+		status, body := badRequest("The name and condition of the rule are required.")
+		return false, status, body
+	}
+	provision := objectField(r.body, "provision")
+	provisioned := []assignment{{
+		roleId: stringField(provision, "role_id"),
+		ouIds:  stringSliceField(provision, "organizational_unit_ids"),
+	}}
+	if ok, status, body := s.checkAssignments(r, provisioned); !ok {
+		return false, status, body
+	}
+	rule["name"] = r.body["name"]
+	rule["condition"] = r.body["condition"]
+	rule["provision"] = object{
+		"role_id":                 provisioned[0].roleId,
+		"organizational_unit_ids": toAnySlice(provisioned[0].ouIds),
+	}
+	return true, 0, nil
+}
+
+// listAupRules handles GET /settings/auto-user-provisioning/rules.
+func listAupRules(s *Server, r *request) (int, any) {
+	return s.listObjects(r, s.aupRules, func(object) string { return "" })
+}
+
+// createAupRule handles POST /settings/auto-user-provisioning/rules.
+func createAupRule(s *Server, r *request) (int, any) {
+	rule := object{"rule_id": uuid.NewString()}
+	if ok, status, body := s.setAupRuleFields(r, rule); !ok {
+		return status, body
+	}
+	s.aupRules[stringField(rule, "rule_id")] = rule
+	return http.StatusOK, copyObject(rule)
+}
+
+// readAupRule handles GET /settings/auto-user-provisioning/rules/{rule_id}.
+func readAupRule(s *Server, r *request) (int, any) {
+	rule, ok := s.aupRules[r.params["rule_id"]]
+	if !ok {
+		return notFound("auto user provisioning rule", r.params["rule_id"])
+	}
+	return http.StatusOK, copyObject(rule)
+}
+
+// updateAupRule handles PUT /settings/auto-user-provisioning/rules/{rule_id}.
+func updateAupRule(s *Server, r *request) (int, any) {
+	rule, ok := s.aupRules[r.params["rule_id"]]
+	if !ok {
+		return notFound("auto user provisioning rule", r.params["rule_id"])
+	}
+	if ok, status, body := s.setAupRuleFields(r, rule); !ok {
+		return status, body
+	}
+	return http.StatusOK, copyObject(rule)
+}
+
+// deleteAupRule handles DELETE /settings/auto-user-provisioning/rules/{rule_id}.
+func deleteAupRule(s *Server, r *request) (int, any) {
+	if _, ok := s.aupRules[r.params["rule_id"]]; !ok {
+		return notFound("auto user provisioning rule", r.params["rule_id"])
+	}
+	delete(s.aupRules, r.params["rule_id"])
+	return http.StatusOK, object{}
+}
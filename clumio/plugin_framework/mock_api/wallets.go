@@ -0,0 +1,111 @@
+// Copyright 2024. Clumio, Inc.
+
+// Contains the handlers of the wallets and KMS post-processing APIs.
+
+package mock_api
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+const (
+	walletStateWaiting   = "waiting_to_be_connected"
+	walletStateConnected = "connected"
+)
+
+// walletRoutes are the routes of the wallets and KMS post-processing APIs.
+var walletRoutes = []route{
+	{http.MethodPost, "/wallets", createWallet},
+	{http.MethodGet, "/wallets", listWallets},
+	{http.MethodPost, "/wallets/_post-process", postProcessKms},
+	{http.MethodGet, "/wallets/{wallet_id}", readWallet},
+	{http.MethodDelete, "/wallets/{wallet_id}", deleteWallet},
+}
+
+// createWallet handles POST /wallets. An AWS account has a single wallet.
+func createWallet(s *Server, r *request) (int, any) {
+	accountNativeId := stringField(r.body, "account_native_id")
+	if accountNativeId == "" {
+		return badRequest("The account_native_id of the wallet is required.")
+	}
+	for _, other := range s.wallets {
+		if stringField(other, "account_native_id") == accountNativeId {
+			return apiError(http.StatusConflict,
+				"A wallet already exists for the AWS account %s.", accountNativeId)
+		}
+	}
+	wallet := object{
+		"id":                    uuid.NewString(),
+		"account_native_id":     accountNativeId,
+		"clumio_aws_account_id": clumioAwsAccountId,
+		"state":                 walletStateWaiting,
+		"token":                 uuid.NewString(),
+		"installed_regions":     []any{},
+		"supported_regions":     []any{},
+	}
+	s.wallets[stringField(wallet, "id")] = wallet
+	return http.StatusOK, copyObject(wallet)
+}
+
+// listWallets handles GET /wallets.
+func listWallets(s *Server, r *request) (int, any) {
+	return s.listObjects(r, s.wallets, func(object) string { return "" })
+}
+
+// readWallet handles GET /wallets/{wallet_id}.
+func readWallet(s *Server, r *request) (int, any) {
+	wallet, ok := s.wallets[r.params["wallet_id"]]
+	if !ok {
+		return notFound("wallet", r.params["wallet_id"])
+	}
+	return http.StatusOK, copyObject(wallet)
+}
+
+// deleteWallet handles DELETE /wallets/{wallet_id}.
+func deleteWallet(s *Server, r *request) (int, any) {
+	if _, ok := s.wallets[r.params["wallet_id"]]; !ok {
+		return notFound("wallet", r.params["wallet_id"])
+	}
+	delete(s.wallets, r.params["wallet_id"])
+	return http.StatusOK, object{}
+}
+
+// postProcessKms handles POST /wallets/_post-process, which is called once the Clumio KMS
+// template is installed in the AWS account. It connects the wallet of the account, or
+// disconnects it when the template is removed.
+func postProcessKms(s *Server, r *request) (int, any) {
+	accountNativeId := stringField(r.body, "account_native_id")
+	var wallet object
+	for _, candidate := range s.wallets {
+		if stringField(candidate, "account_native_id") == accountNativeId {
+			wallet = candidate
+		}
+	}
+	if wallet == nil {
+		return notFound("wallet of the AWS account", accountNativeId)
+	}
+	if stringField(r.body, "token") != stringField(wallet, "token") {
+		return badRequest("The token does not match the token of the wallet %s.",
+			stringField(wallet, "id"))
+	}
+	region := stringField(r.body, "aws_region")
+	regions := stringSliceField(wallet, "installed_regions")
+	switch requestType := stringField(r.body, "request_type"); requestType {
+	case "Create", "Update":
+		if !containsString(regions, region) {
+			regions = append(regions, region)
+		}
+		wallet["state"] = walletStateConnected
+	case "Delete":
+		regions = removeString(regions, region)
+		if len(regions) == 0 {
+			wallet["state"] = walletStateWaiting
+		}
+	default:
+		return badRequest("Unsupported request_type %q.", requestType)
+	}
+	wallet["installed_regions"] = toAnySlice(regions)
+	return http.StatusOK, object{}
+}
@@ -8,6 +8,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	clumioConfig "github.com/clumio-code/clumio-go-sdk/config"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/clumio_auto_user_provisioning_rule"
@@ -15,6 +17,7 @@ import (
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/clumio_aws_connection"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/clumio_aws_manual_connection"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/clumio_aws_manual_connection_resources"
+	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/clumio_functions"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/clumio_organizational_unit"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/clumio_policy"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/clumio_policy_assignment"
@@ -23,22 +26,30 @@ import (
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/clumio_post_process_kms"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/clumio_protection_group"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/clumio_role"
+	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/clumio_task"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/clumio_user"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/clumio_wallet"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure the implementation satisfies the expected interfaces
 var (
-	_ provider.Provider = &clumioProvider{}
+	_ provider.Provider              = &clumioProvider{}
+	_ provider.ProviderWithFunctions = &clumioProvider{}
 )
 
 // New is a helper function to simplify provider server and testing implementation.
@@ -47,13 +58,43 @@ func New() provider.Provider {
 }
 
 // clumioProvider is the provider implementation.
-type clumioProvider struct{}
+type clumioProvider struct {
+	// pollInterval, when positive, replaces the waits between two polls of the asynchronous
+	// operations of the API. It is only set by the acceptance tests.
+	pollInterval time.Duration
+}
 
 // clumioProviderModel maps provider schema data to a Go type.
 type clumioProviderModel struct {
-	ClumioApiToken                  types.String `tfsdk:"clumio_api_token"`
-	ClumioApiBaseUrl                types.String `tfsdk:"clumio_api_base_url"`
-	ClumioOrganizationalUnitContext types.String `tfsdk:"clumio_organizational_unit_context"`
+	ClumioApiToken                  types.String          `tfsdk:"clumio_api_token"`
+	ClumioApiBaseUrl                types.String          `tfsdk:"clumio_api_base_url"`
+	ClumioOrganizationalUnitContext types.String          `tfsdk:"clumio_organizational_unit_context"`
+	ClumioRegion                    types.String          `tfsdk:"clumio_region"`
+	AllowCustomApiBaseUrl           types.Bool            `tfsdk:"allow_custom_api_base_url"`
+	Profile                         types.String          `tfsdk:"profile"`
+	SkipCredentialsValidation       types.Bool            `tfsdk:"skip_credentials_validation"`
+	MaxRetries                      types.Int64           `tfsdk:"max_retries"`
+	RetryMaxBackoff                 types.String          `tfsdk:"retry_max_backoff"`
+	RequestsPerSecond               types.Float64         `tfsdk:"requests_per_second"`
+	MaxConcurrentRequests           types.Int64           `tfsdk:"max_concurrent_requests"`
+	HttpProxy                       types.String          `tfsdk:"http_proxy"`
+	HttpsProxy                      types.String          `tfsdk:"https_proxy"`
+	CaBundleFile                    types.String          `tfsdk:"ca_bundle_file"`
+	CaBundle                        types.String          `tfsdk:"ca_bundle"`
+	ClientCertificateFile           types.String          `tfsdk:"client_certificate_file"`
+	ClientKeyFile                   types.String          `tfsdk:"client_key_file"`
+	RequestTimeout                  types.String          `tfsdk:"request_timeout"`
+	InsecureSkipVerify              types.Bool            `tfsdk:"insecure_skip_verify"`
+	TokenCommand                    types.String          `tfsdk:"token_command"`
+	DisableLookupCache              types.Bool            `tfsdk:"disable_lookup_cache"`
+	DefaultTimeouts                 *defaultTimeoutsModel `tfsdk:"default_timeouts"`
+}
+
+// defaultTimeoutsModel maps the default_timeouts block of the provider schema.
+type defaultTimeoutsModel struct {
+	Create types.String `tfsdk:"create"`
+	Update types.String `tfsdk:"update"`
+	Delete types.String `tfsdk:"delete"`
 }
 
 // Metadata returns the provider type name.
@@ -72,6 +113,22 @@ func (p *clumioProvider) Schema(_ context.Context, _ provider.SchemaRequest, res
 					"https://support.clumio.com/hc/en-us/articles/5009876674196-Creating-an-API-Token",
 				Optional: true,
 			},
+			"token_command": schema.StringAttribute{
+				MarkdownDescription: "A command run with the shell of the platform to obtain" +
+					" a short-lived Clumio API token, as an alternative to clumio_api_token." +
+					" The command must write a JSON object such as" +
+					" `{\"token\": \"...\", \"expiration\": \"2024-01-02T15:04:05Z\"}` to its" +
+					" standard output. The expiration, in RFC 3339 format, is optional. The" +
+					" command is run again shortly before the token expires, so that long" +
+					" running operations keep a valid token, and when the Clumio API" +
+					" rejects the token. Can also be set with the" +
+					" token_command key of a profile of the Clumio credentials file." +
+					" Conflicts with clumio_api_token.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("clumio_api_token")),
+				},
+			},
 			"clumio_api_base_url": schema.StringAttribute{
 				MarkdownDescription: "The base URL for Clumio APIs. The following are the valid " +
 					"values for clumio_api_base_url. Use the appropriate value depending" +
@@ -94,6 +151,177 @@ func (p *clumioProvider) Schema(_ context.Context, _ provider.SchemaRequest, res
 					" be the id of the Organizational Unit and not the name.",
 				Optional: true,
 			},
+			"clumio_region": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("The Clumio region for which your credentials"+
+					" were created. Sets clumio_api_base_url to the API Base URL of the"+
+					" region and conflicts with it. The valid values are: %s.",
+					strings.Join(common.Regions(), ", ")),
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(common.Regions()...),
+					stringvalidator.ConflictsWith(path.MatchRoot("clumio_api_base_url")),
+				},
+			},
+			"allow_custom_api_base_url": schema.BoolAttribute{
+				MarkdownDescription: "Set to true to silence the warning reported when" +
+					" clumio_api_base_url is not the API Base URL of a known Clumio region," +
+					" such as when using a private or staging endpoint.",
+				Optional: true,
+			},
+			"profile": schema.StringAttribute{
+				MarkdownDescription: "The name of the profile to read from the Clumio" +
+					" credentials file. Can also be set with the CLUMIO_PROFILE environment" +
+					" variable. The credentials file is located at ~/.clumio/credentials" +
+					" unless the CLUMIO_CREDENTIALS_FILE environment variable points" +
+					" elsewhere, and holds one INI section per profile with the" +
+					" clumio_api_token, clumio_api_base_url and" +
+					" clumio_organizational_unit_context keys. If no profile is selected," +
+					" the default profile is used when it exists. Each setting is taken" +
+					" from the provider configuration first, then from its environment" +
+					" variable, and lastly from the profile.",
+				Optional: true,
+			},
+			"skip_credentials_validation": schema.BoolAttribute{
+				MarkdownDescription: "Set to true to skip the call made to the Clumio API" +
+					" when the provider is configured to check that the API token is valid" +
+					" for clumio_api_base_url and that clumio_organizational_unit_context is" +
+					" accessible. Useful for plans that must run without access to Clumio.",
+				Optional: true,
+			},
+			"max_retries": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("The maximum number of times a Clumio API"+
+					" request is retried after a throttling error, a 5xx response or a"+
+					" connection failure. Requests that create objects are only retried"+
+					" when the failure proves that the request was not accepted. Set to"+
+					" `0` to disable retries. Defaults to `%d`.", common.DefaultMaxRetries),
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"retry_max_backoff": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("The maximum wait between two attempts of"+
+					" a retried Clumio API request, as a duration string such as `30s` or"+
+					" `2m`. The wait grows exponentially with jitter up to this value. A"+
+					" `Retry-After` header sent by the API always takes precedence."+
+					" Defaults to `%s`.", common.DefaultRetryMaxBackoff),
+				Optional: true,
+			},
+			"requests_per_second": schema.Float64Attribute{
+				MarkdownDescription: "The maximum number of requests per second sent to" +
+					" the Clumio API by the provider, shared by all resources, data sources" +
+					" and task polls. Short bursts of up to one second worth of requests" +
+					" are allowed. Requests above the rate wait for their turn. Set to `0`" +
+					" or leave unset to disable the limit.",
+				Optional: true,
+				Validators: []validator.Float64{
+					float64validator.AtLeast(0),
+				},
+			},
+			"max_concurrent_requests": schema.Int64Attribute{
+				MarkdownDescription: "The maximum number of requests to the Clumio API in" +
+					" flight at the same time, shared by all resources, data sources and" +
+					" task polls. Set to `0` or leave unset to disable the limit.",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"http_proxy": schema.StringAttribute{
+				MarkdownDescription: "The URL of the proxy used to reach an http Clumio API" +
+					" Base URL, such as `http://proxy.example.com:3128`. Defaults to the" +
+					" HTTP_PROXY environment variable. Hosts listed in the NO_PROXY" +
+					" environment variable are reached directly.",
+				Optional: true,
+			},
+			"https_proxy": schema.StringAttribute{
+				MarkdownDescription: "The URL of the proxy used to reach an https Clumio API" +
+					" Base URL, such as `http://proxy.example.com:3128`. Defaults to the" +
+					" HTTPS_PROXY environment variable. Hosts listed in the NO_PROXY" +
+					" environment variable are reached directly.",
+				Optional: true,
+			},
+			"ca_bundle_file": schema.StringAttribute{
+				MarkdownDescription: "The path of a file holding PEM encoded CA" +
+					" certificates trusted in addition to the system trust store, such as" +
+					" the CA of a proxy that re-signs TLS connections. Conflicts with" +
+					" ca_bundle.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("ca_bundle")),
+				},
+			},
+			"ca_bundle": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded CA certificates trusted in addition to the" +
+					" system trust store. Conflicts with ca_bundle_file.",
+				Optional: true,
+			},
+			"client_certificate_file": schema.StringAttribute{
+				MarkdownDescription: "The path of a file holding the PEM encoded client" +
+					" certificate presented to a proxy or endpoint that requires mutual" +
+					" TLS. Requires client_key_file.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("client_key_file")),
+				},
+			},
+			"client_key_file": schema.StringAttribute{
+				MarkdownDescription: "The path of a file holding the PEM encoded private key" +
+					" of client_certificate_file. Requires client_certificate_file.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("client_certificate_file")),
+				},
+			},
+			"request_timeout": schema.StringAttribute{
+				MarkdownDescription: "The maximum duration of a single attempt of a Clumio" +
+					" API request, including reading the response, as a duration string" +
+					" such as `30s` or `2m`. Attempts that time out are retried like" +
+					" connection failures. Leave unset to disable the timeout.",
+				Optional: true,
+			},
+			"insecure_skip_verify": schema.BoolAttribute{
+				MarkdownDescription: "Set to true to disable the verification of the TLS" +
+					" certificate of the Clumio API or proxy. This exposes the API token to" +
+					" interception and must only be used for troubleshooting. Prefer" +
+					" ca_bundle_file to trust a private CA.",
+				Optional: true,
+			},
+			"disable_lookup_cache": schema.BoolAttribute{
+				MarkdownDescription: fmt.Sprintf("Set to true to disable the cache of the"+
+					" reference data looked up by the resources and data sources, such as"+
+					" roles, organizational units and AWS environments. Cached lookups are"+
+					" kept in memory for up to `%s` and dropped as soon as the provider"+
+					" changes an object of the same type.", common.DefaultLookupCacheTTL),
+				Optional: true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"default_timeouts": schema.SingleNestedBlock{
+				MarkdownDescription: "The default timeouts of the operations of the" +
+					" resources backed by Clumio tasks. The timeouts block of a resource" +
+					" takes precedence over them.",
+				Attributes: map[string]schema.Attribute{
+					"create": schema.StringAttribute{
+						MarkdownDescription: fmt.Sprintf("The default timeout of the create"+
+							" operations, as a duration string such as `30m` or `2h`."+
+							" Defaults to `%s`.", common.DefaultOperationTimeout),
+						Optional: true,
+					},
+					"update": schema.StringAttribute{
+						MarkdownDescription: fmt.Sprintf("The default timeout of the update"+
+							" operations, as a duration string such as `30m` or `2h`."+
+							" Defaults to `%s`.", common.DefaultOperationTimeout),
+						Optional: true,
+					},
+					"delete": schema.StringAttribute{
+						MarkdownDescription: fmt.Sprintf("The default timeout of the delete"+
+							" operations, as a duration string such as `30m` or `2h`."+
+							" Defaults to `%s`.", common.DefaultOperationTimeout),
+						Optional: true,
+					},
+				},
+			},
 		},
 	}
 }
@@ -129,33 +357,98 @@ func (p *clumioProvider) Configure(ctx context.Context, req provider.ConfigureRe
 		)
 	}
 
-	if resp.Diagnostics.HasError() {
-		return
+	if config.ClumioRegion.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("clumio_region"),
+			"Unknown Clumio region",
+			fmt.Sprintf(errorFmt, region, common.ClumioApiBaseUrl),
+		)
 	}
 
-	// Default values to environment variables, but override
-	// with Terraform configuration value if set.
+	if config.TokenCommand.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("token_command"),
+			"Unknown Clumio token command",
+			fmt.Sprintf(errorFmt, tokenCommand, common.ClumioApiToken),
+		)
+	}
 
-	clumioApiToken := os.Getenv(common.ClumioApiToken)
-	clumioApiBaseUrl := os.Getenv(common.ClumioApiBaseUrl)
-	clumioOrganizationalUnitContext := os.Getenv(common.ClumioOrganizationalUnitContext)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	if !config.ClumioApiToken.IsNull() {
-		clumioApiToken = config.ClumioApiToken.ValueString()
+	if config.Profile.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("profile"),
+			"Unknown Clumio profile",
+			fmt.Sprintf(errorFmt, profile, common.ClumioProfile),
+		)
+		return
 	}
 
-	if !config.ClumioApiBaseUrl.IsNull() {
-		clumioApiBaseUrl = config.ClumioApiBaseUrl.ValueString()
+	// Load the profile from the credentials file. A profile selected explicitly must
+	// exist, whereas the default profile is only used when present.
+	profileName := os.Getenv(common.ClumioProfile)
+	if !config.Profile.IsNull() {
+		profileName = config.Profile.ValueString()
+	}
+	profileSelected := profileName != ""
+	if !profileSelected {
+		profileName = common.DefaultProfile
+	}
+	credentialsPath, err := common.CredentialsFilePath()
+	var credentialsProfile *common.CredentialsProfile
+	if err == nil {
+		credentialsProfile, err = common.LoadCredentialsProfile(credentialsPath, profileName)
+	}
+	if err != nil {
+		if profileSelected || (credentialsPath != "" && !common.IsProfileNotFound(err)) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("profile"),
+				"Unable to load the Clumio profile",
+				fmt.Sprintf(errorGenericFmt, err),
+			)
+			return
+		}
+		credentialsProfile = &common.CredentialsProfile{}
 	}
 
-	if !config.ClumioOrganizationalUnitContext.IsNull() {
-		clumioOrganizationalUnitContext = config.ClumioOrganizationalUnitContext.ValueString()
+	// Each setting is taken from the Terraform configuration if set, then from its
+	// environment variable and lastly from the profile.
+	clumioApiToken, tokenSource := resolveSetting(config.ClumioApiToken,
+		common.ClumioApiToken, credentialsProfile.ApiToken, profileName)
+	clumioApiBaseUrl, baseUrlSource := resolveSetting(config.ClumioApiBaseUrl,
+		common.ClumioApiBaseUrl, credentialsProfile.ApiBaseUrl, profileName)
+	clumioOrganizationalUnitContext, ouContextSource := resolveSetting(
+		config.ClumioOrganizationalUnitContext, common.ClumioOrganizationalUnitContext,
+		credentialsProfile.OrganizationalUnitContext, profileName)
+	// The token command of the provider configuration conflicts with clumio_api_token,
+	// whereas the one of the profile is only used if no token is set anywhere else.
+	clumioTokenCommand := ""
+	if !config.TokenCommand.IsNull() {
+		clumioTokenCommand, tokenSource = config.TokenCommand.ValueString(),
+			fmt.Sprintf(sourceTokenCommandFmt, sourceConfig)
+		clumioApiToken = ""
+	} else if clumioApiToken == "" && credentialsProfile.TokenCommand != "" {
+		clumioTokenCommand, tokenSource = credentialsProfile.TokenCommand,
+			fmt.Sprintf(sourceTokenCommandFmt, fmt.Sprintf(sourceProfileFmt, profileName))
+	}
+	if !config.ClumioRegion.IsNull() {
+		// The region was validated by the schema and conflicts with clumio_api_base_url.
+		clumioApiBaseUrl, _ = common.RegionBaseUrl(config.ClumioRegion.ValueString())
+		baseUrlSource = sourceRegion
 	}
+	tflog.Info(ctx, "Resolved Clumio client settings", map[string]any{
+		"clumio_api_token_source":                   tokenSource,
+		"clumio_api_base_url_source":                baseUrlSource,
+		"clumio_api_base_url":                       clumioApiBaseUrl,
+		"clumio_organizational_unit_context_source": ouContextSource,
+	})
 
 	// If any of the expected configurations are missing, return
 	// errors with provider-specific guidance.
 
-	if clumioApiToken == "" {
+	if clumioApiToken == "" && clumioTokenCommand == "" {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("clumioApiToken"),
 			"Missing Clumio API Token",
@@ -169,6 +462,90 @@ func (p *clumioProvider) Configure(ctx context.Context, req provider.ConfigureRe
 			"Missing Clumio API Username",
 			fmt.Sprintf(errorFmt, baseUrl, common.ClumioApiBaseUrl),
 		)
+	} else {
+		normalizedBaseUrl, err := common.NormalizeBaseUrl(clumioApiBaseUrl)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("clumio_api_base_url"),
+				"Invalid Clumio API Base URL",
+				fmt.Sprintf(errorGenericFmt, err),
+			)
+		} else if !common.IsKnownBaseUrl(normalizedBaseUrl) &&
+			!config.AllowCustomApiBaseUrl.ValueBool() {
+			resp.Diagnostics.AddAttributeWarning(
+				path.Root("clumio_api_base_url"),
+				"Unrecognized Clumio API Base URL",
+				fmt.Sprintf(unknownBaseUrlFmt, normalizedBaseUrl,
+					strings.Join(common.Regions(), ", ")),
+			)
+		}
+		clumioApiBaseUrl = normalizedBaseUrl
+	}
+
+	retryConfig := common.RetryConfig{
+		MaxRetries: common.DefaultMaxRetries,
+		MaxBackoff: common.DefaultRetryMaxBackoff,
+	}
+	if !config.MaxRetries.IsNull() && !config.MaxRetries.IsUnknown() {
+		retryConfig.MaxRetries = int(config.MaxRetries.ValueInt64())
+	}
+	if !config.RetryMaxBackoff.IsNull() && !config.RetryMaxBackoff.IsUnknown() {
+		maxBackoff, err := time.ParseDuration(config.RetryMaxBackoff.ValueString())
+		if err != nil || maxBackoff <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("retry_max_backoff"),
+				"Invalid retry_max_backoff",
+				fmt.Sprintf("Expected a positive duration such as \"30s\", got %q.",
+					config.RetryMaxBackoff.ValueString()),
+			)
+		}
+		retryConfig.MaxBackoff = maxBackoff
+	}
+
+	rateLimitConfig := common.RateLimitConfig{
+		RequestsPerSecond:     config.RequestsPerSecond.ValueFloat64(),
+		MaxConcurrentRequests: int(config.MaxConcurrentRequests.ValueInt64()),
+	}
+
+	transportConfig := common.TransportConfig{
+		HttpProxy:          config.HttpProxy.ValueString(),
+		HttpsProxy:         config.HttpsProxy.ValueString(),
+		CABundle:           []byte(config.CaBundle.ValueString()),
+		InsecureSkipVerify: config.InsecureSkipVerify.ValueBool(),
+	}
+	transportConfig.CABundle = append(transportConfig.CABundle,
+		readConfigFile(config.CaBundleFile, "ca_bundle_file", &resp.Diagnostics)...)
+	transportConfig.ClientCertificate = readConfigFile(config.ClientCertificateFile,
+		"client_certificate_file", &resp.Diagnostics)
+	transportConfig.ClientKey = readConfigFile(config.ClientKeyFile, "client_key_file",
+		&resp.Diagnostics)
+	if !config.RequestTimeout.IsNull() && !config.RequestTimeout.IsUnknown() {
+		requestTimeout, err := time.ParseDuration(config.RequestTimeout.ValueString())
+		if err != nil || requestTimeout <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("request_timeout"),
+				"Invalid request_timeout",
+				fmt.Sprintf("Expected a positive duration such as \"30s\", got %q.",
+					config.RequestTimeout.ValueString()),
+			)
+		}
+		transportConfig.RequestTimeout = requestTimeout
+	}
+	operationTimeouts := common.DefaultOperationTimeouts()
+	if config.DefaultTimeouts != nil {
+		operationTimeouts.Create = parseDefaultTimeout(config.DefaultTimeouts.Create,
+			"create", operationTimeouts.Create, &resp.Diagnostics)
+		operationTimeouts.Update = parseDefaultTimeout(config.DefaultTimeouts.Update,
+			"update", operationTimeouts.Update, &resp.Diagnostics)
+		operationTimeouts.Delete = parseDefaultTimeout(config.DefaultTimeouts.Delete,
+			"delete", operationTimeouts.Delete, &resp.Diagnostics)
+	}
+	if transportConfig.InsecureSkipVerify {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("insecure_skip_verify"),
+			"TLS certificate verification is disabled",
+			insecureSkipVerifyWarning,
+		)
 	}
 
 	if resp.Diagnostics.HasError() {
@@ -177,8 +554,8 @@ func (p *clumioProvider) Configure(ctx context.Context, req provider.ConfigureRe
 
 	tflog.Debug(ctx, "Creating Clumio client")
 
-	client := &common.ApiClient{
-		ClumioConfig: clumioConfig.Config{
+	client, err := common.NewApiClient(ctx,
+		clumioConfig.Config{
 			Token:                     clumioApiToken,
 			BaseUrl:                   clumioApiBaseUrl,
 			OrganizationalUnitContext: clumioOrganizationalUnitContext,
@@ -187,6 +564,28 @@ func (p *clumioProvider) Configure(ctx context.Context, req provider.ConfigureRe
 				clumioTfProviderVersionKey: clumioTfProviderVersionValue,
 			},
 		},
+		common.ApiClientOptions{
+			Retry:              retryConfig,
+			RateLimit:          rateLimitConfig,
+			Transport:          transportConfig,
+			TokenCommand:       clumioTokenCommand,
+			Timeouts:           operationTimeouts,
+			DisableLookupCache: config.DisableLookupCache.ValueBool(),
+			PollInterval:       p.pollInterval,
+		},
+	)
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to create the Clumio API client.",
+			fmt.Sprintf(errorGenericFmt, err))
+		return
+	}
+
+	if !config.SkipCredentialsValidation.ValueBool() {
+		if err := client.ValidateCredentials(ctx); err != nil {
+			resp.Diagnostics.AddError("Invalid Clumio credentials.",
+				fmt.Sprintf(errorGenericFmt, err))
+			return
+		}
 	}
 	// Make the Clumio client available during DataSource and Resource
 	// type Configure methods.
@@ -196,11 +595,69 @@ func (p *clumioProvider) Configure(ctx context.Context, req provider.ConfigureRe
 	tflog.Info(ctx, "Configured Clumio client", map[string]any{"success": true})
 }
 
+// resolveSetting returns the value of a provider setting along with a description of its
+// source. The Terraform configuration takes precedence over the environment variable,
+// which takes precedence over the profile of the credentials file.
+func resolveSetting(configValue types.String, envVar string, profileValue string,
+	profileName string) (string, string) {
+	if !configValue.IsNull() {
+		return configValue.ValueString(), sourceConfig
+	}
+	if value := os.Getenv(envVar); value != "" {
+		return value, fmt.Sprintf(sourceEnvFmt, envVar)
+	}
+	if profileValue != "" {
+		return profileValue, fmt.Sprintf(sourceProfileFmt, profileName)
+	}
+	return "", sourceNone
+}
+
+// parseDefaultTimeout returns the duration set in the given attribute of the
+// default_timeouts block, or defaultValue if it is not set.
+func parseDefaultTimeout(value types.String, attribute string, defaultValue time.Duration,
+	diags *diag.Diagnostics) time.Duration {
+	if value.IsNull() || value.IsUnknown() {
+		return defaultValue
+	}
+	timeout, err := time.ParseDuration(value.ValueString())
+	if err != nil || timeout <= 0 {
+		diags.AddAttributeError(
+			path.Root("default_timeouts").AtName(attribute),
+			fmt.Sprintf("Invalid default_timeouts.%s", attribute),
+			fmt.Sprintf("Expected a positive duration such as \"30m\", got %q.",
+				value.ValueString()),
+		)
+		return defaultValue
+	}
+	return timeout
+}
+
+// readConfigFile returns the content of the file whose path is set in the given
+// attribute, or nil if the attribute is not set. Errors are reported against the attribute.
+func readConfigFile(filePath types.String, attribute string, diags *diag.Diagnostics) []byte {
+	if filePath.IsNull() || filePath.IsUnknown() {
+		return nil
+	}
+	content, err := os.ReadFile(filePath.ValueString())
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root(attribute),
+			fmt.Sprintf("Unable to read %s", attribute),
+			fmt.Sprintf(errorGenericFmt, err),
+		)
+		return nil
+	}
+	return content
+}
+
 // DataSources defines the data sources implemented in the provider.
 func (p *clumioProvider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		clumio_role.NewClumioRoleDataSource,
 		clumio_aws_manual_connection_resources.NewAwsManualConnectionResourcesDataSource,
+		clumio_task.NewClumioTaskDataSource,
+		clumio_policy.NewPolicyDataSource,
+		clumio_policy.NewPoliciesDataSource,
 	}
 }
 
@@ -222,3 +679,14 @@ func (p *clumioProvider) Resources(_ context.Context) []func() resource.Resource
 		clumio_aws_manual_connection.NewAwsManualConnectionResource,
 	}
 }
+
+// Functions defines the functions implemented in the provider.
+func (p *clumioProvider) Functions(_ context.Context) []func() function.Function {
+	return []func() function.Function{
+		clumio_functions.NewTagEqualsFunction,
+		clumio_functions.NewEntityTypeInFunction,
+		clumio_functions.NewAccountInFunction,
+		clumio_functions.NewAnyOfFunction,
+		clumio_functions.NewAllOfFunction,
+	}
+}
@@ -0,0 +1,448 @@
+// Copyright 2024. Clumio, Inc.
+
+// Unit tests of the configuration of the provider.
+package clumio_pf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+// clearProviderEnv unsets the environment variables read by the provider for the duration
+// of the test, and points CLUMIO_CREDENTIALS_FILE to a file that does not exist.
+func clearProviderEnv(t *testing.T) {
+	t.Helper()
+	for _, env := range []string{common.ClumioApiToken, common.ClumioApiBaseUrl,
+		common.ClumioOrganizationalUnitContext, common.ClumioProfile} {
+		t.Setenv(env, "")
+	}
+	t.Setenv(common.ClumioCredentialsFile, filepath.Join(t.TempDir(), "credentials"))
+}
+
+// configureTestProvider configures the provider with the given attributes, the others
+// being null, and closes the client it creates at the end of the test.
+func configureTestProvider(ctx context.Context, t *testing.T,
+	attributes map[string]tftypes.Value) (*provider.ConfigureResponse, *common.ApiClient) {
+	t.Helper()
+	p := &clumioProvider{}
+	schemaResp := &provider.SchemaResponse{}
+	p.Schema(ctx, provider.SchemaRequest{}, schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	values := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attributeType := range objectType.AttributeTypes {
+		values[name] = tftypes.NewValue(attributeType, nil)
+	}
+	for name, value := range attributes {
+		if _, ok := values[name]; !ok {
+			t.Fatalf("unknown provider attribute %s", name)
+		}
+		values[name] = value
+	}
+
+	resp := &provider.ConfigureResponse{}
+	p.Configure(ctx, provider.ConfigureRequest{
+		Config: tfsdk.Config{
+			Schema: schemaResp.Schema,
+			Raw:    tftypes.NewValue(objectType, values),
+		},
+	}, resp)
+	client, _ := resp.ResourceData.(*common.ApiClient)
+	if client != nil {
+		t.Cleanup(func() { client.Close() })
+	}
+	return resp, client
+}
+
+func TestResolveSetting(t *testing.T) {
+	const envVar = "CLUMIO_TEST_SETTING"
+	for name, test := range map[string]struct {
+		config     types.String
+		env        string
+		profile    string
+		want       string
+		wantSource string
+	}{
+		"configuration over everything": {
+			config:     types.StringValue("from-config"),
+			env:        "from-env",
+			profile:    "from-profile",
+			want:       "from-config",
+			wantSource: "provider configuration",
+		},
+		"empty configuration over everything": {
+			config:     types.StringValue(""),
+			env:        "from-env",
+			profile:    "from-profile",
+			want:       "",
+			wantSource: "provider configuration",
+		},
+		"environment over profile": {
+			config:     types.StringNull(),
+			env:        "from-env",
+			profile:    "from-profile",
+			want:       "from-env",
+			wantSource: "environment variable " + envVar,
+		},
+		"profile": {
+			config:     types.StringNull(),
+			profile:    "from-profile",
+			want:       "from-profile",
+			wantSource: `profile "ci"`,
+		},
+		"not set": {
+			config:     types.StringNull(),
+			wantSource: "not set",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(envVar, test.env)
+			got, source := resolveSetting(test.config, envVar, test.profile, "ci")
+			if got != test.want || source != test.wantSource {
+				t.Errorf("got %q from %s, want %q from %s", got, source, test.want,
+					test.wantSource)
+			}
+		})
+	}
+}
+
+func TestConfigureSettingsPrecedence(t *testing.T) {
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"current_count": 0, "limit": 1, "start": "1"}`))
+	}))
+	defer server.Close()
+
+	clearProviderEnv(t)
+	credentialsPath := filepath.Join(t.TempDir(), "credentials")
+	credentials := fmt.Sprintf(`# Profiles of the tests
+[default]
+clumio_api_token = default-token
+
+[ci]
+clumio_api_token = "profile-token"
+clumio_api_base_url = %s
+clumio_organizational_unit_context = profile-ou
+`, server.URL)
+	if err := os.WriteFile(credentialsPath, []byte(credentials), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(common.ClumioCredentialsFile, credentialsPath)
+	t.Setenv(common.ClumioProfile, "ci")
+	t.Setenv(common.ClumioApiToken, "env-token")
+	t.Setenv(common.ClumioOrganizationalUnitContext, "env-ou")
+
+	resp, client := configureTestProvider(context.Background(), t, map[string]tftypes.Value{
+		"clumio_organizational_unit_context": tftypes.NewValue(tftypes.String, "config-ou"),
+		"allow_custom_api_base_url":          tftypes.NewValue(tftypes.Bool, true),
+	})
+	if resp.Diagnostics.HasError() || client == nil {
+		t.Fatalf("got the diagnostics %v, want a client", resp.Diagnostics)
+	}
+	// The base URL comes from the profile, the token from the environment and the
+	// Organizational Unit context from the configuration.
+	config := client.ClumioConfig()
+	if config.Token != "env-token" || config.OrganizationalUnitContext != "config-ou" {
+		t.Errorf("got the token %q and the Organizational Unit context %q, want env-token"+
+			" and config-ou", config.Token, config.OrganizationalUnitContext)
+	}
+	if strings.Join(authorizations, " ") != "Bearer env-token" {
+		t.Errorf("got the credentials validated with %q, want Bearer env-token",
+			authorizations)
+	}
+}
+
+func TestConfigureProfile(t *testing.T) {
+	credentials := "[default]\n" +
+		"clumio_api_token = default-token\n" +
+		"clumio_api_base_url = https://us-west-2.api.clumio.com\n"
+	for name, test := range map[string]struct {
+		// credentials is the content of the credentials file, which is missing if empty.
+		credentials string
+		profile     string
+		wantToken   string
+		wantErr     string
+	}{
+		"default profile": {
+			credentials: credentials,
+			wantToken:   "default-token",
+		},
+		"missing default profile": {
+			credentials: "[ci]\nclumio_api_token = ci-token\n",
+			wantErr:     "Missing Clumio API Token",
+		},
+		"missing credentials file": {
+			wantErr: "Missing Clumio API Token",
+		},
+		"missing selected profile": {
+			credentials: credentials,
+			profile:     "ci",
+			wantErr:     "Unable to load the Clumio profile",
+		},
+		"invalid credentials file": {
+			credentials: "[default\n",
+			wantErr:     "Unable to load the Clumio profile",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			clearProviderEnv(t)
+			if test.credentials != "" {
+				credentialsPath := filepath.Join(t.TempDir(), "credentials")
+				err := os.WriteFile(credentialsPath, []byte(test.credentials), 0o600)
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Setenv(common.ClumioCredentialsFile, credentialsPath)
+			}
+			attributes := map[string]tftypes.Value{
+				"skip_credentials_validation": tftypes.NewValue(tftypes.Bool, true),
+			}
+			if test.profile != "" {
+				attributes["profile"] = tftypes.NewValue(tftypes.String, test.profile)
+			}
+			resp, client := configureTestProvider(context.Background(), t, attributes)
+			if test.wantErr != "" {
+				if !resp.Diagnostics.HasError() ||
+					resp.Diagnostics.Errors()[0].Summary() != test.wantErr {
+					t.Errorf("got the diagnostics %v, want the error %q", resp.Diagnostics,
+						test.wantErr)
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() || client == nil {
+				t.Fatalf("got the diagnostics %v, want a client", resp.Diagnostics)
+			}
+			if got := client.ClumioConfig().Token; got != test.wantToken {
+				t.Errorf("got the token %q, want %q", got, test.wantToken)
+			}
+		})
+	}
+}
+
+func TestConfigureBaseUrl(t *testing.T) {
+	for name, test := range map[string]struct {
+		attributes map[string]tftypes.Value
+		// wantBaseUrl is the base URL expected in the logs, if any.
+		wantBaseUrl string
+		// wantWarning and wantErr are the summaries of the expected diagnostics, if any.
+		wantWarning string
+		wantErr     string
+	}{
+		"region": {
+			attributes: map[string]tftypes.Value{
+				"clumio_region": tftypes.NewValue(tftypes.String, "eu-central-1"),
+			},
+			wantBaseUrl: "https://eu-central-1.de.api.clumio.com",
+		},
+		// The settings are logged before the normalization, which recognizes the base URL
+		// of the region.
+		"normalized base URL": {
+			attributes: map[string]tftypes.Value{
+				"clumio_api_base_url": tftypes.NewValue(tftypes.String,
+					"US-West-2.api.clumio.com/"),
+			},
+		},
+		"custom base URL": {
+			attributes: map[string]tftypes.Value{
+				"clumio_api_base_url": tftypes.NewValue(tftypes.String,
+					"https://staging.clumio.example.com"),
+			},
+			wantBaseUrl: "https://staging.clumio.example.com",
+			wantWarning: "Unrecognized Clumio API Base URL",
+		},
+		"allowed custom base URL": {
+			attributes: map[string]tftypes.Value{
+				"clumio_api_base_url": tftypes.NewValue(tftypes.String,
+					"https://staging.clumio.example.com"),
+				"allow_custom_api_base_url": tftypes.NewValue(tftypes.Bool, true),
+			},
+			wantBaseUrl: "https://staging.clumio.example.com",
+		},
+		"invalid base URL": {
+			attributes: map[string]tftypes.Value{
+				"clumio_api_base_url": tftypes.NewValue(tftypes.String,
+					"https://us-west-2.api.clumio.com?region=us-west-2"),
+				"allow_custom_api_base_url": tftypes.NewValue(tftypes.Bool, true),
+			},
+			wantErr: "Invalid Clumio API Base URL",
+		},
+		"missing base URL": {
+			attributes: map[string]tftypes.Value{},
+			wantErr:    "Missing Clumio API Username",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			clearProviderEnv(t)
+			t.Setenv(common.ClumioApiToken, "api-token")
+			test.attributes["skip_credentials_validation"] = tftypes.NewValue(tftypes.Bool,
+				true)
+			var output bytes.Buffer
+			ctx := tflogtest.RootLogger(context.Background(), &output)
+			resp, _ := configureTestProvider(ctx, t, test.attributes)
+
+			var warnings, errs []string
+			for _, d := range resp.Diagnostics.Warnings() {
+				warnings = append(warnings, d.Summary())
+			}
+			for _, d := range resp.Diagnostics.Errors() {
+				errs = append(errs, d.Summary())
+			}
+			if strings.Join(warnings, ", ") != test.wantWarning ||
+				strings.Join(errs, ", ") != test.wantErr {
+				t.Errorf("got the warnings %q and the errors %q, want %q and %q", warnings,
+					errs, test.wantWarning, test.wantErr)
+			}
+			if test.wantBaseUrl == "" {
+				return
+			}
+			// The base URL is only visible in the logs, as the client sends the requests to
+			// its relay.
+			logged := fmt.Sprintf(`"clumio_api_base_url":"%s"`, test.wantBaseUrl)
+			if !strings.Contains(output.String(), logged) {
+				t.Errorf("got no %s in the logs: %s", logged, output.String())
+			}
+		})
+	}
+}
+
+func TestConfigureCredentialsValidation(t *testing.T) {
+	for name, test := range map[string]struct {
+		skip         bool
+		wantRequests int
+		wantErr      string
+	}{
+		"invalid credentials": {
+			wantRequests: 1,
+			wantErr:      "Invalid Clumio credentials.",
+		},
+		"skipped validation": {
+			skip: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var requests int
+			server := httptest.NewServer(http.HandlerFunc(
+				func(w http.ResponseWriter, _ *http.Request) {
+					requests++
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusUnauthorized)
+					w.Write([]byte(`{"errors": [{"error_message": "Invalid token."}]}`))
+				}))
+			defer server.Close()
+
+			clearProviderEnv(t)
+			resp, client := configureTestProvider(context.Background(), t,
+				map[string]tftypes.Value{
+					"clumio_api_token":          tftypes.NewValue(tftypes.String, "api-token"),
+					"clumio_api_base_url":       tftypes.NewValue(tftypes.String, server.URL),
+					"allow_custom_api_base_url": tftypes.NewValue(tftypes.Bool, true),
+					"skip_credentials_validation": tftypes.NewValue(tftypes.Bool,
+						test.skip),
+				})
+			if requests != test.wantRequests {
+				t.Errorf("got %d requests, want %d", requests, test.wantRequests)
+			}
+			if test.wantErr == "" {
+				if resp.Diagnostics.HasError() || client == nil {
+					t.Errorf("got the diagnostics %v, want a client", resp.Diagnostics)
+				}
+				return
+			}
+			if client != nil {
+				t.Errorf("got a client for invalid credentials")
+			}
+			errs := resp.Diagnostics.Errors()
+			if len(errs) != 1 || errs[0].Summary() != test.wantErr ||
+				!strings.Contains(errs[0].Detail(), "the Clumio API token was rejected by") {
+				t.Errorf("got the diagnostics %v, want the error %q", resp.Diagnostics,
+					test.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigureTransportFiles(t *testing.T) {
+	dir := t.TempDir()
+	invalidFile := filepath.Join(dir, "invalid.pem")
+	if err := os.WriteFile(invalidFile, []byte("not PEM"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	missingFile := filepath.Join(dir, "missing.pem")
+	for name, test := range map[string]struct {
+		attributes map[string]tftypes.Value
+		wantErr    string
+		wantDetail string
+	}{
+		"missing CA bundle file": {
+			attributes: map[string]tftypes.Value{
+				"ca_bundle_file": tftypes.NewValue(tftypes.String, missingFile),
+			},
+			wantErr:    "Unable to read ca_bundle_file",
+			wantDetail: "no such file or directory",
+		},
+		"invalid CA bundle file": {
+			attributes: map[string]tftypes.Value{
+				"ca_bundle_file": tftypes.NewValue(tftypes.String, invalidFile),
+			},
+			wantErr:    "Unable to create the Clumio API client.",
+			wantDetail: "the CA bundle does not contain any PEM encoded certificate",
+		},
+		"missing client key file": {
+			attributes: map[string]tftypes.Value{
+				"client_certificate_file": tftypes.NewValue(tftypes.String, invalidFile),
+				"client_key_file":         tftypes.NewValue(tftypes.String, missingFile),
+			},
+			wantErr:    "Unable to read client_key_file",
+			wantDetail: "no such file or directory",
+		},
+		"invalid client certificate file": {
+			attributes: map[string]tftypes.Value{
+				"client_certificate_file": tftypes.NewValue(tftypes.String, invalidFile),
+				"client_key_file":         tftypes.NewValue(tftypes.String, invalidFile),
+			},
+			wantErr:    "Unable to create the Clumio API client.",
+			wantDetail: "invalid client certificate or key",
+		},
+		"invalid request timeout": {
+			attributes: map[string]tftypes.Value{
+				"request_timeout": tftypes.NewValue(tftypes.String, "soon"),
+			},
+			wantErr:    "Invalid request_timeout",
+			wantDetail: `Expected a positive duration such as "30s", got "soon".`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			clearProviderEnv(t)
+			test.attributes["clumio_region"] = tftypes.NewValue(tftypes.String, "us-west-2")
+			test.attributes["clumio_api_token"] = tftypes.NewValue(tftypes.String,
+				"api-token")
+			test.attributes["skip_credentials_validation"] = tftypes.NewValue(tftypes.Bool,
+				true)
+			resp, client := configureTestProvider(context.Background(), t, test.attributes)
+			if client != nil {
+				t.Errorf("got a client, want the error %q", test.wantErr)
+			}
+			errs := resp.Diagnostics.Errors()
+			if len(errs) != 1 || errs[0].Summary() != test.wantErr ||
+				!strings.Contains(errs[0].Detail(), test.wantDetail) {
+				t.Errorf("got the diagnostics %v, want the error %q with %q",
+					resp.Diagnostics, test.wantErr, test.wantDetail)
+			}
+		})
+	}
+}
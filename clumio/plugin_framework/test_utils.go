@@ -3,19 +3,230 @@
 package clumio_pf
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"testing"
+	"time"
 
+	clumioConfig "github.com/clumio-code/clumio-go-sdk/config"
+	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/cassette"
 	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/common"
+	"github.com/clumio-code/terraform-provider-clumio/clumio/plugin_framework/mock_api"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+const (
+	// TestAccTaskIntervalInSec is used by the acceptance tests to poll the tasks of the API
+	// calls they make outside of Terraform.
+	TestAccTaskIntervalInSec = 5
+
+	// testAccCassetteDir is the directory of the cassettes of the acceptance tests of a
+	// package, relative to the package.
+	testAccCassetteDir = "testdata/cassettes"
+
+	// testAccReplayToken is the API token used when replaying the cassettes.
+	testAccReplayToken = "replayed-clumio-api-token"
+
+	// testAccReplayPollInterval is the wait between two polls of the asynchronous operations
+	// when replaying the cassettes.
+	testAccReplayPollInterval = 10 * time.Millisecond
 )
 
 // ProviderFactories are used to instantiate a provider during acceptance testing.
 // The factory function will be invoked for every Terraform CLI command executed
 // to create a provider server to which the CLI can reattach.
 var TestAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
-	"clumio": providerserver.NewProtocol6WithError(New()),
+	"clumio": func() (tfprotov6.ProviderServer, error) {
+		return providerserver.NewProtocol6WithError(
+			&clumioProvider{pollInterval: testAccPollInterval})()
+	},
+}
+
+// testAccPollInterval, when positive, replaces the waits between two polls of the
+// asynchronous operations of the API made by the acceptance tests.
+var testAccPollInterval time.Duration
+
+// TestAccMockApiServer is the mock Clumio API the acceptance tests run against when
+// CLUMIO_TEST_MOCK_API is set, and nil otherwise. The tests use it to inject faults.
+var TestAccMockApiServer *mock_api.Server
+
+// mockApiTestEnv are the environment variables set for the acceptance tests run against the
+// mock Clumio API, when they are not already set.
+var mockApiTestEnv = map[string]string{
+	common.ClumioTestAwsAccountId:   "123456789012",
+	common.AwsRegion:                "us-west-2",
+	common.ClumioIAMRoleArn:         "arn:aws:iam::123456789012:role/ClumioIAMRole",
+	common.ClumioEventPubArn:        "arn:aws:sns:us-west-2:123456789012:ClumioEventPub",
+	common.ClumioSupportRoleArn:     "arn:aws:iam::123456789012:role/ClumioSupportRole",
+	common.CloudtrailRuleArn:        "arn:aws:events:us-west-2:123456789012:rule/ClumioCloudtrailRule",
+	common.CloudwatchRuleArn:        "arn:aws:events:us-west-2:123456789012:rule/ClumioCloudwatchRule",
+	common.ContinuousBackupsRoleArn: "arn:aws:iam::123456789012:role/ClumioContinuousBackupsRole",
+	common.SsmNotificationRoleArn:   "arn:aws:iam::123456789012:role/ClumioSsmNotificationRole",
+	common.Ec2SsmInstanceProfileArn: "arn:aws:iam::123456789012:instance-profile/ClumioEc2SsmProfile",
+}
+
+// cassetteEnv are the environment variables the cassettes are recorded with which are not
+// scrubbed out of them, and must have the same values when they are replayed.
+var cassetteEnv = []string{
+	common.AwsRegion,
+	common.ClumioOrganizationalUnitContext,
+}
+
+// testAccCassetteServer records or replays the cassettes of the acceptance tests when
+// CLUMIO_TEST_CASSETTE_MODE is set, and is nil otherwise.
+var testAccCassetteServer *cassette.Server
+
+// UtilTestMain runs the tests of a package. When CLUMIO_TEST_MOCK_API is set, the acceptance
+// tests run against an in-process mock of the Clumio API instead of the Clumio API configured
+// in the environment, so that they do not require any network access or Clumio account.
+// When CLUMIO_TEST_CASSETTE_MODE is set to record, the interactions of every acceptance test
+// with the Clumio API are recorded into its cassette under testdata/cassettes, and when it is
+// set to replay, the tests run against their cassettes instead of the Clumio API and the tests
+// without a cassette are skipped. The cassettes are only recorded against the Clumio API.
+func UtilTestMain(m *testing.M) {
+	mockApi := os.Getenv(common.ClumioTestMockApi) != ""
+	cassetteMode := cassette.Mode(os.Getenv(common.ClumioTestCassetteMode))
+	if !mockApi && cassetteMode == "" {
+		resource.TestMain(m)
+		return
+	}
+	if mockApi && cassetteMode != "" {
+		// Cassettes recorded from the mock would only replay the behavior of the mock, not
+		// the one of the Clumio API.
+		fmt.Fprintf(os.Stderr, "Error: %s and %s cannot be set together.\n",
+			common.ClumioTestMockApi, common.ClumioTestCassetteMode)
+		os.Exit(1)
+	}
+	os.Exit(runTestMain(m, mockApi, cassetteMode))
+}
+
+// runTestMain runs the tests of a package against the mock Clumio API or the cassettes, and
+// returns the exit code of the tests.
+func runTestMain(m *testing.M, mockApi bool, cassetteMode cassette.Mode) int {
+	if mockApi {
+		server := mock_api.NewServer()
+		defer server.Close()
+		TestAccMockApiServer = server
+		os.Setenv(common.ClumioApiBaseUrl, server.URL)
+		os.Setenv(common.ClumioApiToken, server.Token)
+		os.Unsetenv(common.ClumioOrganizationalUnitContext)
+		os.Unsetenv(common.ClumioProfile)
+		for name, value := range mockApiTestEnv {
+			if os.Getenv(name) == "" {
+				os.Setenv(name, value)
+			}
+		}
+	}
+	if cassetteMode != "" {
+		server, err := newTestAccCassetteServer(cassetteMode)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		defer server.Close()
+		testAccCassetteServer = server
+		os.Setenv(common.ClumioApiBaseUrl, server.URL)
+	}
+	return m.Run()
+}
+
+// newTestAccCassetteServer starts the server recording the cassettes of the acceptance tests
+// from the Clumio API configured in the environment, or replaying them. The AWS account ID and
+// the ARNs of the environment are replaced in the cassettes by the placeholders the tests get
+// when replaying them.
+func newTestAccCassetteServer(mode cassette.Mode) (*cassette.Server, error) {
+	replacements := map[string]string{}
+	switch mode {
+	case cassette.ModeRecord:
+		for name, placeholder := range mockApiTestEnv {
+			if name != common.AwsRegion {
+				replacements[os.Getenv(name)] = placeholder
+			}
+		}
+	case cassette.ModeReplay:
+		os.Setenv(common.ClumioApiToken, testAccReplayToken)
+		os.Unsetenv(common.ClumioProfile)
+		for name, placeholder := range mockApiTestEnv {
+			if name != common.AwsRegion || os.Getenv(name) == "" {
+				os.Setenv(name, placeholder)
+			}
+		}
+		testAccPollInterval = testAccReplayPollInterval
+	}
+	return cassette.NewServer(mode, os.Getenv(common.ClumioApiBaseUrl),
+		cassette.NewScrubber(replacements))
+}
+
+// utilTestAccInsertCassette makes the acceptance test record into, or replay, its cassette
+// when CLUMIO_TEST_CASSETTE_MODE is set. A cassette is only saved if its test passes, and a
+// replayed test fails if it sends a request that is not in its cassette.
+func utilTestAccInsertCassette(t *testing.T) {
+	t.Helper()
+	server := testAccCassetteServer
+	if server == nil {
+		return
+	}
+	path := cassette.Path(testAccCassetteDir, t.Name())
+	if server.Mode() == cassette.ModeRecord {
+		env := map[string]string{}
+		for _, name := range cassetteEnv {
+			if value := os.Getenv(name); value != "" {
+				env[name] = value
+			}
+		}
+		server.Insert(&cassette.Cassette{Env: env})
+		t.Cleanup(func() {
+			recorded, _ := server.Eject()
+			if t.Failed() || t.Skipped() {
+				t.Logf("The cassette %s is not saved as the test did not pass.", path)
+				return
+			}
+			if err := recorded.Save(path); err != nil {
+				t.Errorf("Unable to save the cassette %s: %v", path, err)
+			}
+		})
+		return
+	}
+
+	replayed, err := cassette.Load(path)
+	if cassette.IsNotExist(err) {
+		t.Skipf("The cassette %s does not exist. Record it against the Clumio API by running"+
+			" the test with %s=%s.", path, common.ClumioTestCassetteMode, cassette.ModeRecord)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range cassetteEnv {
+		if value := replayed.Env[name]; os.Getenv(name) != value {
+			t.Fatalf("The cassette %s was recorded with %s=%q, which must be set to replay it.",
+				path, name, value)
+		}
+	}
+	server.Insert(replayed)
+	t.Cleanup(func() {
+		_, unmatched := server.Eject()
+		for _, req := range unmatched {
+			t.Errorf("The request %s is not in the cassette %s.", req, path)
+		}
+	})
+}
+
+// UtilTestAccPreCheckMockApi skips the acceptance test unless it runs against the mock Clumio
+// API, for the tests injecting faults in the API. The faults cannot be replayed, so these
+// tests are not recorded into cassettes either.
+func UtilTestAccPreCheckMockApi(t *testing.T) {
+	if TestAccMockApiServer == nil {
+		t.Skipf("%s must be set for the test to inject faults in the Clumio API.",
+			common.ClumioTestMockApi)
+	}
+	if testAccCassetteServer != nil {
+		t.Skipf("The test injecting faults in the Clumio API cannot run with %s set.",
+			common.ClumioTestCassetteMode)
+	}
 }
 
 // UtilTestAccPreCheckClumio validates that the required environment variables are set before
@@ -25,6 +236,7 @@ func UtilTestAccPreCheckClumio(t *testing.T) {
 	UtilTestFailIfEmpty(t, common.ClumioApiToken, common.ClumioApiToken+" cannot be empty.")
 	UtilTestFailIfEmpty(t, common.ClumioApiBaseUrl, common.ClumioApiBaseUrl+" cannot be empty.")
 	UtilTestFailIfEmpty(t, common.AwsRegion, common.AwsRegion+" cannot be empty")
+	utilTestAccInsertCassette(t)
 }
 
 // UtilTestAwsManualConnectionPreCheckClumio validates that the required environment variables are set before
@@ -54,3 +266,36 @@ func UtilTestFailIfEmpty(t *testing.T, name string, usageMessage string) string
 
 	return value
 }
+
+// UtilTestCheckResourceDisappears returns a check that deletes the object of the given
+// resource outside of Terraform with deleteFunc, which receives the ID recorded in the state.
+// The step using it must set ExpectNonEmptyPlan so that the refresh that follows verifies that
+// the vanished resource is removed from the state and planned for re-creation.
+func UtilTestCheckResourceDisappears(resourceName string,
+	deleteFunc func(ctx context.Context, client *common.ApiClient, id string) error,
+) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("resource %s not found in the state", resourceName)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), common.DefaultOperationTimeout)
+		defer cancel()
+		client, err := common.NewApiClient(ctx, clumioConfig.Config{
+			Token:                     os.Getenv(common.ClumioApiToken),
+			BaseUrl:                   os.Getenv(common.ClumioApiBaseUrl),
+			OrganizationalUnitContext: os.Getenv(common.ClumioOrganizationalUnitContext),
+		}, common.ApiClientOptions{
+			Retry: common.RetryConfig{
+				MaxRetries: common.DefaultMaxRetries,
+				MaxBackoff: common.DefaultRetryMaxBackoff,
+			},
+			PollInterval: testAccPollInterval,
+		})
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		return deleteFunc(ctx, client, rs.Primary.ID)
+	}
+}
@@ -0,0 +1,9 @@
+data "clumio_policies" "example" {
+  name_regex        = "^gold-"
+  operation_type    = "aws_ebs_volume_backup"
+  activation_status = "activated"
+}
+
+output "gold_policy_ids" {
+  value = data.clumio_policies.example.ids
+}
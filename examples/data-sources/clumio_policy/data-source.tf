@@ -0,0 +1,9 @@
+data "clumio_policy" "example" {
+  name = "gold"
+}
+
+resource "clumio_policy_assignment" "example" {
+  entity_id   = clumio_protection_group.example.id
+  entity_type = "protection_group"
+  policy_id   = data.clumio_policy.example.id
+}
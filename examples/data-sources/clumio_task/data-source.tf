@@ -0,0 +1,7 @@
+data "clumio_task" "example" {
+  id                  = clumio_policy_rule.example.last_task_id
+  wait_until_terminal = true
+  timeouts {
+    read = "30m"
+  }
+}
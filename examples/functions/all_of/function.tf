@@ -0,0 +1,9 @@
+resource "clumio_policy_rule" "example" {
+  name           = "example-policy-rule"
+  policy_id      = clumio_policy.example.id
+  before_rule_id = ""
+  condition = provider::clumio::all_of(
+    provider::clumio::entity_type_in(["aws_ebs_volume"]),
+    provider::clumio::tag_equals("Environment", "Prod"),
+  )
+}
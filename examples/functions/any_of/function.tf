@@ -0,0 +1,7 @@
+resource "clumio_protection_group" "example" {
+  name = "example-protection-group"
+  bucket_rule = provider::clumio::any_of(
+    provider::clumio::tag_equals("Environment", "Prod"),
+    provider::clumio::tag_equals("Environment", "Staging"),
+  )
+}
@@ -0,0 +1,6 @@
+resource "clumio_policy_rule" "example" {
+  name           = "example-policy-rule"
+  policy_id      = clumio_policy.example.id
+  before_rule_id = ""
+  condition      = provider::clumio::entity_type_in(["aws_ebs_volume", "aws_ec2_instance"])
+}
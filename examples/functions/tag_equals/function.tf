@@ -0,0 +1,4 @@
+resource "clumio_protection_group" "example" {
+  name        = "example-protection-group"
+  bucket_rule = provider::clumio::tag_equals("Environment", "Prod")
+}